@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// baselineVersion is the format version written to baseline files.
+const baselineVersion = 1
+
+// baselineFile is the JSON structure of a baseline file, a committable record
+// of known violations written by --write-baseline and read by --baseline.
+type baselineFile struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baselineEntry records a single known violation. Entries are matched by
+// rule, file, and fingerprint only, so they survive lines moving around;
+// Line is informational.
+type baselineEntry struct {
+	Rule string `json:"rule"`
+	// File is slash-separated and relative to the directory of the baseline
+	// file, so that the baseline can be committed.
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// baselineKey identifies the entries that a violation can match.
+type baselineKey struct {
+	rule, fingerprint string
+}
+
+// baseline holds the entries of a baseline file and tracks which of them
+// matched a violation in the current run.
+type baseline struct {
+	dir     string // directory of the baseline file
+	entries []baselineEntry
+	matched map[int]bool    // indexes of entries that matched a violation
+	linted  map[string]bool // files (as in baselineEntry.File) seen in this run
+}
+
+// newBaseline returns an empty baseline for a baseline file at path.
+func newBaseline(path string) *baseline {
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return &baseline{dir: dir, matched: make(map[int]bool), linted: make(map[string]bool)}
+}
+
+// loadBaseline reads the baseline file at path. When missingOK is set, a
+// missing file yields an empty baseline instead of an error.
+func loadBaseline(path string, missingOK bool) (*baseline, error) {
+	b := newBaseline(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if missingOK && errors.Is(err, fs.ErrNotExist) {
+			return b, nil
+		}
+		return nil, err
+	}
+	var f baselineFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if f.Version != baselineVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", path, f.Version)
+	}
+	b.entries = f.Entries
+	return b, nil
+}
+
+// sourceLines splits source into the lines passed to lineFingerprint.
+func sourceLines(source []byte) []string {
+	return strings.Split(string(source), "\n")
+}
+
+// lineFingerprint returns the fingerprint of line n (1-based) of lines: a
+// digest of its content with surrounding whitespace removed, so that it does
+// not depend on the line number or on re-indentation.
+func lineFingerprint(lines []string, n int) string {
+	var line string
+	if n >= 1 && n <= len(lines) {
+		line = strings.TrimSpace(lines[n-1])
+	}
+	sum := sha256.Sum256([]byte(line))
+	return hex.EncodeToString(sum[:8])
+}
+
+// relFile returns file in the form used by baselineEntry.File.
+func (b *baseline) relFile(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(b.dir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// suppress returns the violations of file that match no baseline entry. Each
+// entry matches at most one violation, so a new copy of a known violation is
+// still reported. Calling suppress again for the same file, as watch mode
+// does, replaces the matches of the previous call.
+func (b *baseline) suppress(file string, source []byte, violations []lint.Violation) []lint.Violation {
+	rel := b.relFile(file)
+	b.linted[rel] = true
+	available := make(map[baselineKey][]int)
+	for i, e := range b.entries {
+		if e.File == rel {
+			delete(b.matched, i)
+			k := baselineKey{e.Rule, e.Fingerprint}
+			available[k] = append(available[k], i)
+		}
+	}
+	var kept []lint.Violation
+	lines := sourceLines(source)
+	for _, v := range violations {
+		k := baselineKey{v.Rule, lineFingerprint(lines, v.Line)}
+		if idx := available[k]; len(idx) > 0 {
+			b.matched[idx[0]] = true
+			available[k] = idx[1:]
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// stale returns the entries of files linted in this run that matched no
+// violation, ordered by file and line. They refer to violations that have
+// been fixed and can be dropped by writing the baseline again.
+func (b *baseline) stale() []baselineEntry {
+	var result []baselineEntry
+	for i, e := range b.entries {
+		if b.linted[e.File] && !b.matched[i] {
+			result = append(result, e)
+		}
+	}
+	sortBaselineEntries(result)
+	return result
+}
+
+// record replaces the entries of file with its current violations.
+func (b *baseline) record(file string, source []byte, violations []lint.Violation) {
+	rel := b.relFile(file)
+	if !b.linted[rel] {
+		b.linted[rel] = true
+		kept := b.entries[:0]
+		for _, e := range b.entries {
+			if e.File != rel {
+				kept = append(kept, e)
+			}
+		}
+		b.entries = kept
+	}
+	lines := sourceLines(source)
+	for _, v := range violations {
+		b.entries = append(b.entries, baselineEntry{
+			Rule:        v.Rule,
+			File:        rel,
+			Line:        v.Line,
+			Fingerprint: lineFingerprint(lines, v.Line),
+		})
+	}
+}
+
+// save writes the entries to path as indented JSON.
+func (b *baseline) save(path string) error {
+	sortBaselineEntries(b.entries)
+	f := baselineFile{Version: baselineVersion, Entries: b.entries}
+	if f.Entries == nil {
+		f.Entries = []baselineEntry{}
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// sortBaselineEntries orders entries by file, line, and rule.
+func sortBaselineEntries(entries []baselineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+}
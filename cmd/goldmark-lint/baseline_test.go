@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+func TestLineFingerprint(t *testing.T) {
+	lines := sourceLines([]byte("# Title\n\n  Some text  \nOther\n"))
+	if lineFingerprint(lines, 3) != lineFingerprint(sourceLines([]byte("Some text")), 1) {
+		t.Error("expected surrounding whitespace to be ignored")
+	}
+	if lineFingerprint(lines, 3) == lineFingerprint(lines, 4) {
+		t.Error("expected different lines to have different fingerprints")
+	}
+	if lineFingerprint(lines, 99) != lineFingerprint(nil, 1) {
+		t.Error("expected out-of-range lines to fingerprint as empty")
+	}
+}
+
+func TestBaseline_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	file := filepath.Join(dir, "docs", "a.md")
+	source := []byte("# A\n\nTrailing   \nTrailing   \n")
+	violations := []lint.Violation{
+		{Rule: "MD009", Line: 3},
+		{Rule: "MD009", Line: 4},
+	}
+
+	b, err := loadBaseline(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.record(file, source, violations)
+	if err := b.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = loadBaseline(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.entries) != 2 || b.entries[0].File != "docs/a.md" {
+		t.Fatalf("unexpected entries: %+v", b.entries)
+	}
+
+	// Lines shifted down by two; a third copy of the same line is new.
+	shifted := []byte("# A\n\nNew\n\nTrailing   \nTrailing   \nTrailing   \n")
+	got := b.suppress(file, shifted, []lint.Violation{
+		{Rule: "MD009", Line: 5},
+		{Rule: "MD009", Line: 6},
+		{Rule: "MD009", Line: 7},
+	})
+	if want := []lint.Violation{{Rule: "MD009", Line: 7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suppress = %+v, want %+v", got, want)
+	}
+	if stale := b.stale(); len(stale) != 0 {
+		t.Errorf("expected no stale entries, got %+v", stale)
+	}
+
+	// One violation was fixed, so one entry is stale.
+	got = b.suppress(file, source, []lint.Violation{{Rule: "MD009", Line: 3}})
+	if len(got) != 0 {
+		t.Errorf("expected all violations to be suppressed, got %+v", got)
+	}
+	if stale := b.stale(); len(stale) != 1 {
+		t.Errorf("expected one stale entry, got %+v", stale)
+	}
+}
+
+func TestBaseline_RecordKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	b := newBaseline(filepath.Join(dir, "baseline.json"))
+	b.entries = []baselineEntry{
+		{Rule: "MD001", File: "a.md", Line: 1},
+		{Rule: "MD001", File: "b.md", Line: 1},
+	}
+	b.record(filepath.Join(dir, "a.md"), []byte("x\n"), []lint.Violation{{Rule: "MD041", Line: 1}})
+	var got []string
+	for _, e := range b.entries {
+		got = append(got, e.File+":"+e.Rule)
+	}
+	sort.Strings(got)
+	if want := []string{"a.md:MD041", "b.md:MD001"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestLoadBaseline_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadBaseline(filepath.Join(dir, "missing.json"), false); err == nil {
+		t.Error("expected an error for a missing baseline")
+	}
+	path := filepath.Join(dir, "baseline.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "entries": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadBaseline(path, false); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+}
+
+func TestCLI_Baseline(t *testing.T) {
+	bin := buildBinary(t)
+	dir := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "doc.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) (string, int) {
+		t.Helper()
+		cmd := exec.Command(bin, append([]string{"--no-cache"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		code := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		return string(out), code
+	}
+
+	write("# Doc\n\nTrailing   \n")
+	if out, code := run("--baseline", "baseline.json", "--write-baseline", "doc.md"); code != 0 {
+		t.Fatalf("--write-baseline: exit code %d, output:\n%s", code, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "baseline.json")); err != nil {
+		t.Fatalf("expected baseline file: %v", err)
+	}
+
+	// The known violation moved down; only the new one is reported.
+	write("# Doc\n\nIntro\n\nTrailing   \n\nMore   \n")
+	out, code := run("--baseline", "baseline.json", "doc.md")
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d:\n%s", code, out)
+	}
+	if !strings.Contains(out, "doc.md:7") || strings.Contains(out, "doc.md:5") {
+		t.Errorf("expected only the new violation on line 7, got:\n%s", out)
+	}
+
+	// Fixing the known violation leaves a stale entry behind.
+	write("# Doc\n\nTrailing\n")
+	out, code = run("--baseline", "baseline.json", "doc.md")
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d:\n%s", code, out)
+	}
+	if !strings.Contains(out, "stale baseline entry for doc.md:3 MD009") {
+		t.Errorf("expected a stale entry warning, got:\n%s", out)
+	}
+
+	if _, code := run("--write-baseline", "doc.md"); code != 2 {
+		t.Errorf("expected exit code 2 for --write-baseline without --baseline, got %d", code)
+	}
+	if _, code := run("--baseline", "missing.json", "doc.md"); code != 2 {
+		t.Errorf("expected exit code 2 for a missing baseline, got %d", code)
+	}
+}
@@ -6,46 +6,174 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 )
 
 const cacheFileName = ".goldmark-lint-cache"
 
-// cacheEntry stores the lint result for a single file indexed by its content hash.
+// cacheEntry stores the lint result for a single file indexed by its content
+// hash and the hash of the settings it was linted with.
 type cacheEntry struct {
 	Hash       string           `json:"hash"`
+	Config     string           `json:"config"`
 	Violations []lint.Violation `json:"violations"`
 }
 
-// lintCache maps absolute file paths to their cached lint results.
+// lintCache maps file paths, relative to the cache root, to their cached lint
+// results.
 type lintCache map[string]cacheEntry
 
+// cacheSettings holds what besides its content decides the lint result of a
+// file.
+type cacheSettings struct {
+	Version        string                 `json:"version"`
+	Config         map[string]interface{} `json:"config"`
+	CustomRules    []CustomRuleConfig     `json:"customRules,omitempty"`
+	NoInlineConfig bool                   `json:"noInlineConfig,omitempty"`
+	FrontMatter    string                 `json:"frontMatter,omitempty"`
+	Flavor         string                 `json:"flavor,omitempty"`
+}
+
+// hash returns the SHA-256 hex digest of s.
+func (s cacheSettings) hash() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		// Settings that cannot be marshalled never match a cache entry.
+		return ""
+	}
+	return hashContent(data)
+}
+
 // hashContent returns the SHA-256 hex digest of data.
 func hashContent(data []byte) string {
 	sum := sha256.Sum256(data)
 	return hex.EncodeToString(sum[:])
 }
 
-// loadCache reads the cache file from dir and returns its contents.
+// toolVersion returns the version the cache is keyed by. Development builds
+// add the VCS revision they were built from, when known, so that rebuilding
+// from another commit invalidates the cache as well.
+func toolVersion() string {
+	if version != "dev" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version
+	}
+	v := version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v += "+" + s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				v += "-dirty"
+			}
+		}
+	}
+	return v
+}
+
+// cacheRoot returns the directory that cache keys are relative to: the root
+// of the git repository containing cwd, or cwd itself outside a repository.
+func cacheRoot(cwd string) string {
+	if root := findGitRoot(cwd); root != "" {
+		return root
+	}
+	return cwd
+}
+
+// cachePath returns the path of the cache file. location is the value of
+// --cache-location: a file, or a directory to keep cacheFileName in. Without
+// it, the cache file is kept in root.
+func cachePath(root, location string) string {
+	if location == "" {
+		return filepath.Join(root, cacheFileName)
+	}
+	if strings.HasSuffix(location, "/") || strings.HasSuffix(location, string(filepath.Separator)) {
+		return filepath.Join(location, cacheFileName)
+	}
+	if info, err := os.Stat(location); err == nil && info.IsDir() {
+		return filepath.Join(location, cacheFileName)
+	}
+	return location
+}
+
+// cacheKey returns the key of file in a cache for root: its path relative to
+// root with forward slashes, so that runs from any working directory share
+// entries. Files outside root are keyed by their absolute path.
+func cacheKey(root, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// loadCache reads the cache file at path and returns its contents.
 // On any error an empty cache is returned.
-func loadCache(dir string) lintCache {
-	data, err := os.ReadFile(filepath.Join(dir, cacheFileName))
+func loadCache(path string) lintCache {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return make(lintCache)
 	}
 	var c lintCache
-	if err := json.Unmarshal(data, &c); err != nil {
+	if err := json.Unmarshal(data, &c); err != nil || c == nil {
 		return make(lintCache)
 	}
 	return c
 }
 
-// saveCache writes c to the cache file in dir.
-func saveCache(dir string, c lintCache) error {
+// pruneCache removes the entries of files that no longer exist from c, a
+// cache for root, and reports whether it removed any.
+func pruneCache(root string, c lintCache) bool {
+	pruned := false
+	for key := range c {
+		path := filepath.FromSlash(key)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(c, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// saveCache writes c to the cache file at path. The file is replaced
+// atomically, so that concurrent runs never leave a partly written cache.
+func saveCache(path string, c lintCache) error {
 	data, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(dir, cacheFileName), data, 0644)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
@@ -34,7 +34,7 @@ func TestHashContent_Distinct(t *testing.T) {
 
 func TestLoadCache_Missing(t *testing.T) {
 	dir := t.TempDir()
-	c := loadCache(dir)
+	c := loadCache(filepath.Join(dir, cacheFileName))
 	if len(c) != 0 {
 		t.Errorf("expected empty cache for missing file, got %d entries", len(c))
 	}
@@ -45,7 +45,7 @@ func TestLoadCache_Corrupt(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, cacheFileName), []byte("not json"), 0644); err != nil {
 		t.Fatal(err)
 	}
-	c := loadCache(dir)
+	c := loadCache(filepath.Join(dir, cacheFileName))
 	if len(c) != 0 {
 		t.Errorf("expected empty cache for corrupt file, got %d entries", len(c))
 	}
@@ -59,11 +59,11 @@ func TestSaveAndLoadCache(t *testing.T) {
 	c := lintCache{
 		"/some/file.md": {Hash: "abc123", Violations: violations},
 	}
-	if err := saveCache(dir, c); err != nil {
+	if err := saveCache(filepath.Join(dir, cacheFileName), c); err != nil {
 		t.Fatalf("saveCache error: %v", err)
 	}
 
-	loaded := loadCache(dir)
+	loaded := loadCache(filepath.Join(dir, cacheFileName))
 	entry, ok := loaded["/some/file.md"]
 	if !ok {
 		t.Fatal("expected entry for /some/file.md")
@@ -78,7 +78,7 @@ func TestSaveAndLoadCache(t *testing.T) {
 
 func TestSaveCache_CreatesFile(t *testing.T) {
 	dir := t.TempDir()
-	if err := saveCache(dir, make(lintCache)); err != nil {
+	if err := saveCache(filepath.Join(dir, cacheFileName), make(lintCache)); err != nil {
 		t.Fatalf("saveCache error: %v", err)
 	}
 	if _, err := os.Stat(filepath.Join(dir, cacheFileName)); err != nil {
@@ -89,7 +89,7 @@ func TestSaveCache_CreatesFile(t *testing.T) {
 func TestSaveCache_ValidJSON(t *testing.T) {
 	dir := t.TempDir()
 	c := lintCache{"a.md": {Hash: "h", Violations: nil}}
-	if err := saveCache(dir, c); err != nil {
+	if err := saveCache(filepath.Join(dir, cacheFileName), c); err != nil {
 		t.Fatal(err)
 	}
 	data, err := os.ReadFile(filepath.Join(dir, cacheFileName))
@@ -102,6 +102,84 @@ func TestSaveCache_ValidJSON(t *testing.T) {
 	}
 }
 
+func TestSaveCache_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "cache.json")
+	if err := saveCache(path, lintCache{"a.md": {Hash: "h"}}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "cache.json" {
+		t.Errorf("expected only the cache file, got %v", entries)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	root := t.TempDir()
+	if got := cacheKey(root, filepath.Join(root, "docs", "a.md")); got != "docs/a.md" {
+		t.Errorf("cacheKey inside root = %q, want docs/a.md", got)
+	}
+	outside := filepath.Join(filepath.Dir(root), "other.md")
+	if got := cacheKey(root, outside); got != filepath.ToSlash(outside) {
+		t.Errorf("cacheKey outside root = %q, want %q", got, filepath.ToSlash(outside))
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		location, want string
+	}{
+		{"", filepath.Join(root, cacheFileName)},
+		{root, filepath.Join(root, cacheFileName)},
+		{filepath.Join(root, "tmp") + "/", filepath.Join(root, "tmp", cacheFileName)},
+		{filepath.Join(root, "lint.json"), filepath.Join(root, "lint.json")},
+	}
+	for _, tt := range tests {
+		if got := cachePath(root, tt.location); got != tt.want {
+			t.Errorf("cachePath(%q) = %q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "kept.md"), []byte("# Kept\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := lintCache{"kept.md": {Hash: "a"}, "deleted.md": {Hash: "b"}}
+	if !pruneCache(root, c) {
+		t.Error("expected pruneCache to report a removed entry")
+	}
+	if _, ok := c["kept.md"]; !ok || len(c) != 1 {
+		t.Errorf("expected only kept.md to remain, got %v", c)
+	}
+	if pruneCache(root, c) {
+		t.Error("expected nothing left to prune")
+	}
+}
+
+func TestCacheSettings_Hash(t *testing.T) {
+	base := cacheSettings{Version: "1.0.0", Config: map[string]interface{}{"MD013": map[string]interface{}{"line_length": 100}}}
+	same := cacheSettings{Version: "1.0.0", Config: map[string]interface{}{"MD013": map[string]interface{}{"line_length": 100}}}
+	if base.hash() != same.hash() {
+		t.Error("expected equal settings to hash the same")
+	}
+	changed := []cacheSettings{
+		{Version: "1.1.0", Config: base.Config},
+		{Version: "1.0.0", Config: map[string]interface{}{"MD013": map[string]interface{}{"line_length": 120}}},
+		{Version: "1.0.0", Config: base.Config, Flavor: "commonmark"},
+	}
+	for _, s := range changed {
+		if s.hash() == base.hash() {
+			t.Errorf("expected %+v to hash differently", s)
+		}
+	}
+}
+
 // TestCLI_Cache verifies that on the second run a file is served from cache
 // (the cache file is written and re-read, skipping the re-lint).
 func TestCLI_Cache(t *testing.T) {
@@ -171,6 +249,97 @@ func TestCLI_CacheInvalidatedOnChange(t *testing.T) {
 	}
 }
 
+// TestCLI_CacheInvalidatedOnConfigChange verifies that changing the config
+// re-lints files whose content is unchanged.
+func TestCLI_CacheInvalidatedOnConfigChange(t *testing.T) {
+	bin := buildBinary(t)
+	dir := t.TempDir()
+
+	mdFile := filepath.Join(dir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("Not a heading\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd1 := exec.Command(bin, mdFile)
+	cmd1.Dir = dir
+	if err := cmd1.Run(); err == nil {
+		t.Fatal("first run: expected non-zero exit for file with violations")
+	}
+
+	// Disable the rule in an override: the cached violation no longer applies.
+	cfg := "overrides:\n  - files: [\"*.md\"]\n    config:\n      MD041: false\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd2 := exec.Command(bin, mdFile)
+	cmd2.Dir = dir
+	if out, err := cmd2.CombinedOutput(); err != nil {
+		t.Errorf("second run: expected exit 0 after the config change, got: %v\n%s", err, out)
+	}
+}
+
+// TestCLI_CacheSharedAcrossDirectories verifies that the cache is kept at
+// the repository root with relative keys, whatever the working directory.
+func TestCLI_CacheSharedAcrossDirectories(t *testing.T) {
+	bin := buildBinary(t)
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "docs")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := os.WriteFile(filepath.Join(sub, name), []byte("# Heading\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd1 := exec.Command(bin, "docs/a.md", "docs/b.md")
+	cmd1.Dir = dir
+	if out, err := cmd1.CombinedOutput(); err != nil {
+		t.Fatalf("first run: %v\n%s", err, out)
+	}
+	// Deleted files are pruned when the cache is saved.
+	if err := os.Remove(filepath.Join(sub, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+	cmd2 := exec.Command(bin, "a.md")
+	cmd2.Dir = sub
+	if out, err := cmd2.CombinedOutput(); err != nil {
+		t.Fatalf("second run: %v\n%s", err, out)
+	}
+
+	if _, err := os.Stat(filepath.Join(sub, cacheFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected no cache file in the working directory")
+	}
+	c := loadCache(filepath.Join(dir, cacheFileName))
+	if _, ok := c["docs/a.md"]; !ok || len(c) != 1 {
+		t.Errorf("expected a single docs/a.md entry, got %v", c)
+	}
+}
+
+// TestCLI_CacheLocation verifies that --cache-location sets the cache file.
+func TestCLI_CacheLocation(t *testing.T) {
+	bin := buildBinary(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.md"), []byte("# Heading\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(dir, "cache", "lint.json")
+	cmd := exec.Command(bin, "--cache-location", location, "test.md")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("expected exit 0, got %v\n%s", err, out)
+	}
+	if _, ok := loadCache(location)["test.md"]; !ok {
+		t.Errorf("expected a test.md entry in %s", location)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected no default cache file with --cache-location")
+	}
+}
+
 // TestCLI_NoCache verifies that --no-cache prevents the cache file from being created.
 func TestCLI_NoCache(t *testing.T) {
 	bin := buildBinary(t)
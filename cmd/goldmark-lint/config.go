@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"go.yaml.in/yaml/v3"
@@ -33,6 +36,26 @@ type ConfigFile struct {
 	Fix              bool                   `yaml:"fix"              json:"fix"`
 	FrontMatter      string                 `yaml:"frontMatter"      json:"frontMatter"`
 	Gitignore        interface{}            `yaml:"gitignore"        json:"gitignore"`
+	CustomRules      []CustomRuleConfig     `yaml:"customRules"      json:"customRules"`
+	Flavor           string                 `yaml:"flavor"           json:"flavor"`
+
+	// sources are the absolute paths of the files the config was read from:
+	// the file itself, then the files it extends.
+	sources []string
+	// problems are those that validating the files found.
+	problems []configProblem
+	// layers are the configs of the files, before merging, in the order
+	// they apply: a file after the file it extends.
+	layers []configLayer
+	// dirs are the configs of the directories that layerConfig layered,
+	// from the outermost; nil unless the config was layered.
+	dirs []*ConfigFile
+}
+
+// configLayer is the config read from a single file.
+type configLayer struct {
+	source string // absolute path
+	cfg    *ConfigFile
 }
 
 var configFileNames = []string{
@@ -51,11 +74,8 @@ var configFileNames = []string{
 // or an empty string if none is found.
 func findConfigFile(dir string) string {
 	for {
-		for _, name := range configFileNames {
-			path := filepath.Join(dir, name)
-			if _, err := os.Stat(path); err == nil {
-				return path
-			}
+		if path := findDirConfigFile(dir); path != "" {
+			return path
 		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
@@ -66,6 +86,18 @@ func findConfigFile(dir string) string {
 	return ""
 }
 
+// findDirConfigFile returns the path of the config file in dir itself, or
+// an empty string if there is none.
+func findDirConfigFile(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 // isSimpleFormatConfig reports whether path is a .markdownlint.* file (not
 // .markdownlint-cli2.*). These files use a rule-only format where the entire
 // content is the rule config map, with no wrapping "config:" key.
@@ -78,12 +110,14 @@ func isSimpleFormatConfig(path string) bool {
 // "extends" references recursively. Circular references are detected and
 // reported as errors.
 func loadConfig(path string) (*ConfigFile, error) {
-	return loadConfigResolved(path, make(map[string]bool))
+	return loadConfigResolved(path, make(map[string]bool), nil)
 }
 
 // loadConfigResolved is the internal recursive implementation of loadConfig.
 // visited tracks absolute paths already being loaded to detect circular refs.
-func loadConfigResolved(path string, visited map[string]bool) (*ConfigFile, error) {
+// inherited are the custom rules declared by the config files of the
+// directories above that of path, which its rule config may name as well.
+func loadConfigResolved(path string, visited map[string]bool, inherited []CustomRuleConfig) (*ConfigFile, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
@@ -114,7 +148,13 @@ func loadConfigResolved(path string, visited map[string]bool) (*ConfigFile, erro
 		default:
 			return nil, fmt.Errorf("unsupported config file format: %s", path)
 		}
-		return &ConfigFile{Config: ruleCfg}, nil
+		cfg := &ConfigFile{
+			Config:   resolveOptionPaths(resolveRuleConfig(ruleCfg, inherited), filepath.Dir(absPath)),
+			sources:  []string{absPath},
+			problems: validateConfigData(path, data, inherited),
+		}
+		cfg.layers = []configLayer{{source: absPath, cfg: &ConfigFile{Config: cfg.Config}}}
+		return cfg, nil
 	}
 
 	var cfg ConfigFile
@@ -130,8 +170,28 @@ func loadConfigResolved(path string, visited map[string]bool) (*ConfigFile, erro
 	default:
 		return nil, fmt.Errorf("unsupported config file format: %s", path)
 	}
+	if cfg.Flavor != "" {
+		if _, err := lint.ParseFlavor(cfg.Flavor); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	if cfg.FrontMatter != "" {
+		if _, err := regexp.Compile(cfg.FrontMatter); err != nil {
+			return nil, fmt.Errorf("parsing %s: invalid frontMatter regex %q: %v", path, cfg.FrontMatter, err)
+		}
+	}
 
+	cfg.sources = []string{absPath}
 	if cfg.Extends == "" {
+		if err := validateCustomRules(cfg.CustomRules, absPath, inherited); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		custom := append(append([]CustomRuleConfig(nil), inherited...), cfg.CustomRules...)
+		cfg.resolveRuleNames(custom)
+		cfg.resolveOptionPaths(filepath.Dir(absPath))
+		cfg.problems = validateConfigData(path, data, custom)
+		own := cfg
+		cfg.layers = []configLayer{{source: absPath, cfg: &own}}
 		return &cfg, nil
 	}
 
@@ -141,10 +201,18 @@ func loadConfigResolved(path string, visited map[string]bool) (*ConfigFile, erro
 		extendsPath = filepath.Join(filepath.Dir(absPath), extendsPath)
 	}
 
-	baseCfg, err := loadConfigResolved(extendsPath, visited)
+	baseCfg, err := loadConfigResolved(extendsPath, visited, inherited)
 	if err != nil {
 		return nil, fmt.Errorf("loading extends %q: %w", extendsPath, err)
 	}
+	if err := validateCustomRules(cfg.CustomRules, absPath, append(append([]CustomRuleConfig(nil), inherited...), baseCfg.CustomRules...)); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	// The rule names may refer to custom rules declared by the base config.
+	custom := append(append(append([]CustomRuleConfig(nil), inherited...), baseCfg.CustomRules...), cfg.CustomRules...)
+	cfg.resolveRuleNames(custom)
+	cfg.resolveOptionPaths(filepath.Dir(absPath))
+	own := cfg
 
 	// Merge: base config is the foundation; the current config overrides it.
 	outputFormatters := baseCfg.OutputFormatters
@@ -161,19 +229,336 @@ func loadConfigResolved(path string, visited map[string]bool) (*ConfigFile, erro
 	if cfg.FrontMatter != "" {
 		frontMatter = cfg.FrontMatter
 	}
+	// Flavor: child overrides base when set.
+	flavor := baseCfg.Flavor
+	if cfg.Flavor != "" {
+		flavor = cfg.Flavor
+	}
 	merged := &ConfigFile{
 		Globs:            globs,
 		Fix:              baseCfg.Fix || cfg.Fix,
+		NoInlineConfig:   baseCfg.NoInlineConfig || cfg.NoInlineConfig,
 		FrontMatter:      frontMatter,
 		Gitignore:        mergeGitignore(baseCfg.Gitignore, cfg.Gitignore),
 		Config:           mergeConfigs(baseCfg.Config, cfg.Config),
 		Ignores:          append(baseCfg.Ignores, cfg.Ignores...),
 		Overrides:        append(baseCfg.Overrides, cfg.Overrides...),
 		OutputFormatters: outputFormatters,
+		CustomRules:      append(baseCfg.CustomRules, cfg.CustomRules...),
+		Flavor:           flavor,
+		sources:          append(cfg.sources, baseCfg.sources...),
+		problems:         append(validateConfigData(path, data, custom), baseCfg.problems...),
+		layers:           append(append([]configLayer(nil), baseCfg.layers...), configLayer{source: absPath, cfg: &own}),
 	}
 	return merged, nil
 }
 
+// resolveRuleNames resolves the keys of the rule configs of c, its "config"
+// and those of its overrides, with resolveRuleConfig.
+func (c *ConfigFile) resolveRuleNames(custom []CustomRuleConfig) {
+	c.Config = resolveRuleConfig(c.Config, custom)
+	for i := range c.Overrides {
+		c.Overrides[i].Config = resolveRuleConfig(c.Overrides[i].Config, custom)
+	}
+}
+
+// resolveOptionPaths resolves the relative paths in the rule options of c
+// against dir, the directory of the config file that sets them.
+func (c *ConfigFile) resolveOptionPaths(dir string) {
+	c.Config = resolveOptionPaths(c.Config, dir)
+	for i := range c.Overrides {
+		c.Overrides[i].Config = resolveOptionPaths(c.Overrides[i].Config, dir)
+	}
+}
+
+// pathOptions lists the rule options that name files, by rule ID.
+var pathOptions = map[string][]string{
+	"GL004": {"cache_file"},
+}
+
+// resolveOptionPaths returns cfg, a rule config with resolved rule IDs, with
+// the relative paths among the options in pathOptions made relative to dir,
+// as the commands of custom rules are, rather than to the working directory.
+func resolveOptionPaths(cfg map[string]interface{}, dir string) map[string]interface{} {
+	for id, names := range pathOptions {
+		options, ok := cfg[id].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resolved := make(map[string]interface{}, len(options))
+		for k, v := range options {
+			resolved[k] = v
+		}
+		for _, name := range names {
+			if p, ok := resolved[name].(string); ok && p != "" && !filepath.IsAbs(p) {
+				resolved[name] = filepath.Join(dir, p)
+			}
+		}
+		cfg[id] = resolved
+	}
+	return cfg
+}
+
+// The kinds of rule config keys, in order of precedence.
+const (
+	ruleKeyID = iota
+	ruleKeyAlias
+	ruleKeyTag
+)
+
+// resolveRuleConfig returns cfg with its keys resolved to rule IDs, so that
+// the rest of the config handling only has to look up IDs. As in markdownlint,
+// an ID is matched ignoring case, an alias stands for the ID of its rule, and
+// a tag for the IDs of all rules that have it, except opt-in rules, which are
+// only enabled by their ID or an alias; custom lists the custom rules that
+// may be named. Since config maps are unordered, a rule named by several
+// keys takes the value of the key with the highest precedence: its ID, then
+// an alias, then a tag. Among tags, one set to false wins, so that disabling a
+// group of rules is never undone by enabling another. A tag set to an object
+// enables its rules with their default options, and the severity it gives, if
+// any. "default" and keys that name no rule or tag are kept as they are.
+func resolveRuleConfig(cfg map[string]interface{}, custom []CustomRuleConfig) map[string]interface{} {
+	if cfg == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resolved := make(map[string]interface{}, len(cfg))
+	ranks := make(map[string]int)
+	for _, key := range keys {
+		val := cfg[key]
+		ids, rank := ruleKeyIDs(key, custom)
+		if len(ids) == 0 {
+			resolved[key] = val
+			continue
+		}
+		if m, ok := val.(map[string]interface{}); ok && rank == ruleKeyTag {
+			val = true
+			if severity, ok := m["severity"].(string); ok {
+				val = severity
+			}
+		}
+		for _, id := range ids {
+			if old, ok := ranks[id]; ok && (rank > old || rank == old && (rank != ruleKeyTag || val != false)) {
+				continue
+			}
+			resolved[id] = val
+			ranks[id] = rank
+		}
+	}
+	return resolved
+}
+
+// ruleKeyIDs returns the IDs of the rules, registered or custom, that the
+// config key names, and whether it names them by ID, alias or tag.
+func ruleKeyIDs(key string, custom []CustomRuleConfig) ([]string, int) {
+	if e, ok := rules.Lookup(key); ok {
+		if strings.EqualFold(e.ID, key) {
+			return []string{e.ID}, ruleKeyID
+		}
+		return []string{e.ID}, ruleKeyAlias
+	}
+	for _, def := range custom {
+		if strings.EqualFold(def.ID, key) {
+			return []string{def.ID}, ruleKeyID
+		}
+		if def.hasName(key) {
+			return []string{def.ID}, ruleKeyAlias
+		}
+	}
+	var ids []string
+	for _, e := range rules.All() {
+		if hasTag(e.Tags, key) && !e.OptIn {
+			ids = append(ids, e.ID)
+		}
+	}
+	for _, def := range custom {
+		if hasTag(def.Tags, key) {
+			ids = append(ids, def.ID)
+		}
+	}
+	return ids, ruleKeyTag
+}
+
+// hasTag reports whether tags contains tag, ignoring case.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirConfigs resolves the config that applies to the files of each directory,
+// as markdownlint-cli2 does: the config files of the directories below root
+// are layered, from the outermost to the innermost, on top of the config of
+// root, as layerConfig describes. Directories outside of root use the root
+// config. Results are cached per directory.
+type dirConfigs struct {
+	root string      // absolute path
+	base *ConfigFile // the config of root, nil if none
+
+	mu    sync.Mutex
+	cache map[string]dirConfig
+	// problems are those that validating the config files found.
+	problems []configProblem
+}
+
+// dirConfig is the resolved config of a directory.
+type dirConfig struct {
+	cfg *ConfigFile
+	err error
+	// path is the config file in the directory itself, "" if none, and
+	// stamps hold the stamps of it and the files it extends.
+	path   string
+	stamps map[string]fileStamp
+}
+
+// newDirConfigs returns the dirConfigs for the directory root, whose config
+// is base.
+func newDirConfigs(root string, base *ConfigFile) *dirConfigs {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &dirConfigs{root: root, base: base, cache: make(map[string]dirConfig)}
+}
+
+// forFile returns the config for the file at path.
+func (d *dirConfigs) forFile(path string) (*ConfigFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return d.base, nil
+	}
+	return d.forDir(filepath.Dir(abs))
+}
+
+// forDir returns the config for the files in dir, an absolute path.
+func (d *dirConfigs) forDir(dir string) (*ConfigFile, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.resolve(dir)
+}
+
+func (d *dirConfigs) resolve(dir string) (*ConfigFile, error) {
+	if dir == d.root || !isSubdir(d.root, dir) {
+		return d.base, nil
+	}
+	if c, ok := d.cache[dir]; ok {
+		return c.cfg, c.err
+	}
+	parent, err := d.resolve(filepath.Dir(dir))
+	c := dirConfig{cfg: parent, err: err, path: findDirConfigFile(dir)}
+	if err == nil && c.path != "" {
+		var inherited []CustomRuleConfig
+		if parent != nil {
+			inherited = parent.CustomRules
+		}
+		cfg, err := loadConfigResolved(c.path, make(map[string]bool), inherited)
+		sources := []string{c.path}
+		if err != nil {
+			c.cfg, c.err = nil, fmt.Errorf("loading config %s: %w", c.path, err)
+		} else {
+			c.cfg = layerConfig(parent, cfg)
+			sources = cfg.sources
+			d.problems = append(d.problems, cfg.problems...)
+		}
+		c.stamps = make(map[string]fileStamp, len(sources))
+		for _, src := range sources {
+			c.stamps[src], _ = stampFile(src)
+		}
+	}
+	d.cache[dir] = c
+	return c.cfg, c.err
+}
+
+// changed reports whether a config file was added to, removed from or
+// changed in a directory since its config was resolved.
+func (d *dirConfigs) changed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for dir, c := range d.cache {
+		if findDirConfigFile(dir) != c.path {
+			return true
+		}
+		for path, old := range c.stamps {
+			if st, _ := stampFile(path); st != old {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// layerConfig returns parent with cfg, the config of a directory below it,
+// layered on top. parent may be nil. The keys that decide how the files of
+// the directory are linted are layered: "config" and "overrides" as
+// ruleConfigForFile describes, "customRules" are added to those of parent,
+// "frontMatter" and "flavor" replace those of parent when set, and
+// "noInlineConfig" is set when either sets it. The other keys, such as
+// "ignores" and "globs", only apply to a whole run and are taken from parent.
+func layerConfig(parent, cfg *ConfigFile) *ConfigFile {
+	if parent == nil {
+		return cfg
+	}
+	layered := *parent
+	layered.Config = mergeConfigs(parent.Config, cfg.Config)
+	layered.Overrides = append(append([]GlobOverride(nil), parent.Overrides...), cfg.Overrides...)
+	layered.CustomRules = append([]CustomRuleConfig(nil), parent.CustomRules...)
+	for _, def := range cfg.CustomRules {
+		if !isDeclared(def, parent.CustomRules) {
+			layered.CustomRules = append(layered.CustomRules, def)
+		}
+	}
+	if cfg.FrontMatter != "" {
+		layered.FrontMatter = cfg.FrontMatter
+	}
+	if cfg.Flavor != "" {
+		layered.Flavor = cfg.Flavor
+	}
+	layered.NoInlineConfig = parent.NoInlineConfig || cfg.NoInlineConfig
+	layered.sources = append(append([]string(nil), cfg.sources...), parent.sources...)
+	layered.layers = append(append([]configLayer(nil), parent.layers...), cfg.layers...)
+	layered.dirs = append(append([]*ConfigFile(nil), parent.dirLayers()...), cfg)
+	return &layered
+}
+
+// dirLayers returns the configs of the directories c is layered from, or c
+// itself when it is not layered.
+func (c *ConfigFile) dirLayers() []*ConfigFile {
+	if len(c.dirs) == 0 {
+		return []*ConfigFile{c}
+	}
+	return c.dirs
+}
+
+// ruleConfigForFile returns the rule config that c gives the file at
+// filePath. The "config" of each directory is merged on top of the rule
+// config of the directory above it, overrides included, and then its own
+// matching overrides apply; so an override only beats the configs of its own
+// directory and those above it.
+func (c *ConfigFile) ruleConfigForFile(filePath string) map[string]interface{} {
+	if len(c.dirs) == 0 {
+		return effectiveConfigForFile(c.Config, c.Overrides, filePath)
+	}
+	var cfg map[string]interface{}
+	for _, d := range c.dirs {
+		cfg = effectiveConfigForFile(mergeConfigs(cfg, d.Config), d.Overrides, filePath)
+	}
+	return cfg
+}
+
+// isSubdir reports whether dir is root or a directory below it. Both must be
+// absolute paths.
+func isSubdir(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // stripJSONComments removes // line comments and /* */ block comments from JSON
 // data, ignoring comment-like sequences inside strings.
 func stripJSONComments(data []byte) []byte {
@@ -197,6 +582,10 @@ func stripJSONComments(data []byte) []byte {
 				inBlockComment = false
 				i += 2
 			} else {
+				// Keep line breaks, so that lines keep their numbers.
+				if c == '\n' {
+					result = append(result, c)
+				}
 				i++
 			}
 			continue
@@ -239,8 +628,14 @@ func stripJSONComments(data []byte) []byte {
 }
 
 // isRuleEnabled returns whether the rule with the given ID should be run.
-// It checks the rule's config entry and falls back to the "default" key.
+// It checks the rule's config entry and falls back to the "default" key,
+// except for opt-in rules, which are disabled unless configured.
 func isRuleEnabled(id string, cfg map[string]interface{}) bool {
+	if _, ok := cfg[id]; !ok {
+		if e, ok := rules.Lookup(id); ok && e.OptIn {
+			return false
+		}
+	}
 	if val, ok := cfg[id]; ok {
 		switch v := val.(type) {
 		case bool:
@@ -259,39 +654,65 @@ func isRuleEnabled(id string, cfg map[string]interface{}) bool {
 	return true
 }
 
-// getRuleSeverity returns "warning" if the rule is configured with "warning"
-// severity, otherwise "error".
+// getRuleSeverity returns the severity of the rule id in cfg: "warning" or
+// "info" if the rule is set to that string or its options have a "severity"
+// key set to it, otherwise "error".
 func getRuleSeverity(id string, cfg map[string]interface{}) string {
-	if val, ok := cfg[id]; ok {
-		if s, ok := val.(string); ok && strings.ToLower(s) == "warning" {
-			return "warning"
-		}
+	var severity string
+	switch v := cfg[id].(type) {
+	case string:
+		severity = v
+	case map[string]interface{}:
+		severity, _ = v["severity"].(string)
+	}
+	switch severity = strings.ToLower(severity); severity {
+	case "warning", "info":
+		return severity
 	}
 	return "error"
 }
 
-// applyRuleConfig applies rule-specific config options to the rule pointer by
-// marshaling the config map entry to JSON and unmarshaling it into the rule.
-func applyRuleConfig(rule interface{}, cfg map[string]interface{}, id string) {
-	val, ok := cfg[id]
-	if !ok {
-		return
-	}
-	m, ok := val.(map[string]interface{})
-	if !ok {
-		return
-	}
-	data, err := json.Marshal(m)
-	if err != nil {
-		return
+// ruleOptions returns the options object configured for the rule id in cfg,
+// without its "severity" key, or nil if the rule's entry is not an object.
+func ruleOptions(cfg map[string]interface{}, id string) map[string]interface{} {
+	m, _ := cfg[id].(map[string]interface{})
+	if _, ok := m["severity"]; !ok {
+		return m
+	}
+	options := make(map[string]interface{}, len(m)-1)
+	for k, v := range m {
+		if k != "severity" {
+			options[k] = v
+		}
 	}
-	_ = json.Unmarshal(data, rule)
+	return options
 }
 
-// newLinterFromConfig creates a Linter using the given rule config map.
-// If cfg is nil, all rules are enabled with their default options.
-func newLinterFromConfig(cfg map[string]interface{}) *lint.Linter {
-	return lint.NewLinter(buildRules(cfg)...)
+// newLinterFromConfig creates a Linter using the given rule config map and
+// custom rule declarations. If cfg is nil, all rules are enabled with their
+// default options.
+func newLinterFromConfig(cfg map[string]interface{}, custom ...CustomRuleConfig) *lint.Linter {
+	l := lint.NewLinter(buildRules(cfg, custom...)...)
+	l.BuildRule = ruleBuilder(custom)
+	return l
+}
+
+// ruleBuilder returns a lint.Linter.BuildRule function that builds custom
+// rules as well as the rules in the registry, so that configure-file comments
+// can set the options of either.
+func ruleBuilder(custom []CustomRuleConfig) func(string, map[string]interface{}) (lint.Rule, error) {
+	return func(id string, options map[string]interface{}) (lint.Rule, error) {
+		for _, def := range custom {
+			if def.hasName(id) {
+				var cfg map[string]interface{}
+				if options != nil {
+					cfg = map[string]interface{}{def.ID: options}
+				}
+				return newCustomRule(def, cfg), nil
+			}
+		}
+		return rules.Build(id, options)
+	}
 }
 
 // ruleInfo holds metadata about a rule for the --list-rules display.
@@ -307,74 +728,39 @@ type ruleFactory struct {
 	factory func() lint.Rule
 }
 
-// makeRuleFactories returns the ordered list of rule factories, each of which
-// creates a rule instance with options applied from cfg.
+// makeRuleFactories returns a factory for every rule in the rules registry,
+// in registration order, each of which creates the rule with options applied
+// from cfg.
 func makeRuleFactories(cfg map[string]interface{}) []ruleFactory {
-	return []ruleFactory{
-		{"MD001", func() lint.Rule { r := &rules.MD001{}; applyRuleConfig(r, cfg, "MD001"); return r }},
-		{"MD003", func() lint.Rule { r := &rules.MD003{}; applyRuleConfig(r, cfg, "MD003"); return r }},
-		{"MD004", func() lint.Rule { r := &rules.MD004{}; applyRuleConfig(r, cfg, "MD004"); return r }},
-		{"MD005", func() lint.Rule { r := &rules.MD005{}; applyRuleConfig(r, cfg, "MD005"); return r }},
-		{"MD007", func() lint.Rule { r := &rules.MD007{}; applyRuleConfig(r, cfg, "MD007"); return r }},
-		{"MD009", func() lint.Rule { r := &rules.MD009{}; applyRuleConfig(r, cfg, "MD009"); return r }},
-		{"MD010", func() lint.Rule { r := &rules.MD010{}; applyRuleConfig(r, cfg, "MD010"); return r }},
-		{"MD011", func() lint.Rule { r := &rules.MD011{}; applyRuleConfig(r, cfg, "MD011"); return r }},
-		{"MD012", func() lint.Rule { r := &rules.MD012{}; applyRuleConfig(r, cfg, "MD012"); return r }},
-		{"MD013", func() lint.Rule { r := &rules.MD013{}; applyRuleConfig(r, cfg, "MD013"); return r }},
-		{"MD014", func() lint.Rule { r := &rules.MD014{}; applyRuleConfig(r, cfg, "MD014"); return r }},
-		{"MD018", func() lint.Rule { r := &rules.MD018{}; applyRuleConfig(r, cfg, "MD018"); return r }},
-		{"MD019", func() lint.Rule { r := &rules.MD019{}; applyRuleConfig(r, cfg, "MD019"); return r }},
-		{"MD020", func() lint.Rule { r := &rules.MD020{}; applyRuleConfig(r, cfg, "MD020"); return r }},
-		{"MD021", func() lint.Rule { r := &rules.MD021{}; applyRuleConfig(r, cfg, "MD021"); return r }},
-		{"MD022", func() lint.Rule { r := &rules.MD022{}; applyRuleConfig(r, cfg, "MD022"); return r }},
-		{"MD023", func() lint.Rule { r := &rules.MD023{}; applyRuleConfig(r, cfg, "MD023"); return r }},
-		{"MD024", func() lint.Rule { r := &rules.MD024{}; applyRuleConfig(r, cfg, "MD024"); return r }},
-		{"MD025", func() lint.Rule { r := &rules.MD025{}; applyRuleConfig(r, cfg, "MD025"); return r }},
-		{"MD026", func() lint.Rule { r := &rules.MD026{}; applyRuleConfig(r, cfg, "MD026"); return r }},
-		{"MD027", func() lint.Rule { r := &rules.MD027{}; applyRuleConfig(r, cfg, "MD027"); return r }},
-		{"MD028", func() lint.Rule { r := &rules.MD028{}; applyRuleConfig(r, cfg, "MD028"); return r }},
-		{"MD029", func() lint.Rule { r := &rules.MD029{}; applyRuleConfig(r, cfg, "MD029"); return r }},
-		{"MD030", func() lint.Rule { r := &rules.MD030{}; applyRuleConfig(r, cfg, "MD030"); return r }},
-		{"MD031", func() lint.Rule { r := &rules.MD031{}; applyRuleConfig(r, cfg, "MD031"); return r }},
-		{"MD032", func() lint.Rule { r := &rules.MD032{}; applyRuleConfig(r, cfg, "MD032"); return r }},
-		{"MD033", func() lint.Rule { r := &rules.MD033{}; applyRuleConfig(r, cfg, "MD033"); return r }},
-		{"MD034", func() lint.Rule { r := &rules.MD034{}; applyRuleConfig(r, cfg, "MD034"); return r }},
-		{"MD035", func() lint.Rule { r := &rules.MD035{}; applyRuleConfig(r, cfg, "MD035"); return r }},
-		{"MD036", func() lint.Rule { r := &rules.MD036{}; applyRuleConfig(r, cfg, "MD036"); return r }},
-		{"MD037", func() lint.Rule { r := &rules.MD037{}; applyRuleConfig(r, cfg, "MD037"); return r }},
-		{"MD038", func() lint.Rule { r := &rules.MD038{}; applyRuleConfig(r, cfg, "MD038"); return r }},
-		{"MD039", func() lint.Rule { r := &rules.MD039{}; applyRuleConfig(r, cfg, "MD039"); return r }},
-		{"MD040", func() lint.Rule { r := &rules.MD040{}; applyRuleConfig(r, cfg, "MD040"); return r }},
-		{"MD041", func() lint.Rule { r := &rules.MD041{}; applyRuleConfig(r, cfg, "MD041"); return r }},
-		{"MD042", func() lint.Rule { r := &rules.MD042{}; applyRuleConfig(r, cfg, "MD042"); return r }},
-		{"MD043", func() lint.Rule { r := &rules.MD043{}; applyRuleConfig(r, cfg, "MD043"); return r }},
-		{"MD044", func() lint.Rule { r := &rules.MD044{}; applyRuleConfig(r, cfg, "MD044"); return r }},
-		{"MD045", func() lint.Rule { r := &rules.MD045{}; applyRuleConfig(r, cfg, "MD045"); return r }},
-		{"MD046", func() lint.Rule { r := &rules.MD046{}; applyRuleConfig(r, cfg, "MD046"); return r }},
-		{"MD047", func() lint.Rule { r := &rules.MD047{}; applyRuleConfig(r, cfg, "MD047"); return r }},
-		{"MD048", func() lint.Rule { r := &rules.MD048{}; applyRuleConfig(r, cfg, "MD048"); return r }},
-		{"MD049", func() lint.Rule { r := &rules.MD049{}; applyRuleConfig(r, cfg, "MD049"); return r }},
-		{"MD050", func() lint.Rule { r := &rules.MD050{}; applyRuleConfig(r, cfg, "MD050"); return r }},
-		{"MD051", func() lint.Rule { r := &rules.MD051{}; applyRuleConfig(r, cfg, "MD051"); return r }},
-		{"MD052", func() lint.Rule { r := &rules.MD052{}; applyRuleConfig(r, cfg, "MD052"); return r }},
-		{"MD053", func() lint.Rule { r := &rules.MD053{}; applyRuleConfig(r, cfg, "MD053"); return r }},
-		{"MD054", func() lint.Rule { r := &rules.MD054{}; applyRuleConfig(r, cfg, "MD054"); return r }},
-		{"MD055", func() lint.Rule { r := &rules.MD055{}; applyRuleConfig(r, cfg, "MD055"); return r }},
-		{"MD056", func() lint.Rule { r := &rules.MD056{}; applyRuleConfig(r, cfg, "MD056"); return r }},
-		{"MD058", func() lint.Rule { r := &rules.MD058{}; applyRuleConfig(r, cfg, "MD058"); return r }},
-		{"MD059", func() lint.Rule { r := &rules.MD059{}; applyRuleConfig(r, cfg, "MD059"); return r }},
-		{"MD060", func() lint.Rule { r := &rules.MD060{}; applyRuleConfig(r, cfg, "MD060"); return r }},
+	entries := rules.All()
+	factories := make([]ruleFactory, 0, len(entries))
+	for _, e := range entries {
+		factories = append(factories, ruleFactory{e.ID, func() lint.Rule {
+			r, _ := e.Build(ruleOptions(cfg, e.ID))
+			return r
+		}})
+	}
+	return factories
+}
+
+// customRuleFactories returns a factory for each custom rule declaration,
+// with options applied from cfg.
+func customRuleFactories(cfg map[string]interface{}, custom []CustomRuleConfig) []ruleFactory {
+	factories := make([]ruleFactory, 0, len(custom))
+	for _, def := range custom {
+		factories = append(factories, ruleFactory{def.ID, func() lint.Rule { return newCustomRule(def, cfg) }})
 	}
+	return factories
 }
 
 // buildAllRulesInfo returns metadata for every known rule, regardless of whether
 // it is enabled or disabled in cfg.  The enabled field reflects the effective
 // enabled/disabled state according to cfg.
-func buildAllRulesInfo(cfg map[string]interface{}) []ruleInfo {
+func buildAllRulesInfo(cfg map[string]interface{}, custom ...CustomRuleConfig) []ruleInfo {
 	if cfg == nil {
 		cfg = map[string]interface{}{}
 	}
-	factories := makeRuleFactories(cfg)
+	factories := append(makeRuleFactories(cfg), customRuleFactories(cfg, custom)...)
 	infos := make([]ruleInfo, 0, len(factories))
 	for _, f := range factories {
 		infos = append(infos, ruleInfo{
@@ -385,13 +771,14 @@ func buildAllRulesInfo(cfg map[string]interface{}) []ruleInfo {
 	return infos
 }
 
-// buildRules constructs the list of lint rules based on the provided config map.
-// If cfg is nil, all rules are enabled with their default options.
-func buildRules(cfg map[string]interface{}) []lint.Rule {
+// buildRules constructs the list of lint rules based on the provided config map,
+// followed by the given custom rules. If cfg is nil, all rules are enabled with
+// their default options.
+func buildRules(cfg map[string]interface{}, custom ...CustomRuleConfig) []lint.Rule {
 	if cfg == nil {
 		cfg = map[string]interface{}{}
 	}
-	factories := makeRuleFactories(cfg)
+	factories := append(makeRuleFactories(cfg), customRuleFactories(cfg, custom)...)
 	var result []lint.Rule
 	for _, f := range factories {
 		if isRuleEnabled(f.id, cfg) {
@@ -510,11 +897,29 @@ func findGitRoot(dir string) string {
 // collectGitignorePatterns reads all .gitignore files from cwd up to the git
 // repository root and returns the combined list of ignore patterns.
 func collectGitignorePatterns(cwd string) []string {
-	gitRoot := findGitRoot(cwd)
 	var patterns []string
+	for _, path := range gitignoreFiles(cwd, true) {
+		patterns = append(patterns, parseGitignore(path)...)
+	}
+	return patterns
+}
+
+// gitignoreFiles returns the paths of the .gitignore files that the gitignore
+// config value v reads in cwd: for bool true, those from cwd up to the git
+// repository root, whether they exist or not; for a glob pattern, those below
+// cwd that match it.
+func gitignoreFiles(cwd string, v interface{}) []string {
+	if !gitignoreIsEnabled(v) || cwd == "" {
+		return nil
+	}
+	if pattern := gitignoreGlobPattern(v); pattern != "" {
+		return findFilesMatchingGlob(cwd, pattern)
+	}
+	gitRoot := findGitRoot(cwd)
+	var paths []string
 	dir := cwd
 	for {
-		patterns = append(patterns, parseGitignore(filepath.Join(dir, ".gitignore"))...)
+		paths = append(paths, filepath.Join(dir, ".gitignore"))
 		if dir == gitRoot || gitRoot == "" {
 			break
 		}
@@ -524,7 +929,7 @@ func collectGitignorePatterns(cwd string) []string {
 		}
 		dir = parent
 	}
-	return patterns
+	return paths
 }
 
 // findFilesMatchingGlob walks root and returns the absolute paths of all files
@@ -2,9 +2,11 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -319,6 +321,15 @@ func TestIsRuleEnabled_DefaultFalse_ExplicitConfig(t *testing.T) {
 	}
 }
 
+func TestIsRuleEnabled_OptIn(t *testing.T) {
+	if isRuleEnabled("GL001", map[string]interface{}{"default": true}) {
+		t.Error("expected GL001 to be disabled unless configured explicitly")
+	}
+	if !isRuleEnabled("GL001", map[string]interface{}{"GL001": true}) {
+		t.Error("expected GL001 to be enabled when set to true")
+	}
+}
+
 func TestIsRuleEnabled_SeverityError(t *testing.T) {
 	cfg := map[string]interface{}{"MD013": "error"}
 	if !isRuleEnabled("MD013", cfg) {
@@ -361,10 +372,73 @@ func TestGetRuleSeverity_BoolTrue(t *testing.T) {
 	}
 }
 
+func TestGetRuleSeverity_Options(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"info", "info"},
+		{"Warning", "warning"},
+		{map[string]interface{}{"line_length": 120, "severity": "warning"}, "warning"},
+		{map[string]interface{}{"severity": "info"}, "info"},
+		{map[string]interface{}{"severity": "error"}, "error"},
+		{map[string]interface{}{"line_length": 120}, "error"},
+	}
+	for _, tt := range tests {
+		cfg := map[string]interface{}{"MD013": tt.value}
+		if got := getRuleSeverity("MD013", cfg); got != tt.want {
+			t.Errorf("getRuleSeverity(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRuleOptions_WithoutSeverity(t *testing.T) {
+	cfg := map[string]interface{}{"MD013": map[string]interface{}{"line_length": 120, "severity": "warning"}}
+	got := ruleOptions(cfg, "MD013")
+	if want := map[string]interface{}{"line_length": 120}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ruleOptions() = %v, want %v", got, want)
+	}
+	if cfg["MD013"].(map[string]interface{})["severity"] != "warning" {
+		t.Error("ruleOptions modified the config")
+	}
+}
+
 func TestBuildRules_AllEnabled(t *testing.T) {
 	got := buildRules(nil)
-	if len(got) != 53 {
-		t.Errorf("expected 53 rules, got %d", len(got))
+	if len(got) != 54 {
+		t.Errorf("expected 54 rules, got %d", len(got))
+	}
+}
+
+func TestNewLinterFromConfig_ConfigureFileOptions(t *testing.T) {
+	// configure-file options apply to built-in and custom rules alike.
+	custom := []CustomRuleConfig{{ID: "CUSTOM001", Aliases: []string{"my-rule"}, Command: []string{"true"}}}
+	l := newLinterFromConfig(map[string]interface{}{"MD013": false}, custom...)
+	if l.BuildRule == nil {
+		t.Fatal("expected BuildRule to be set")
+	}
+	r, err := l.BuildRule("line-length", map[string]interface{}{"line_length": 120})
+	if err != nil || r.ID() != "MD013" {
+		t.Errorf("BuildRule(line-length) = %v, %v; want MD013", r, err)
+	}
+	r, err = l.BuildRule("MY-RULE", map[string]interface{}{"max": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr, ok := r.(*customRule)
+	if !ok || cr.ID() != "CUSTOM001" || cr.options["max"] != 3 {
+		t.Errorf("BuildRule(my-rule) = %#v, want CUSTOM001 with max 3", r)
+	}
+
+	src := "<!-- markdownlint-configure-file { \"MD013\": { \"line_length\": 40 } } -->\n" + strings.Repeat("word ", 9) + "word\n"
+	found := false
+	for _, v := range l.Lint([]byte(src)) {
+		if v.Rule == "MD013" && v.Line == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected MD013 to be enabled by configure-file on line 2")
 	}
 }
 
@@ -640,6 +714,42 @@ func TestLoadConfig_Extends_JSON(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_OptionPaths(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared")
+	if err := os.Mkdir(shared, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(shared, "base.yaml"), []byte("config:\n  GL004:\n    cache_file: links.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	abs := filepath.Join(t.TempDir(), "abs.json")
+	child := "extends: shared/base.yaml\noverrides:\n  - files: [\"docs/**\"]\n    config:\n      GL004:\n        cache_file: .links.json\n" +
+		"  - files: [\"blog/**\"]\n    config:\n      GL004:\n        cache_file: " + abs + "\n"
+	childPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(childPath, []byte(child), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(childPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheFile := func(c map[string]interface{}) interface{} {
+		options, _ := c["GL004"].(map[string]interface{})
+		return options["cache_file"]
+	}
+	if got, want := cacheFile(cfg.Config), filepath.Join(shared, "links.json"); got != want {
+		t.Errorf("base cache_file = %v, want %v", got, want)
+	}
+	if got, want := cacheFile(cfg.Overrides[0].Config), filepath.Join(dir, ".links.json"); got != want {
+		t.Errorf("override cache_file = %v, want %v", got, want)
+	}
+	if got := cacheFile(cfg.Overrides[1].Config); got != abs {
+		t.Errorf("absolute cache_file = %v, want %v", got, abs)
+	}
+}
+
 func TestLoadConfig_Extends_CircularReference(t *testing.T) {
 	dir := t.TempDir()
 
@@ -1025,6 +1135,32 @@ func TestLoadConfig_FrontMatter(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_Flavor(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.yaml")
+	if err := os.WriteFile(basePath, []byte("flavor: php-extra\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte("extends: base.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Flavor != "php-extra" {
+		t.Errorf("expected flavor inherited from base, got %q", cfg.Flavor)
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("flavor: gfm+emoji\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(cfgPath); err == nil || !strings.Contains(err.Error(), "emoji") {
+		t.Errorf("expected an error for an unknown extension, got %v", err)
+	}
+}
+
 func TestLoadConfig_Gitignore(t *testing.T) {
 	dir := t.TempDir()
 	content := "gitignore: true\n"
@@ -1402,6 +1538,38 @@ func TestCLI_FrontMatterFromConfig(t *testing.T) {
 	}
 }
 
+func TestCLI_FlavorFromConfig(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	// With footnotes parsed, [^1] is a footnote reference, not an undefined
+	// shortcut reference.
+	mdFile := filepath.Join(dir, "test.md")
+	content := "# Notes\n\nText[^1].\n\n[^1]: A footnote.\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	cfgContent := "config:\n  MD052:\n    shortcut_syntax: true\n"
+	if err := os.WriteFile(cfgPath, []byte(cfgContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "--no-cache", mdFile)
+	cmd.Dir = dir
+	if err := cmd.Run(); err == nil {
+		t.Error("expected MD052 to report [^1] without the footnotes extension")
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("flavor: gfm+footnotes\n"+cfgContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.Command(bin, "--no-cache", mdFile)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected exit 0 with flavor gfm+footnotes, got %v:\n%s", err, out)
+	}
+}
+
 func TestCLI_FrontMatter_InvalidRegex(t *testing.T) {
 	bin := buildBinary(t)
 
@@ -1427,3 +1595,422 @@ func TestCLI_FrontMatter_InvalidRegex(t *testing.T) {
 		t.Errorf("invalid frontMatter regex exit code = %d, want 2", exitErr.ExitCode())
 	}
 }
+
+func TestDirConfigs_Layering(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	guides := filepath.Join(docs, "guides")
+	other := filepath.Join(root, "other")
+	for _, d := range []string{guides, other} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".markdownlint-cli2.yaml"), []byte("config:\n  MD013:\n    line_length: 80\n  MD001: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, ".markdownlint-cli2.yaml"), []byte("config:\n  MD013:\n    code_blocks: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(guides, ".markdownlint.yaml"), []byte("MD013:\n  line_length: 120\nMD001: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := loadConfig(filepath.Join(root, ".markdownlint-cli2.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDirConfigs(root, base)
+
+	for _, dir := range []string{root, other, t.TempDir()} {
+		cfg, err := d.forDir(dir)
+		if err != nil || cfg != base {
+			t.Errorf("forDir(%s) = %v, %v; want the root config", dir, cfg, err)
+		}
+	}
+
+	cfg, err := d.forFile(filepath.Join(guides, "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md013, _ := cfg.Config["MD013"].(map[string]interface{})
+	if md013["line_length"] != 120 || md013["code_blocks"] != false {
+		t.Errorf("MD013 = %v, want line_length 120 and code_blocks false", md013)
+	}
+	if cfg.Config["MD001"] != true {
+		t.Errorf("MD001 = %v, want true", cfg.Config["MD001"])
+	}
+
+	cfg, err = d.forDir(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md013, _ = cfg.Config["MD013"].(map[string]interface{})
+	if md013["line_length"] != 80 || cfg.Config["MD001"] != false {
+		t.Errorf("docs config = %v, want line_length 80 and MD001 false", cfg.Config)
+	}
+	if d.changed() {
+		t.Error("changed() = true before any config file changed")
+	}
+	if err := os.WriteFile(filepath.Join(other, ".markdownlint.yaml"), []byte("MD001: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.forDir(other); err != nil {
+		t.Fatal(err)
+	}
+	if !d.changed() {
+		t.Error("changed() = false after a config file was added")
+	}
+}
+
+func TestDirConfigs_OverridesBeforeNestedConfig(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rootCfg := "config:\n  MD013:\n    line_length: 80\n" +
+		"overrides:\n  - files: [\"**/*.md\"]\n    config:\n      MD013:\n        line_length: 60\n      MD033: false\n"
+	if err := os.WriteFile(filepath.Join(root, ".markdownlint-cli2.yaml"), []byte(rootCfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nestedCfg := "config:\n  MD013:\n    line_length: 120\n" +
+		"overrides:\n  - files: [\"**/long.md\"]\n    config:\n      MD013:\n        line_length: 200\n"
+	if err := os.WriteFile(filepath.Join(docs, ".markdownlint-cli2.yaml"), []byte(nestedCfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := loadConfig(filepath.Join(root, ".markdownlint-cli2.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup, err := newLintSetup(base, root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file       string
+		lineLength int
+	}{
+		{filepath.Join(root, "f.md"), 60},          // the root override beats the root config
+		{filepath.Join(docs, "f.md"), 120},         // the nested config beats the root override
+		{filepath.Join(docs, "long.md"), 200},      // the nested override beats both
+		{filepath.Join(root, "other", "f.md"), 60}, // no nested config applies
+	}
+	for _, tt := range tests {
+		ruleCfg, _, err := setup.ruleConfigFor(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md013, _ := ruleCfg["MD013"].(map[string]interface{})
+		if md013["line_length"] != tt.lineLength {
+			t.Errorf("%s: MD013 = %v, want line_length %d", tt.file, md013, tt.lineLength)
+		}
+		// The root override still applies to what the nested config leaves.
+		if ruleCfg["MD033"] != false {
+			t.Errorf("%s: MD033 = %v, want false", tt.file, ruleCfg["MD033"])
+		}
+	}
+}
+
+func TestDirConfigs_LayersLinterSettings(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	if err := os.Mkdir(docs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rootCfg := "frontMatter: \"(?s)^<!--.*?-->\\n\"\nflavor: commonmark\n" +
+		"customRules:\n  - id: TEAM001\n    aliases: [no-todo]\n    command: [./todo.sh]\n"
+	if err := os.WriteFile(filepath.Join(root, ".markdownlint-cli2.yaml"), []byte(rootCfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Extending the root config declares its custom rules once more.
+	nestedCfg := "extends: ../.markdownlint-cli2.yaml\nnoInlineConfig: true\nflavor: gfm\nconfig:\n  no-todo: false\n" +
+		"customRules:\n  - id: TEAM002\n    command: [./fixme.sh]\n"
+	if err := os.WriteFile(filepath.Join(docs, ".markdownlint-cli2.yaml"), []byte(nestedCfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := loadConfig(filepath.Join(root, ".markdownlint-cli2.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup, err := newLintSetup(base, root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings, own, err := setup.settingsFor(filepath.Join(docs, "a.md"))
+	if err != nil || !own {
+		t.Fatalf("settingsFor() = %v, %v; want settings of its own", own, err)
+	}
+	var ids []string
+	for _, def := range settings.CustomRules {
+		ids = append(ids, def.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"TEAM001", "TEAM002"}) {
+		t.Errorf("custom rules = %v, want TEAM001 and TEAM002", ids)
+	}
+	if settings.FrontMatter != base.FrontMatter || settings.Flavor != "gfm" || !settings.NoInlineConfig {
+		t.Errorf("settings = %+v, want the root frontMatter, flavor gfm and noInlineConfig", settings)
+	}
+	// The nested config may name the custom rules of the root config.
+	if settings.Config["TEAM001"] != false {
+		t.Errorf("TEAM001 = %v, want false", settings.Config["TEAM001"])
+	}
+	if len(setup.dirs.problems) != 0 {
+		t.Errorf("expected no config problems, got %v", setup.dirs.problems)
+	}
+	l, err := setup.linterFor(filepath.Join(docs, "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.NoInlineConfig || l.Flavor != "gfm" || l.FrontMatterRegexp == nil {
+		t.Errorf("linter settings = %v, %q, %v; want those of the nested config", l.NoInlineConfig, l.Flavor, l.FrontMatterRegexp)
+	}
+	for _, r := range l.Rules {
+		if r.ID() == "TEAM001" {
+			t.Error("expected TEAM001 to be disabled by the nested config")
+		}
+	}
+
+	settings, own, err = setup.settingsFor(filepath.Join(root, "a.md"))
+	if err != nil || own || len(settings.CustomRules) != 1 || settings.Flavor != "commonmark" || settings.NoInlineConfig {
+		t.Errorf("root settings = %+v, %v, %v; want those of the root config", settings, own, err)
+	}
+}
+
+func TestDirConfigs_InvalidNestedConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(sub, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte("config: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := newDirConfigs(root, nil).forDir(filepath.Join(sub))
+	if err == nil || !strings.Contains(err.Error(), cfgPath) {
+		t.Errorf("forDir error = %v, want one naming %s", err, cfgPath)
+	}
+}
+
+func TestCLI_NestedConfig(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	docsDir := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := "# Heading\n\n" + strings.Repeat("a", 90) + " extra\n"
+	rootFile := filepath.Join(dir, "file.md")
+	docsFile := filepath.Join(docsDir, "file.md")
+	for _, f := range []string{rootFile, docsFile} {
+		if err := os.WriteFile(f, []byte(line), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte("config:\n  MD013:\n    line_length: 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, ".markdownlint.yaml"), []byte("MD013:\n  line_length: 120\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--no-cache", "**/*.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected non-zero exit for the root file, got exit 0")
+	}
+	if !strings.Contains(string(out), "file.md:3") || strings.Contains(string(out), "docs/file.md") {
+		t.Errorf("expected MD013 only for the root file, got:\n%s", out)
+	}
+}
+
+func TestCLI_NestedConfig_CustomRules(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".markdownlint-cli2.yaml":      "config:\n  MD041: false\n",
+		"docs/.markdownlint-cli2.yaml": "customRules:\n  - id: TEAM001\n    description: Team rule\n    command: [./team.sh]\n",
+		"docs/team.sh":                 "#!/bin/sh\ncat >/dev/null\necho '{\"violations\": [{\"line\": 1}]}'\n",
+		"docs/guide.md":                "# Guide\n",
+		"root.md":                      "# Root\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The second run replays the results from the cache.
+	for run := 0; run < 2; run++ {
+		cmd := exec.Command(bin, "**/*.md")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("run %d: expected exit 1 from the nested custom rule, output:\n%s", run, out)
+		}
+		if !strings.Contains(string(out), "docs/guide.md:1:1 TEAM001 Team rule") || strings.Contains(string(out), "root.md") {
+			t.Errorf("run %d: expected TEAM001 only for docs/guide.md, got:\n%s", run, out)
+		}
+	}
+}
+
+func TestCLI_NestedConfig_ProjectRules(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".markdownlint-cli2.yaml": "config:\n  MD041: false\n",
+		"docs/.markdownlint.yaml": "GL001: true\nGL005: true\n",
+		"docs/guide.md":           "# Guide\n\n[Missing](missing.md)\n\n![Missing](missing.png)\n\n[Root](../root.md)\n",
+		"root.md":                 "# Root\n\n[Missing](missing.md)\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(bin, "--no-cache", "**/*.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected non-zero exit for the project rules enabled in docs, got exit 0:\n%s", out)
+	}
+	for _, want := range []string{"docs/guide.md:3:1 GL001", "docs/guide.md:5:1 GL005"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q, got:\n%s", want, out)
+		}
+	}
+	// GL001 stays off for the root file, and the link to it resolves.
+	if strings.Contains(string(out), "root.md:") || strings.Contains(string(out), ":7:1") {
+		t.Errorf("expected no violations outside of docs, got:\n%s", out)
+	}
+}
+
+func TestResolveRuleConfig(t *testing.T) {
+	custom := []CustomRuleConfig{{ID: "TEAM001", Aliases: []string{"no-todo"}, Tags: []string{"team"}}}
+	got := resolveRuleConfig(map[string]interface{}{
+		"default":        true,
+		"line-length":    map[string]interface{}{"line_length": 100},
+		"no-inline-html": false,
+		"md041":          false,
+		"whitespace":     false,
+		"MD009":          true,
+		"code":           map[string]interface{}{"style": "fenced"},
+		"headings":       true,
+		"blank_lines":    false,
+		"no-todo":        "warning",
+		"links":          map[string]interface{}{"severity": "info"},
+		"unknown-key":    1,
+	}, custom)
+
+	want := map[string]interface{}{
+		"default":     true,
+		"MD013":       map[string]interface{}{"line_length": 100},
+		"MD033":       false,
+		"MD041":       false,
+		"MD009":       true,  // the ID beats the whitespace tag
+		"MD010":       false, // whitespace
+		"MD046":       true,  // a tag set to an object enables its rules
+		"MD001":       true,  // headings
+		"MD022":       false, // false wins between headings and blank_lines
+		"TEAM001":     "warning",
+		"MD042":       "info", // a tag set to an object keeps its severity
+		"unknown-key": 1,
+	}
+	for key, w := range want {
+		g, ok := got[key]
+		if !ok || fmt.Sprint(g) != fmt.Sprint(w) {
+			t.Errorf("resolved[%q] = %v, want %v", key, g, w)
+		}
+	}
+	for _, key := range []string{"line-length", "no-inline-html", "md041", "whitespace", "headings", "no-todo"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("resolved config still has key %q", key)
+		}
+	}
+}
+
+func TestResolveRuleConfig_TagsSkipOptInRules(t *testing.T) {
+	cfg := resolveRuleConfig(map[string]interface{}{"links": true, "images": true}, nil)
+	for _, id := range []string{"GL001", "GL004", "GL005"} {
+		if _, ok := cfg[id]; ok || isRuleEnabled(id, cfg) {
+			t.Errorf("%s enabled by a tag: %v", id, cfg)
+		}
+	}
+	for _, id := range []string{"MD034", "MD045"} {
+		if cfg[id] != true {
+			t.Errorf("%s = %v, want true", id, cfg[id])
+		}
+	}
+	// An alias still enables an opt-in rule.
+	if cfg := resolveRuleConfig(map[string]interface{}{"external-links": true}, nil); !isRuleEnabled("GL004", cfg) {
+		t.Errorf("GL004 not enabled by its alias: %v", cfg)
+	}
+}
+
+func TestLoadConfig_AliasesAndTags(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+config:
+  line-length:
+    line_length: 120
+  headings: false
+overrides:
+  - files: ["docs/**"]
+    config:
+      no-inline-html:
+        allowed_elements: [br]
+`
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md013 := ruleOptions(cfg.Config, "MD013"); md013["line_length"] != 120 {
+		t.Errorf("MD013 options = %v, want line_length 120", md013)
+	}
+	if isRuleEnabled("MD001", cfg.Config) || isRuleEnabled("MD025", cfg.Config) {
+		t.Error("expected the headings tag to disable MD001 and MD025")
+	}
+	fileCfg := effectiveConfigForFile(cfg.Config, cfg.Overrides, "docs/a.md")
+	if md033 := ruleOptions(fileCfg, "MD033"); md033 == nil {
+		t.Errorf("expected MD033 options from the override alias, got %v", fileCfg)
+	}
+}
+
+func TestCLI_ConfigAliasDisablesRule(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Heading\n\n"+strings.Repeat("a", 90)+" extra\n\n<br>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgContent := "config:\n  line-length: false\n  html: false\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfgContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--no-cache", mdFile)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected exit 0 with MD013 and MD033 disabled by alias and tag, got %v:\n%s", err, out)
+	}
+}
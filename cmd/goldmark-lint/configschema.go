@@ -0,0 +1,430 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+// configSchemaID is the JSON Schema dialect of the schemas below.
+const configSchemaID = "http://json-schema.org/draft-07/schema#"
+
+// ruleSeverities are the strings a rule can be set to instead of true.
+var ruleSeverities = []interface{}{"error", "warning", "info"}
+
+// configSchema returns the JSON Schema of a .markdownlint-cli2.* config file
+// that declares the custom rules custom. The rule config maps refer to the
+// "rules" definition, which ruleConfigSchema returns.
+func configSchema(custom []CustomRuleConfig) map[string]interface{} {
+	rulesRef := map[string]interface{}{"$ref": "#/definitions/rules"}
+	return map[string]interface{}{
+		"$schema":     configSchemaID,
+		"title":       "goldmark-lint configuration",
+		"type":        "object",
+		"definitions": map[string]interface{}{"rules": ruleConfigSchema(custom)},
+		"properties": map[string]interface{}{
+			"extends": stringSchema(),
+			"config":  rulesRef,
+			"ignores": stringsSchema(),
+			"overrides": map[string]interface{}{
+				"type": "array",
+				"items": closedObjectSchema(map[string]interface{}{
+					"files":  stringsSchema(),
+					"config": rulesRef,
+				}),
+			},
+			"outputFormatters": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "array"},
+			},
+			"noInlineConfig": booleanSchema(),
+			"globs":          stringsSchema(),
+			"fix":            booleanSchema(),
+			"frontMatter":    stringSchema(),
+			"gitignore":      map[string]interface{}{"anyOf": []interface{}{booleanSchema(), stringSchema()}},
+			"customRules": map[string]interface{}{
+				"type": "array",
+				"items": closedObjectSchema(map[string]interface{}{
+					"id":          stringSchema(),
+					"aliases":     stringsSchema(),
+					"tags":        stringsSchema(),
+					"description": stringSchema(),
+					"command":     stringsSchema(),
+					"timeout":     map[string]interface{}{"type": "number"},
+				}),
+			},
+			"flavor": stringSchema(),
+		},
+		"additionalProperties": false,
+	}
+}
+
+// ruleConfigSchema returns the JSON Schema of a rule config map, which is
+// also the whole of a .markdownlint.* config file. Each rule can be named by
+// ID or alias and set to a boolean, a severity, or its options; tags and
+// custom rules take any object, as their options are not known.
+func ruleConfigSchema(custom []CustomRuleConfig) map[string]interface{} {
+	properties := map[string]interface{}{
+		"default": booleanSchema(),
+	}
+	anyOptions := map[string]interface{}{"type": "object"}
+	for _, e := range rules.All() {
+		schema := ruleValueSchema(e.OptionsSchema())
+		if r, err := e.Build(nil); err == nil {
+			schema["description"] = r.Description()
+		}
+		for _, name := range append([]string{e.ID}, e.Aliases...) {
+			properties[name] = schema
+		}
+		if e.OptIn {
+			// Tags do not name opt-in rules.
+			continue
+		}
+		for _, tag := range e.Tags {
+			properties[tag] = ruleValueSchema(anyOptions)
+		}
+	}
+	for _, def := range custom {
+		schema := ruleValueSchema(anyOptions)
+		if def.Description != "" {
+			schema["description"] = def.Description
+		}
+		for _, name := range append([]string{def.ID}, def.Aliases...) {
+			properties[name] = schema
+		}
+		for _, tag := range def.Tags {
+			properties[tag] = ruleValueSchema(anyOptions)
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// ruleValueSchema returns the schema of the value of a rule config key whose
+// options have the schema options. The options of a rule may also set its
+// severity.
+func ruleValueSchema(options map[string]interface{}) map[string]interface{} {
+	if properties, ok := options["properties"].(map[string]interface{}); ok {
+		properties["severity"] = map[string]interface{}{"type": "string", "enum": ruleSeverities}
+	}
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			booleanSchema(),
+			map[string]interface{}{"type": "string", "enum": ruleSeverities},
+			options,
+		},
+	}
+}
+
+func booleanSchema() map[string]interface{} { return map[string]interface{}{"type": "boolean"} }
+func stringSchema() map[string]interface{}  { return map[string]interface{}{"type": "string"} }
+
+func stringsSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": stringSchema()}
+}
+
+func closedObjectSchema(properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+}
+
+// configProblem is an issue that validating a config file found.
+type configProblem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p configProblem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// validateConfigData checks data, the content of the config file at path,
+// against the schema of its format. custom lists the custom rules its rule
+// names may refer to. Data that does not parse yields no problems, as
+// loadConfig reports the error.
+func validateConfigData(path string, data []byte, custom []CustomRuleConfig) []configProblem {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonc":
+		// JSON is YAML, which gives the nodes their positions.
+		data = stripJSONComments(data)
+	case ".yaml", ".yml":
+	default:
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	var root map[string]interface{}
+	if isSimpleFormatConfig(path) {
+		root = ruleConfigSchema(custom)
+	} else {
+		root = configSchema(custom)
+	}
+	v := &schemaValidator{file: path, root: root}
+	v.validate(doc.Content[0], root, "")
+	return v.problems
+}
+
+// schemaValidator checks YAML nodes against the subset of JSON Schema that
+// configSchema uses: type, enum, properties, additionalProperties, items,
+// anyOf, and references to definitions.
+type schemaValidator struct {
+	file     string
+	root     map[string]interface{}
+	problems []configProblem
+}
+
+func (v *schemaValidator) report(n *yaml.Node, format string, args ...interface{}) {
+	v.problems = append(v.problems, configProblem{File: v.file, Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks n against schema. path names n in messages, as in
+// "config.MD013.line_length"; it is empty for the document.
+func (v *schemaValidator) validate(n *yaml.Node, schema map[string]interface{}, path string) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		defs, _ := v.root["definitions"].(map[string]interface{})
+		schema, _ = defs[strings.TrimPrefix(ref, "#/definitions/")].(map[string]interface{})
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var types []string
+		for _, s := range anyOf {
+			branch := s.(map[string]interface{})
+			typ, _ := branch["type"].(string)
+			if nodeHasType(n, typ) {
+				v.validate(n, branch, path)
+				return
+			}
+			types = append(types, typ)
+		}
+		v.report(n, "%s: expected %s, got %s", label(path), joinTypes(types), nodeType(n))
+		return
+	}
+	if typ, ok := schema["type"].(string); ok && !nodeHasType(n, typ) {
+		v.report(n, "%s: expected %s, got %s", label(path), typ, nodeType(n))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && n.Kind == yaml.ScalarNode {
+		var allowed []string
+		found := false
+		for _, e := range enum {
+			allowed = append(allowed, fmt.Sprintf("%q", e))
+			if fmt.Sprint(e) == n.Value {
+				found = true
+			}
+		}
+		if !found {
+			v.report(n, "%s: invalid value %q; expected one of %s", label(path), n.Value, strings.Join(allowed, ", "))
+		}
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			sub := lookupProperty(properties, key.Value)
+			if sub == nil {
+				switch additional := schema["additionalProperties"].(type) {
+				case bool:
+					if !additional {
+						v.report(key, "%s: unknown key %q", label(path), key.Value)
+					}
+				case map[string]interface{}:
+					v.validate(val, additional, joinPath(path, key.Value))
+				}
+				continue
+			}
+			v.validate(val, sub, joinPath(path, key.Value))
+		}
+	case yaml.SequenceNode:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range n.Content {
+				v.validate(item, items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+// lookupProperty returns the schema of the property key, matching it
+// ignoring case like encoding/json and the rule registry do, or nil if
+// there is none.
+func lookupProperty(properties map[string]interface{}, key string) map[string]interface{} {
+	if s, ok := properties[key].(map[string]interface{}); ok {
+		return s
+	}
+	for name, s := range properties {
+		if strings.EqualFold(name, key) {
+			return s.(map[string]interface{})
+		}
+	}
+	return nil
+}
+
+// nodeType returns the JSON Schema type of n.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.Tag {
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
+
+// nodeHasType reports whether n is of the JSON Schema type typ; an empty
+// typ allows any value.
+func nodeHasType(n *yaml.Node, typ string) bool {
+	got := nodeType(n)
+	return typ == "" || got == typ || typ == "number" && got == "integer"
+}
+
+func joinTypes(types []string) string {
+	if len(types) < 2 {
+		return strings.Join(types, "")
+	}
+	return strings.Join(types[:len(types)-1], ", ") + " or " + types[len(types)-1]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// label returns path for use in a message, naming the document itself when
+// path is empty.
+func label(path string) string {
+	if path == "" {
+		return "config file"
+	}
+	return path
+}
+
+// printConfigProblems writes problems to w as warnings.
+func printConfigProblems(w io.Writer, problems []configProblem) {
+	for _, p := range problems {
+		fmt.Fprintf(w, "Warning: %s\n", p)
+	}
+}
+
+const configHelpText = `Usage: goldmark-lint config validate [file...]
+       goldmark-lint config schema
+
+Subcommands:
+- validate  check config files for unknown keys, wrong option types and
+            invalid values; without arguments, the config file discovered
+            from the current directory is checked
+- schema    print a JSON Schema of .markdownlint-cli2.* config files, for
+            editor completion and validation
+
+Exit codes of validate:
+- 0: The config files are valid
+- 1: Problems were found
+- 2: A config file could not be loaded
+`
+
+// runConfig runs the "config" subcommand with args, the arguments after
+// "config", and returns the exit code.
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, configHelpText)
+		return 2
+	}
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(args[1:], stdout, stderr)
+	case "schema":
+		cwd, _ := os.Getwd()
+		cfg, path, err := discoverConfig("", cwd)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading config %s: %v\n", path, err)
+			return 2
+		}
+		var custom []CustomRuleConfig
+		if cfg != nil {
+			custom = cfg.CustomRules
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(configSchema(custom)); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 2
+		}
+		return 0
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, configHelpText)
+		return 0
+	}
+	fmt.Fprintf(stderr, "Error: unknown config subcommand %q\n\n%s", args[0], configHelpText)
+	return 2
+}
+
+// runConfigValidate checks the config files at paths, or the discovered one
+// when paths is empty, and the files they extend.
+func runConfigValidate(paths []string, stdout, stderr io.Writer) int {
+	if len(paths) == 0 {
+		cwd, _ := os.Getwd()
+		if path := findConfigFile(cwd); path != "" {
+			paths = []string{path}
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "Error: no config file found")
+		return 2
+	}
+	exitCode := 0
+	var problems []configProblem
+	for _, path := range paths {
+		cfg, err := loadConfig(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading config %s: %v\n", path, err)
+			exitCode = 2
+			continue
+		}
+		problems = append(problems, cfg.problems...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	for _, p := range problems {
+		fmt.Fprintln(stdout, p)
+	}
+	if len(problems) > 0 && exitCode == 0 {
+		exitCode = 1
+	}
+	if exitCode == 0 {
+		fmt.Fprintf(stdout, "%d config file(s) valid\n", len(paths))
+	}
+	return exitCode
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema_CoversConfigFile(t *testing.T) {
+	properties, _ := configSchema(nil)["properties"].(map[string]interface{})
+	typ := reflect.TypeOf(ConfigFile{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			continue
+		}
+		if _, ok := properties[name]; !ok {
+			t.Errorf("config schema has no property for %q", name)
+		}
+	}
+}
+
+func TestValidateConfigData_YAML(t *testing.T) {
+	data := `config:
+  MD013:
+    line_lenght: 100
+    code_blocks: "no"
+  MD003:
+    style: atxx
+  md041: false
+  line-length: warning
+  headings: false
+  TEAM001:
+    anything: 1
+  MD999: true
+  MD009:
+    severity: info
+  MD010:
+    severity: fatal
+ignore:
+  - vendor
+overrides:
+  - files: [docs/**]
+    config:
+      MD022:
+        lines_above: [1, "2"]
+`
+	custom := []CustomRuleConfig{{ID: "TEAM001"}}
+	got := validateConfigData(".markdownlint-cli2.yaml", []byte(data), custom)
+	want := []string{
+		`.markdownlint-cli2.yaml:3:5: config.MD013: unknown key "line_lenght"`,
+		`.markdownlint-cli2.yaml:4:18: config.MD013.code_blocks: expected boolean, got string`,
+		`.markdownlint-cli2.yaml:6:12: config.MD003.style: invalid value "atxx"; expected one of "consistent", "atx", "atx_closed", "setext", "setext_with_atx", "setext_with_atx_closed"`,
+		`.markdownlint-cli2.yaml:12:3: config: unknown key "MD999"`,
+		`.markdownlint-cli2.yaml:16:15: config.MD010.severity: invalid value "fatal"; expected one of "error", "warning", "info"`,
+		`.markdownlint-cli2.yaml:17:1: config file: unknown key "ignore"`,
+		`.markdownlint-cli2.yaml:23:26: overrides[0].config.MD022.lines_above[1]: expected integer, got string`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d problems, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("problem %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestValidateConfigData_JSONC(t *testing.T) {
+	data := `{
+  /* a block
+     comment */
+  "MD013": {"line_length": "long"}, // a line comment
+  "MD001": 1
+}`
+	got := validateConfigData(".markdownlint.jsonc", []byte(data), nil)
+	if len(got) != 2 {
+		t.Fatalf("got %v, want two problems", got)
+	}
+	if got[0].Line != 4 || !strings.Contains(got[0].Message, "MD013.line_length: expected integer, got string") {
+		t.Errorf("problem 0 = %v, want line_length on line 4", got[0])
+	}
+	if got[1].Line != 5 || !strings.Contains(got[1].Message, "MD001: expected boolean, string or object, got integer") {
+		t.Errorf("problem 1 = %v, want MD001 on line 5", got[1])
+	}
+}
+
+func TestLoadConfig_Problems(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("config:\n  MD004:\n    style: stars\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte("extends: base.yaml\nconfig:\n  MD013: {line_length: 100}\nfixx: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.problems) != 2 {
+		t.Fatalf("problems = %v, want one from each file", cfg.problems)
+	}
+	if cfg.problems[0].File != cfgPath || !strings.Contains(cfg.problems[0].Message, `unknown key "fixx"`) {
+		t.Errorf("problem 0 = %v, want the unknown fixx key", cfg.problems[0])
+	}
+	if !strings.HasSuffix(cfg.problems[1].File, "base.yaml") || cfg.problems[1].Line != 3 {
+		t.Errorf("problem 1 = %v, want the MD004 style on line 3 of base.yaml", cfg.problems[1])
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, ".markdownlint.yaml")
+	if err := os.WriteFile(valid, []byte("MD013:\n  line_length: 100\nwhitespace: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("config:\n  MD013:\n    line-length: 100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runConfig([]string{"validate", valid}, &stdout, &stderr); code != 0 {
+		t.Errorf("validate of a valid config exited %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	if code := runConfig([]string{"validate", invalid}, &stdout, &stderr); code != 1 {
+		t.Errorf("validate of an invalid config exited %d, want 1", code)
+	}
+	if !strings.Contains(stdout.String(), `invalid.yaml:3:5: config.MD013: unknown key "line-length"`) {
+		t.Errorf("validate output = %q, want the unknown line-length option", stdout.String())
+	}
+	if code := runConfig([]string{"validate", filepath.Join(dir, "missing.yaml")}, &stdout, &stderr); code != 2 {
+		t.Errorf("validate of a missing config exited %d, want 2", code)
+	}
+
+	stdout.Reset()
+	if code := runConfig([]string{"schema"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("schema exited %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{`"$schema"`, `"line_length"`, `"heading-increment"`, `"setext_with_atx"`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("schema output has no %s", want)
+		}
+	}
+}
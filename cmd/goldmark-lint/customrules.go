@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+// CustomRuleConfig declares a rule implemented by an external command, listed
+// under the "customRules" config key. For every document the command receives
+// a customRuleRequest as JSON on stdin and writes a customRuleResponse as JSON
+// to stdout.
+type CustomRuleConfig struct {
+	ID          string   `yaml:"id"          json:"id"`
+	Aliases     []string `yaml:"aliases"     json:"aliases"`
+	Tags        []string `yaml:"tags"        json:"tags"`
+	Description string   `yaml:"description" json:"description"`
+	// Command is the program to run followed by its arguments. A program
+	// given as a relative path is resolved against the directory of the
+	// config file that declares the rule; a bare name is looked up in PATH.
+	Command []string `yaml:"command" json:"command"`
+	// Timeout is the time limit of a run of the command in seconds. Zero
+	// means defaultCustomRuleTimeout.
+	Timeout float64 `yaml:"timeout" json:"timeout"`
+
+	dir string // directory of the declaring config file
+}
+
+// defaultCustomRuleTimeout is the time limit of a custom rule command that
+// does not set one.
+const defaultCustomRuleTimeout = 30 * time.Second
+
+// customRuleIDRE matches valid custom rule IDs and aliases.
+var customRuleIDRE = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateCustomRules checks the customRules entries of the config file at
+// path, uppercases their IDs, and records the config file's directory so that
+// relative commands can be resolved later. inherited are the custom rules
+// that the config file extends or is layered on; a rule declared exactly like
+// one of them, as when a nested config extends the config above it, is the
+// same rule. An ID or alias must not name another rule, registered or custom,
+// and a tag must not name any rule, as config keys that name a rule never
+// stand for a tag.
+func validateCustomRules(defs []CustomRuleConfig, path string, inherited []CustomRuleConfig) error {
+	// names holds the uppercased IDs and aliases of the custom rules.
+	names := make(map[string]bool)
+	for _, def := range inherited {
+		names[strings.ToUpper(def.ID)] = true
+		for _, alias := range def.Aliases {
+			names[strings.ToUpper(alias)] = true
+		}
+	}
+	inUse := func(name string) bool {
+		_, builtin := rules.Lookup(name)
+		return builtin || names[strings.ToUpper(name)]
+	}
+	for i := range defs {
+		def := &defs[i]
+		if !customRuleIDRE.MatchString(def.ID) {
+			return fmt.Errorf("customRules[%d]: invalid id %q", i, def.ID)
+		}
+		def.ID = strings.ToUpper(def.ID)
+		def.dir = filepath.Dir(path)
+		if isDeclared(*def, inherited) {
+			continue
+		}
+		if inUse(def.ID) {
+			return fmt.Errorf("customRules[%d]: id %s is already in use", i, def.ID)
+		}
+		names[def.ID] = true
+		for _, alias := range def.Aliases {
+			if !customRuleIDRE.MatchString(alias) {
+				return fmt.Errorf("customRules[%d]: invalid alias %q", i, alias)
+			}
+			if inUse(alias) {
+				return fmt.Errorf("customRules[%d]: alias %q of %s is already in use", i, alias, def.ID)
+			}
+			names[strings.ToUpper(alias)] = true
+		}
+		if len(def.Command) == 0 || def.Command[0] == "" {
+			return fmt.Errorf("customRules[%d]: %s has no command", i, def.ID)
+		}
+		if def.Timeout < 0 {
+			return fmt.Errorf("customRules[%d]: %s has a negative timeout", i, def.ID)
+		}
+	}
+	// Tags are checked once all the names are known.
+	for i, def := range defs {
+		for _, tag := range def.Tags {
+			if !customRuleIDRE.MatchString(tag) {
+				return fmt.Errorf("customRules[%d]: invalid tag %q", i, tag)
+			}
+			if inUse(tag) {
+				return fmt.Errorf("customRules[%d]: tag %q of %s names a rule", i, tag, def.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// isDeclared reports whether defs holds a declaration equal to def.
+func isDeclared(def CustomRuleConfig, defs []CustomRuleConfig) bool {
+	for _, d := range defs {
+		if reflect.DeepEqual(d, def) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasName reports whether name is the ID or one of the aliases of def,
+// ignoring case.
+func (def CustomRuleConfig) hasName(name string) bool {
+	if strings.EqualFold(def.ID, name) {
+		return true
+	}
+	for _, alias := range def.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// customRuleRequest is the document sent to a custom rule command. Source and
+// Lines have the front matter blanked out, like lint.Document.
+type customRuleRequest struct {
+	Source      string                 `json:"source"`
+	Lines       []string               `json:"lines"`
+	FrontMatter map[string]interface{} `json:"frontMatter,omitempty"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+}
+
+// customRuleResponse is the document a custom rule command writes back.
+type customRuleResponse struct {
+	Violations []customRuleViolation `json:"violations"`
+}
+
+// customRuleViolation is a single violation reported by a custom rule
+// command. Positions are 1-based; zero values get the same defaults as
+// built-in rules, and an empty Message falls back to the rule description.
+type customRuleViolation struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Message   string `json:"message"`
+}
+
+// customRule adapts a CustomRuleConfig to lint.Rule.
+type customRule struct {
+	def     CustomRuleConfig
+	options map[string]interface{}
+}
+
+// newCustomRule creates the rule declared by def with its options taken from
+// the rule's entry in cfg, if that entry is an object.
+func newCustomRule(def CustomRuleConfig, cfg map[string]interface{}) *customRule {
+	return &customRule{def: def, options: ruleOptions(cfg, def.ID)}
+}
+
+func (r *customRule) ID() string        { return r.def.ID }
+func (r *customRule) Aliases() []string { return r.def.Aliases }
+func (r *customRule) Tags() []string    { return r.def.Tags }
+
+func (r *customRule) Description() string {
+	if r.def.Description != "" {
+		return r.def.Description
+	}
+	return "Custom rule " + r.def.ID
+}
+
+// MarshalJSON reports the configured options, so that --list-rules shows them
+// like the options of built-in rules.
+func (r *customRule) MarshalJSON() ([]byte, error) {
+	if r.options == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(r.options)
+}
+
+// timeout returns the time limit of a run of the command.
+func (r *customRule) timeout() time.Duration {
+	if r.def.Timeout <= 0 {
+		return defaultCustomRuleTimeout
+	}
+	return time.Duration(r.def.Timeout * float64(time.Second))
+}
+
+// command returns the command to run, resolving a relative program path
+// against the directory of the declaring config file. The command is killed
+// when ctx is done.
+func (r *customRule) command(ctx context.Context) *exec.Cmd {
+	name := r.def.Command[0]
+	if !filepath.IsAbs(name) && strings.ContainsRune(filepath.ToSlash(name), '/') && r.def.dir != "" {
+		name = filepath.Join(r.def.dir, name)
+	}
+	cmd := exec.CommandContext(ctx, name, r.def.Command[1:]...)
+	// Do not wait for processes the command started, which may still
+	// hold its output open, once it has been killed.
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// Check runs the command on doc. A command that fails, runs out of time or
+// writes invalid output is reported as a single violation on line 1, so that
+// a broken plugin cannot go unnoticed.
+func (r *customRule) Check(doc *lint.Document) []lint.Violation {
+	req, err := json.Marshal(customRuleRequest{
+		Source:      string(doc.Source),
+		Lines:       doc.Lines,
+		FrontMatter: doc.FrontMatter,
+		Options:     r.options,
+	})
+	if err != nil {
+		return []lint.Violation{r.failure(err)}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+	defer cancel()
+	cmd := r.command(ctx)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return []lint.Violation{r.failure(fmt.Errorf("timed out after %v", r.timeout()))}
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return []lint.Violation{r.failure(err)}
+	}
+	var resp customRuleResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return []lint.Violation{r.failure(fmt.Errorf("invalid output: %w", err))}
+	}
+
+	violations := make([]lint.Violation, 0, len(resp.Violations))
+	for _, cv := range resp.Violations {
+		v := lint.Violation{
+			Rule:      r.def.ID,
+			Line:      max(min(cv.Line, len(doc.Lines)), 1),
+			Column:    max(cv.Column, 1),
+			EndLine:   cv.EndLine,
+			EndColumn: cv.EndColumn,
+			Message:   cv.Message,
+		}
+		if v.Message == "" {
+			v.Message = r.Description()
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// failure returns the violation reporting that the command could not be run.
+func (r *customRule) failure(err error) lint.Violation {
+	return lint.Violation{
+		Rule:    r.def.ID,
+		Line:    1,
+		Column:  1,
+		Message: fmt.Sprintf("Custom rule %s failed: %v", r.def.ID, err),
+	}
+}
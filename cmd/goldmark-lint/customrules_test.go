@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// TestCustomRuleHelperProcess is not a real test: it is the custom rule
+// command run by the tests below. It reports every line containing the word
+// given in the "word" option and the line given in the "line" option, or
+// hangs when the "hang" option is true.
+func TestCustomRuleHelperProcess(t *testing.T) {
+	if os.Getenv("GOLDMARK_LINT_TEST_CUSTOM_RULE") != "1" {
+		return
+	}
+	var req customRuleRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(3)
+	}
+	if hang, _ := req.Options["hang"].(bool); hang {
+		time.Sleep(time.Minute)
+	}
+	word, _ := req.Options["word"].(string)
+	var resp customRuleResponse
+	if line, ok := req.Options["line"].(float64); ok {
+		resp.Violations = append(resp.Violations, customRuleViolation{Line: int(line)})
+	}
+	for i, line := range req.Lines {
+		if col := strings.Index(line, word); word != "" && col >= 0 {
+			resp.Violations = append(resp.Violations, customRuleViolation{
+				Line:      i + 1,
+				Column:    col + 1,
+				EndColumn: col + len(word) + 1,
+				Message:   "Found " + word,
+			})
+		}
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+	os.Exit(0)
+}
+
+// helperRule returns a custom rule declaration that runs
+// TestCustomRuleHelperProcess.
+func helperRule(t *testing.T) CustomRuleConfig {
+	t.Helper()
+	t.Setenv("GOLDMARK_LINT_TEST_CUSTOM_RULE", "1")
+	return CustomRuleConfig{
+		ID:      "TEAM001",
+		Aliases: []string{"no-word"},
+		Command: []string{os.Args[0], "-test.run=^TestCustomRuleHelperProcess$"},
+	}
+}
+
+func TestCustomRule_Check(t *testing.T) {
+	cfg := map[string]interface{}{"TEAM001": map[string]interface{}{"word": "TODO"}}
+	linter := newLinterFromConfig(cfg, helperRule(t))
+	v := linter.Lint([]byte("# Title\n\nSome TODO here\n<!-- markdownlint-disable-next-line no-word -->\nTODO\n"))
+	if len(v) != 1 {
+		t.Fatalf("expected 1 violation, got %v", v)
+	}
+	want := lint.Violation{Rule: "TEAM001", Line: 3, Column: 6, EndLine: 3, EndColumn: 10, Message: "Found TODO"}
+	if !reflect.DeepEqual(v[0], want) {
+		t.Errorf("got %+v, want %+v", v[0], want)
+	}
+}
+
+func TestCustomRule_LineClamped(t *testing.T) {
+	tests := []struct {
+		lines []string
+		line  int
+		want  int
+	}{
+		{[]string{"# Title", ""}, 5, 2},
+		{[]string{"# Title", ""}, 0, 1},
+		{nil, 3, 1},
+	}
+	for _, tt := range tests {
+		cfg := map[string]interface{}{"TEAM001": map[string]interface{}{"line": tt.line}}
+		r := newCustomRule(helperRule(t), cfg)
+		v := r.Check(&lint.Document{Lines: tt.lines})
+		if len(v) != 1 || v[0].Line != tt.want {
+			t.Errorf("%d lines, line %d: got %v, want one violation on line %d", len(tt.lines), tt.line, v, tt.want)
+		}
+	}
+}
+
+func TestCustomRule_Disabled(t *testing.T) {
+	cfg := map[string]interface{}{"TEAM001": false}
+	for _, r := range buildRules(cfg, helperRule(t)) {
+		if r.ID() == "TEAM001" {
+			t.Fatal("expected TEAM001 to be disabled")
+		}
+	}
+}
+
+func TestCustomRule_KnownToSuppressions(t *testing.T) {
+	// Comments naming a custom rule are not unknown, even where the config
+	// disables it.
+	cfg := map[string]interface{}{"TEAM001": false, "GL006": true}
+	linter := newLinterFromConfig(cfg, helperRule(t))
+	var got []string
+	for _, v := range linter.Lint([]byte("# Title\n\n<!-- markdownlint-disable-next-line TEAM001 no-word no-such-rule -->\nText\n")) {
+		if v.Rule == "GL006" {
+			got = append(got, v.Message)
+		}
+	}
+	if len(got) != 1 || !strings.Contains(got[0], "Unknown rule in markdownlint-disable-next-line: no-such-rule]") {
+		t.Errorf("expected only no-such-rule to be unknown, got %q", got)
+	}
+}
+
+func TestCustomRule_CommandFailure(t *testing.T) {
+	def := CustomRuleConfig{ID: "TEAM001", Command: []string{filepath.Join(t.TempDir(), "missing")}}
+	v := newCustomRule(def, nil).Check(&lint.Document{Lines: []string{""}})
+	if len(v) != 1 || v[0].Line != 1 || !strings.Contains(v[0].Message, "Custom rule TEAM001 failed") {
+		t.Errorf("expected a failure violation, got %v", v)
+	}
+}
+
+func TestCustomRule_Timeout(t *testing.T) {
+	def := helperRule(t)
+	def.Timeout = 0.2
+	r := newCustomRule(def, map[string]interface{}{"TEAM001": map[string]interface{}{"hang": true}})
+	start := time.Now()
+	v := r.Check(&lint.Document{Lines: []string{""}})
+	if len(v) != 1 || v[0].Line != 1 || !strings.Contains(v[0].Message, "Custom rule TEAM001 failed: timed out after 200ms") {
+		t.Errorf("expected a timeout violation, got %v", v)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Check() took %v, expected the command to be killed", elapsed)
+	}
+	if got := (&customRule{}).timeout(); got != defaultCustomRuleTimeout {
+		t.Errorf("default timeout = %v, want %v", got, defaultCustomRuleTimeout)
+	}
+}
+
+func TestValidateCustomRules(t *testing.T) {
+	tests := []struct {
+		name string
+		def  CustomRuleConfig
+		want string
+	}{
+		{"missing id", CustomRuleConfig{Command: []string{"x"}}, "invalid id"},
+		{"builtin id", CustomRuleConfig{ID: "md001", Command: []string{"x"}}, "already in use"},
+		{"invalid alias", CustomRuleConfig{ID: "TEAM001", Aliases: []string{"no space"}, Command: []string{"x"}}, "invalid alias"},
+		{"missing command", CustomRuleConfig{ID: "TEAM001"}, "no command"},
+		{"negative timeout", CustomRuleConfig{ID: "TEAM001", Command: []string{"x"}, Timeout: -1}, "negative timeout"},
+		{"builtin alias", CustomRuleConfig{ID: "TEAM001", Aliases: []string{"line-length"}, Command: []string{"x"}}, `alias "line-length" of TEAM001 is already in use`},
+		{"builtin id as alias", CustomRuleConfig{ID: "TEAM001", Aliases: []string{"md013"}, Command: []string{"x"}}, `alias "md013" of TEAM001 is already in use`},
+		{"inherited alias", CustomRuleConfig{ID: "TEAM001", Aliases: []string{"No-Todo"}, Command: []string{"x"}}, `alias "No-Todo" of TEAM001 is already in use`},
+		{"inherited id", CustomRuleConfig{ID: "team000", Command: []string{"x"}}, "id TEAM000 is already in use"},
+		{"tag naming a rule", CustomRuleConfig{ID: "TEAM001", Tags: []string{"no-todo"}, Command: []string{"x"}}, `tag "no-todo" of TEAM001 names a rule`},
+	}
+	inherited := []CustomRuleConfig{{ID: "TEAM000", Aliases: []string{"no-todo"}, Command: []string{"x"}, dir: "/"}}
+	for _, tt := range tests {
+		err := validateCustomRules([]CustomRuleConfig{tt.def}, "/cfg/.markdownlint-cli2.yaml", inherited)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	defs := []CustomRuleConfig{{ID: "team001", Command: []string{"x"}}, {ID: "TEAM001", Command: []string{"y"}}}
+	if err := validateCustomRules(defs, "/cfg/.markdownlint-cli2.yaml", nil); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("expected duplicate id error, got %v", err)
+	}
+	if defs[0].ID != "TEAM001" || defs[0].dir != "/cfg" {
+		t.Errorf("expected uppercased id and config dir, got %+v", defs[0])
+	}
+	defs = []CustomRuleConfig{{ID: "TEAM001", Aliases: []string{"no-todo"}, Command: []string{"x"}}, {ID: "TEAM002", Aliases: []string{"NO-TODO"}, Command: []string{"y"}}}
+	if err := validateCustomRules(defs, "/cfg/.markdownlint-cli2.yaml", nil); err == nil || !strings.Contains(err.Error(), `alias "NO-TODO" of TEAM002 is already in use`) {
+		t.Errorf("expected duplicate alias error, got %v", err)
+	}
+	// A rule declared exactly like an inherited one is the same rule.
+	defs = []CustomRuleConfig{{ID: "team000", Aliases: []string{"no-todo"}, Command: []string{"x"}}}
+	if err := validateCustomRules(defs, "/.markdownlint-cli2.yaml", inherited); err != nil {
+		t.Errorf("expected the inherited declaration to be accepted, got %v", err)
+	}
+}
+
+func TestCustomRule_CommandPath(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"./rules/check.sh", filepath.Join("/cfg", "rules", "check.sh")},
+		{"rules/check.sh", filepath.Join("/cfg", "rules", "check.sh")},
+		{"/usr/local/bin/check", "/usr/local/bin/check"},
+	}
+	for _, tt := range tests {
+		r := &customRule{def: CustomRuleConfig{ID: "TEAM001", Command: []string{tt.command}, dir: "/cfg"}}
+		if got := r.command(context.Background()).Path; got != tt.want {
+			t.Errorf("command %q: path = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestCLI_CustomRules(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"violations\": [{\"line\": 1, \"message\": \"Team rule\"}]}'\n"
+	if err := os.MkdirAll(filepath.Join(dir, "rules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "rules", "team.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "customRules:\n  - id: TEAM001\n    description: Team rule\n    command: [./rules/team.sh]\n"
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mdFile := filepath.Join(dir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run from another directory to check that the command is resolved
+	// relative to the config file.
+	cmd := exec.Command(bin, "--config", cfgPath, mdFile)
+	cmd.Dir = t.TempDir()
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1 from the custom rule violation, output:\n%s", out)
+	}
+	if !strings.Contains(string(out), "TEAM001") || !strings.Contains(string(out), "Team rule") {
+		t.Errorf("expected TEAM001 violation in output:\n%s", out)
+	}
+}
@@ -20,6 +20,7 @@ const (
 	colorRed    = "\033[31m"
 	colorGreen  = "\033[32m"
 	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
 	colorCyan   = "\033[36m"
 )
 
@@ -55,7 +56,7 @@ func ruleInfoURL(ruleID string) string {
 // formatDefault writes violations in the default text format to w.
 // When w is an interactive terminal and NO_COLOR is not set, the output is
 // colored: the file path is bold, the position is cyan, and the rule ID is
-// red (errors) or yellow (warnings).
+// red (errors), yellow (warnings) or blue (info).
 func formatDefault(violations []fileViolation, w io.Writer) {
 	formatDefaultWithColor(violations, w, isColorEnabled(w))
 }
@@ -67,8 +68,11 @@ func formatDefaultWithColor(violations []fileViolation, w io.Writer, color bool)
 		for _, v := range fv.Violations {
 			if color {
 				ruleColor := colorRed
-				if v.Severity == "warning" {
+				switch v.Severity {
+				case "warning":
 					ruleColor = colorYellow
+				case "info":
+					ruleColor = colorBlue
 				}
 				_, _ = fmt.Fprintf(w, "%s%s%s:%s%d:%d%s %s%s%s %s\n",
 					colorBold, fv.File, colorReset,
@@ -84,15 +88,24 @@ func formatDefaultWithColor(violations []fileViolation, w io.Writer, color bool)
 
 // jsonViolation is the JSON output structure for a single violation.
 type jsonViolation struct {
-	FileName        string   `json:"fileName"`
-	LineNumber      int      `json:"lineNumber"`
-	ColumnNumber    int      `json:"columnNumber"`
-	RuleNames       []string `json:"ruleNames"`
-	RuleDescription string   `json:"ruleDescription"`
-	RuleInformation string   `json:"ruleInformation"`
-	ErrorDetail     *string  `json:"errorDetail"`
-	ErrorContext    *string  `json:"errorContext"`
-	ErrorRange      *[2]int  `json:"errorRange"`
+	FileName        string     `json:"fileName"`
+	LineNumber      int        `json:"lineNumber"`
+	ColumnNumber    int        `json:"columnNumber"`
+	RuleNames       []string   `json:"ruleNames"`
+	RuleDescription string     `json:"ruleDescription"`
+	RuleInformation string     `json:"ruleInformation"`
+	ErrorDetail     *string    `json:"errorDetail"`
+	ErrorContext    *string    `json:"errorContext"`
+	ErrorRange      *[2]int    `json:"errorRange"`
+	Fix             []jsonEdit `json:"fix,omitempty"`
+}
+
+// jsonEdit is the JSON output structure for a single fix edit. Offsets are
+// byte offsets into the file.
+type jsonEdit struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
 }
 
 // formatJSON writes violations as a JSON array to w.
@@ -107,6 +120,8 @@ func formatJSON(violations []fileViolation, w io.Writer) {
 				RuleNames:       []string{v.Rule},
 				RuleDescription: v.Message,
 				RuleInformation: ruleInfoURL(v.Rule),
+				ErrorRange:      errorRange(v),
+				Fix:             jsonEdits(v.Fix),
 			})
 		}
 	}
@@ -115,6 +130,27 @@ func formatJSON(violations []fileViolation, w io.Writer) {
 	_ = enc.Encode(results)
 }
 
+// errorRange returns the markdownlint-style [column, length] pair for a
+// violation that spans part of a single line, or nil otherwise.
+func errorRange(v lint.Violation) *[2]int {
+	if v.EndLine != v.Line || v.EndColumn <= v.Column {
+		return nil
+	}
+	return &[2]int{v.Column, v.EndColumn - v.Column}
+}
+
+// jsonEdits converts fix edits to their JSON output structure.
+func jsonEdits(edits []lint.Edit) []jsonEdit {
+	if len(edits) == 0 {
+		return nil
+	}
+	out := make([]jsonEdit, len(edits))
+	for i, e := range edits {
+		out[i] = jsonEdit{Start: e.Start, End: e.End, NewText: e.NewText}
+	}
+	return out
+}
+
 // xmlTestSuites is the root element for JUnit XML output.
 type xmlTestSuites struct {
 	XMLName xml.Name   `xml:"testsuites"`
@@ -227,9 +263,9 @@ type sarifDriver struct {
 }
 
 type sarifRule struct {
-	ID               string      `json:"id"`
-	ShortDescription sarifText   `json:"shortDescription"`
-	HelpUri          string      `json:"helpUri"`
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+	HelpUri          string    `json:"helpUri"`
 }
 
 type sarifText struct {
@@ -241,6 +277,29 @@ type sarifResult struct {
 	Level     string          `json:"level"`
 	Message   sarifText       `json:"message"`
 	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifText             `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifByteRegion `json:"deletedRegion"`
+	InsertedContent *sarifText      `json:"insertedContent,omitempty"`
+}
+
+// sarifByteRegion is a region given by byte offsets; unlike sarifRegion its
+// zero values are meaningful and must not be omitted.
+type sarifByteRegion struct {
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
 }
 
 type sarifLocation struct {
@@ -260,16 +319,42 @@ type sarifArtifactLocation struct {
 type sarifRegion struct {
 	StartLine   int `json:"startLine"`
 	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
 }
 
 // sarifLevel maps a violation severity string to a SARIF level.
 func sarifLevel(severity string) string {
-	if severity == "warning" {
+	switch severity {
+	case "warning":
 		return "warning"
+	case "info":
+		return "note"
 	}
 	return "error"
 }
 
+// sarifFixes converts the fix edits of v to SARIF fix objects.
+func sarifFixes(v lint.Violation, artifact sarifArtifactLocation) []sarifFix {
+	if len(v.Fix) == 0 {
+		return nil
+	}
+	replacements := make([]sarifReplacement, len(v.Fix))
+	for i, e := range v.Fix {
+		replacements[i].DeletedRegion = sarifByteRegion{ByteOffset: e.Start, ByteLength: e.End - e.Start}
+		if e.NewText != "" {
+			replacements[i].InsertedContent = &sarifText{Text: e.NewText}
+		}
+	}
+	return []sarifFix{{
+		Description: sarifText{Text: "Fix " + v.Rule},
+		ArtifactChanges: []sarifArtifactChange{{
+			ArtifactLocation: artifact,
+			Replacements:     replacements,
+		}},
+	}}
+}
+
 // formatSARIF writes violations in SARIF 2.1.0 format to w.
 func formatSARIF(violations []fileViolation, w io.Writer) {
 	// Collect unique rules in order of first appearance.
@@ -296,6 +381,8 @@ func formatSARIF(violations []fileViolation, w io.Writer) {
 					Region: sarifRegion{
 						StartLine:   v.Line,
 						StartColumn: v.Column,
+						EndLine:     v.EndLine,
+						EndColumn:   v.EndColumn,
 					},
 				},
 			}
@@ -304,6 +391,7 @@ func formatSARIF(violations []fileViolation, w io.Writer) {
 				Level:     sarifLevel(v.Severity),
 				Message:   sarifText{Text: v.Message},
 				Locations: []sarifLocation{loc},
+				Fixes:     sarifFixes(v, loc.PhysicalLocation.ArtifactLocation),
 			})
 		}
 	}
@@ -338,21 +426,37 @@ func formatSARIF(violations []fileViolation, w io.Writer) {
 }
 
 // formatGitHubActions writes violations as GitHub Actions workflow commands to w.
-// Errors use ::error and warnings use ::warning so that GitHub Actions displays
+// Errors use ::error, warnings ::warning and info ::notice so that GitHub Actions displays
 // them as native annotations in the PR diff view.
 func formatGitHubActions(violations []fileViolation, w io.Writer) {
 	for _, fv := range violations {
 		for _, v := range fv.Violations {
 			level := "error"
-			if v.Severity == "warning" {
+			switch v.Severity {
+			case "warning":
 				level = "warning"
+			case "info":
+				level = "notice"
 			}
-			_, _ = fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d::%s %s\n",
-				level, fv.File, v.Line, v.Column, v.Rule, v.Message)
+			_, _ = fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d%s::%s %s\n",
+				level, fv.File, v.Line, v.Column, githubEndPosition(v), v.Rule, v.Message)
 		}
 	}
 }
 
+// githubEndPosition returns the endLine/endColumn properties of a GitHub
+// Actions annotation for v. GitHub only honours endColumn for single-line
+// annotations and treats it as inclusive.
+func githubEndPosition(v lint.Violation) string {
+	switch {
+	case v.EndLine > v.Line:
+		return fmt.Sprintf(",endLine=%d", v.EndLine)
+	case v.EndLine == v.Line && v.EndColumn > v.Column:
+		return fmt.Sprintf(",endLine=%d,endColumn=%d", v.EndLine, v.EndColumn-1)
+	}
+	return ""
+}
+
 // formatSummary writes a count-per-rule summary to w.
 // Rules are sorted by count descending, then by rule ID ascending for ties.
 func formatSummary(violations []fileViolation, w io.Writer) {
@@ -442,7 +546,7 @@ func parseOutputFormatters(raw []interface{}) []outputFormatterSpec {
 
 // diffOp is a single element of a line-level diff: a context, deleted, or added line.
 type diffOp struct {
-	op   byte   // ' ' context, '-' deleted, '+' added
+	op   byte // ' ' context, '-' deleted, '+' added
 	text string
 }
 
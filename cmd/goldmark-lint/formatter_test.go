@@ -152,6 +152,41 @@ func TestFormatJSON_ValidJSON(t *testing.T) {
 	}
 }
 
+// makeFixableViolations returns a violation carrying fix edits.
+func makeFixableViolations() []fileViolation {
+	return []fileViolation{
+		{
+			File: "test.md",
+			Violations: []lint.Violation{
+				{Rule: "MD009", Line: 1, Column: 5, Message: "Trailing spaces", Fix: []lint.Edit{{Start: 4, End: 7}}},
+				{Rule: "MD047", Line: 1, Column: 8, Message: "Files should end with a single newline character", Fix: []lint.Edit{{Start: 7, End: 7, NewText: "\n"}}},
+			},
+		},
+	}
+}
+
+func TestFormatJSON_Fix(t *testing.T) {
+	var buf bytes.Buffer
+	formatJSON(makeFixableViolations(), &buf)
+
+	var results []jsonViolation
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("formatJSON produced invalid JSON: %v", err)
+	}
+	if len(results[0].Fix) != 1 || results[0].Fix[0] != (jsonEdit{Start: 4, End: 7}) {
+		t.Errorf("fix = %+v, want [{4 7 }]", results[0].Fix)
+	}
+	if len(results[1].Fix) != 1 || results[1].Fix[0].NewText != "\n" {
+		t.Errorf("fix = %+v, want an inserted newline", results[1].Fix)
+	}
+
+	buf.Reset()
+	formatJSON(makeViolations(), &buf)
+	if strings.Contains(buf.String(), `"fix"`) {
+		t.Errorf("expected no fix key for unfixable violations, got:\n%s", buf.String())
+	}
+}
+
 func TestFormatJSON_Empty(t *testing.T) {
 	var buf bytes.Buffer
 	formatJSON(nil, &buf)
@@ -283,6 +318,36 @@ func TestFormatSARIF_ValidJSON(t *testing.T) {
 	}
 }
 
+func TestFormatSARIF_Fixes(t *testing.T) {
+	var buf bytes.Buffer
+	formatSARIF(makeFixableViolations(), &buf)
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("formatSARIF produced invalid JSON: %v", err)
+	}
+	results := log.Runs[0].Results
+	if len(results[0].Fixes) != 1 {
+		t.Fatalf("expected 1 fix, got %+v", results[0].Fixes)
+	}
+	change := results[0].Fixes[0].ArtifactChanges[0]
+	if change.ArtifactLocation.URI != "test.md" {
+		t.Errorf("uri = %q, want %q", change.ArtifactLocation.URI, "test.md")
+	}
+	r := change.Replacements[0]
+	if r.DeletedRegion != (sarifByteRegion{ByteOffset: 4, ByteLength: 3}) || r.InsertedContent != nil {
+		t.Errorf("unexpected replacement: %+v", r)
+	}
+	r = results[1].Fixes[0].ArtifactChanges[0].Replacements[0]
+	if r.DeletedRegion != (sarifByteRegion{ByteOffset: 7}) || r.InsertedContent == nil || r.InsertedContent.Text != "\n" {
+		t.Errorf("unexpected replacement: %+v", r)
+	}
+	// The zero byte length of an insertion must still be emitted.
+	if !strings.Contains(buf.String(), `"byteLength": 0`) {
+		t.Errorf("expected byteLength 0 in output:\n%s", buf.String())
+	}
+}
+
 func TestFormatSARIF_Empty(t *testing.T) {
 	var buf bytes.Buffer
 	formatSARIF(nil, &buf)
@@ -631,6 +696,76 @@ func TestFormatGitHubActions_Output(t *testing.T) {
 	}
 }
 
+// makeRangedViolations returns violations with end positions: a single-line
+// range and one spanning two lines.
+func makeRangedViolations() []fileViolation {
+	return []fileViolation{
+		{
+			File: "test.md",
+			Violations: []lint.Violation{
+				{Rule: "MD044", Line: 2, Column: 5, EndLine: 2, EndColumn: 15, Message: "Proper names"},
+				{Rule: "MD046", Line: 4, Column: 5, EndLine: 6, EndColumn: 9, Message: "Code block style"},
+			},
+		},
+	}
+}
+
+func TestFormatGitHubActions_EndPosition(t *testing.T) {
+	var buf bytes.Buffer
+	formatGitHubActions(makeRangedViolations(), &buf)
+	got := buf.String()
+	if !strings.Contains(got, "::error file=test.md,line=2,col=5,endLine=2,endColumn=14::MD044") {
+		t.Errorf("expected single-line range with inclusive endColumn, got: %s", got)
+	}
+	if !strings.Contains(got, "::error file=test.md,line=4,col=5,endLine=6::MD046") {
+		t.Errorf("expected multi-line range without endColumn, got: %s", got)
+	}
+}
+
+func TestFormatJSON_ErrorRange(t *testing.T) {
+	var buf bytes.Buffer
+	formatJSON(makeRangedViolations(), &buf)
+	var results []jsonViolation
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("formatJSON produced invalid JSON: %v", err)
+	}
+	if results[0].ErrorRange == nil || *results[0].ErrorRange != [2]int{5, 10} {
+		t.Errorf("errorRange = %v, want [5 10]", results[0].ErrorRange)
+	}
+	if results[1].ErrorRange != nil {
+		t.Errorf("errorRange = %v, want null for a multi-line range", *results[1].ErrorRange)
+	}
+}
+
+func TestFormatSARIF_EndPosition(t *testing.T) {
+	var buf bytes.Buffer
+	formatSARIF(makeRangedViolations(), &buf)
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("formatSARIF produced invalid JSON: %v", err)
+	}
+	region := log.Runs[0].Results[1].Locations[0].PhysicalLocation.Region
+	if region != (sarifRegion{StartLine: 4, StartColumn: 5, EndLine: 6, EndColumn: 9}) {
+		t.Errorf("region = %+v", region)
+	}
+}
+
+func TestSeverityLevels(t *testing.T) {
+	violations := []fileViolation{{File: "test.md", Violations: []lint.Violation{
+		{Rule: "MD013", Line: 5, Column: 82, Message: "Line length", Severity: "info"},
+	}}}
+	var buf bytes.Buffer
+	formatGitHubActions(violations, &buf)
+	if want := "::notice file=test.md,line=5,col=82::MD013"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got: %s", want, buf.String())
+	}
+	for severity, want := range map[string]string{"error": "error", "warning": "warning", "info": "note", "": "error"} {
+		if got := sarifLevel(severity); got != want {
+			t.Errorf("sarifLevel(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
 func TestFormatGitHubActions_Empty(t *testing.T) {
 	var buf bytes.Buffer
 	formatGitHubActions(nil, &buf)
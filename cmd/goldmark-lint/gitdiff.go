@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// gitChanges records which lines of the files in a git repository were added
+// or modified since a base revision, as used by --diff-base.
+type gitChanges struct {
+	root    string              // repository root, with symlinks resolved
+	tracked map[string]bool     // slash-separated paths relative to root
+	ranges  map[string][][2]int // changed line ranges (inclusive) per path
+}
+
+// gitHunkRE matches the new-file range of a unified diff hunk header.
+var gitHunkRE = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// loadGitChanges runs the git binary in dir to find the lines changed between
+// the merge base of base and HEAD and the working tree, so that uncommitted
+// changes count as well.
+func loadGitChanges(dir, base string) (*gitChanges, error) {
+	out, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(string(out))
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	// Resolve base first so that git never takes it for an option.
+	out, err = runGit(root, "rev-parse", "--verify", "--quiet", "--end-of-options", base+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("not a commit")
+	}
+	commit := strings.TrimSpace(string(out))
+
+	c := &gitChanges{root: root, tracked: make(map[string]bool), ranges: make(map[string][][2]int)}
+	out, err = runGit(root, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			c.tracked[path] = true
+		}
+	}
+
+	out, err = runGit(root, "diff", "--merge-base", "-U0", "--no-color", "--no-ext-diff", "--no-renames",
+		"--src-prefix=a/", "--dst-prefix=b/", commit, "--")
+	if err != nil {
+		return nil, err
+	}
+	c.parseDiff(out)
+	return c, nil
+}
+
+// runGit runs git with args in dir and returns its standard output.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// parseDiff records the added line ranges of a "git diff -U0" output.
+func (c *gitChanges) parseDiff(diff []byte) {
+	var path string
+	for _, line := range strings.Split(string(diff), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			path = gitDiffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			m := gitHunkRE.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			// A count of 0 means the hunk only removes lines.
+			if count > 0 {
+				c.ranges[path] = append(c.ranges[path], [2]int{start, start + count - 1})
+			}
+		}
+	}
+}
+
+// gitDiffPath returns the path of a "+++" diff header value such as
+// "b/docs/a.md", or "" for deleted files.
+func gitDiffPath(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			s = unquoted
+		}
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, "b/")
+}
+
+// relPath returns file relative to the repository root in slash form, or
+// ok=false when file lies outside the repository.
+func (c *gitChanges) relPath(file string) (string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(c.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// filter returns the violations of file that lie on lines changed since the
+// base revision. Every line of a file that git does not track counts as
+// changed; a violation spanning several lines is kept when any of them
+// changed.
+func (c *gitChanges) filter(file string, violations []lint.Violation) []lint.Violation {
+	rel, ok := c.relPath(file)
+	if !ok || !c.tracked[rel] {
+		return violations
+	}
+	ranges := c.ranges[rel]
+	var kept []lint.Violation
+	for _, v := range violations {
+		end := max(v.EndLine, v.Line)
+		for _, r := range ranges {
+			if v.Line <= r[1] && end >= r[0] {
+				kept = append(kept, v)
+				break
+			}
+		}
+	}
+	return kept
+}
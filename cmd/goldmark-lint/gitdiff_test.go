@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+func TestGitChanges_ParseDiff(t *testing.T) {
+	diff := `diff --git a/docs/a.md b/docs/a.md
+index 1111111..2222222 100644
+--- a/docs/a.md
++++ b/docs/a.md
+@@ -3 +3 @@ Title
+-old
++new
+@@ -10,2 +9,0 @@
+-gone
+-gone
+@@ -20,0 +20,3 @@
++added
++added
++added
+diff --git a/old.md b/old.md
+deleted file mode 100644
+--- a/old.md
++++ /dev/null
+@@ -1 +0,0 @@
+-x
+diff --git "a/sp\303\244ce.md" "b/sp\303\244ce.md"
+--- "a/sp\303\244ce.md"
++++ "b/sp\303\244ce.md"
+@@ -1 +1,2 @@
++y
+`
+	c := &gitChanges{ranges: make(map[string][][2]int)}
+	c.parseDiff([]byte(diff))
+	want := map[string][][2]int{
+		"docs/a.md": {{3, 3}, {20, 22}},
+		"späce.md":  {{1, 2}},
+	}
+	if !reflect.DeepEqual(c.ranges, want) {
+		t.Errorf("ranges = %v, want %v", c.ranges, want)
+	}
+}
+
+func TestGitChanges_Filter(t *testing.T) {
+	root := t.TempDir()
+	c := &gitChanges{
+		root:    root,
+		tracked: map[string]bool{"a.md": true, "b.md": true},
+		ranges:  map[string][][2]int{"a.md": {{3, 4}}},
+	}
+	violations := []lint.Violation{
+		{Rule: "MD001", Line: 1, EndLine: 1},
+		{Rule: "MD002", Line: 3, EndLine: 3},
+		{Rule: "MD003", Line: 2, EndLine: 3},
+		{Rule: "MD004", Line: 5, EndLine: 5},
+	}
+	rules := func(vs []lint.Violation) []string {
+		var ids []string
+		for _, v := range vs {
+			ids = append(ids, v.Rule)
+		}
+		return ids
+	}
+	if got := rules(c.filter(filepath.Join(root, "a.md"), violations)); !reflect.DeepEqual(got, []string{"MD002", "MD003"}) {
+		t.Errorf("changed file: got %v", got)
+	}
+	if got := c.filter(filepath.Join(root, "b.md"), violations); len(got) != 0 {
+		t.Errorf("unchanged file: expected no violations, got %v", got)
+	}
+	if got := c.filter(filepath.Join(root, "new.md"), violations); len(got) != len(violations) {
+		t.Errorf("untracked file: expected all violations, got %v", got)
+	}
+}
+
+func TestCLI_DiffBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("old.md", "# Old\n\nTrailing   \n")
+	write("edited.md", "# Edited\n\nTrailing   \n\nText\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+
+	write("edited.md", "# Edited\n\nTrailing   \n\nText with a tab\there\n")
+	write("new.md", "# New\n\nTrailing   \n")
+
+	cmd := exec.Command(bin, "--no-cache", "--diff-base", "base", "old.md", "edited.md", "new.md")
+	cmd.Dir = dir
+	out, _ := cmd.CombinedOutput()
+	got := string(out)
+	for _, want := range []string{"edited.md:5:", "new.md:3:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"old.md", "edited.md:3:"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected no violation matching %q, got:\n%s", unwanted, got)
+		}
+	}
+
+	cmd = exec.Command(bin, "--no-cache", "--diff-base", "no-such-ref", "old.md")
+	cmd.Dir = dir
+	if err := cmd.Run(); err == nil {
+		t.Error("expected an error for an unknown ref")
+	} else if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Errorf("expected exit code 2 for an unknown ref, got %v", err)
+	}
+
+	cmd = exec.Command(bin, "--no-cache", "--diff-base", "--output=leak.txt", "old.md")
+	cmd.Dir = dir
+	if err := cmd.Run(); err == nil {
+		t.Error("expected an error for an option given as the base")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "leak.txt")); err == nil {
+		t.Error("expected the base not to be passed to git as an option")
+	}
+}
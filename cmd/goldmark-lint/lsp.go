@@ -0,0 +1,681 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// JSON-RPC error codes used by the language server.
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+// LSP DiagnosticSeverity values.
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+)
+
+// LSP TextDocumentSyncKind.Full: clients send the whole document on every change.
+const lspSyncFull = 1
+
+// lspMessage is a JSON-RPC 2.0 request, response or notification.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspTextDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type lspDidOpenParams struct {
+	TextDocument lspTextDocumentItem `json:"textDocument"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument   lspTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type lspTextDocumentParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspCodeDescription struct {
+	Href string `json:"href"`
+}
+
+type lspDiagnostic struct {
+	Range           lspRange            `json:"range"`
+	Severity        int                 `json:"severity"`
+	Code            string              `json:"code"`
+	CodeDescription *lspCodeDescription `json:"codeDescription,omitempty"`
+	Source          string              `json:"source"`
+	Message         string              `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Range        lspRange                  `json:"range"`
+	Context      struct {
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+		Only        []string        `json:"only,omitempty"`
+	} `json:"context"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title       string            `json:"title"`
+	Kind        string            `json:"kind"`
+	Diagnostics []lspDiagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool              `json:"isPreferred,omitempty"`
+	Edit        *lspWorkspaceEdit `json:"edit,omitempty"`
+}
+
+// lspServer is a minimal Language Server Protocol server that publishes
+// goldmark-lint violations as diagnostics and offers auto-fixes as code
+// actions. Documents are synchronised in full on every change.
+type lspServer struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]string // URI → current document text
+	shutdown bool
+	// workspaces holds the config of the documents below each workspace
+	// root, by the absolute path of the root.
+	workspaces map[string]*lspWorkspace
+}
+
+// lspWorkspace is the config of the documents below a workspace root. It is
+// kept until one of its config files changes, so that linting a document on
+// every change does not read them again.
+type lspWorkspace struct {
+	setup *lintSetup
+	// configFile is the config file of the root, "" if none; stamps holds
+	// the stamps of it and the files it extends.
+	configFile string
+	stamps     map[string]fileStamp
+	// linters holds the linters built for documents with settings of their
+	// own, by the JSON encoding of the settings.
+	linters map[string]*lint.Linter
+}
+
+// loadLSPWorkspace loads the config of the documents below root. A config
+// file that cannot be loaded is reported and left out.
+func loadLSPWorkspace(root string) *lspWorkspace {
+	w := &lspWorkspace{
+		configFile: findConfigFile(root),
+		stamps:     make(map[string]fileStamp),
+		linters:    make(map[string]*lint.Linter),
+	}
+	var cfg *ConfigFile
+	sources := []string{w.configFile}
+	if w.configFile != "" {
+		loaded, err := loadConfig(w.configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", w.configFile, err)
+		} else {
+			cfg = loaded
+			sources = cfg.sources
+		}
+	}
+	for _, src := range sources {
+		if src != "" {
+			w.stamps[src], _ = stampFile(src)
+		}
+	}
+	setup, err := newLintSetup(cfg, root, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", w.configFile, err)
+		setup, _ = newLintSetup(nil, root, true)
+	}
+	w.setup = setup
+	return w
+}
+
+// changed reports whether another config file was discovered for the root
+// of w or one of its config files changed, including those of the
+// directories below the root.
+func (w *lspWorkspace) changed(root string) bool {
+	if findConfigFile(root) != w.configFile || w.setup.dirs.changed() {
+		return true
+	}
+	for path, old := range w.stamps {
+		if st, _ := stampFile(path); st != old {
+			return true
+		}
+	}
+	return false
+}
+
+// runLSP serves the Language Server Protocol on in/out until the client sends
+// "exit". It returns the process exit code: 0 if "shutdown" was received
+// before "exit", 1 otherwise.
+func runLSP(in io.Reader, out io.Writer) int {
+	s := &lspServer{
+		in:         bufio.NewReader(in),
+		out:        out,
+		docs:       make(map[string]string),
+		workspaces: make(map[string]*lspWorkspace),
+	}
+	for {
+		body, err := s.readMessage()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "Error reading LSP message: %v\n", err)
+			}
+			return 1
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.replyError(nil, lspParseError, err.Error())
+			continue
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		s.handle(&msg)
+	}
+}
+
+// readMessage reads a single base-protocol message and returns its body.
+func (s *lspServer) readMessage() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage frames msg with a Content-Length header and writes it to out.
+func (s *lspServer) writeMessage(msg lspMessage) {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding LSP message: %v\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func (s *lspServer) reply(id *json.RawMessage, result interface{}) {
+	if result == nil {
+		// A null result must still be sent explicitly.
+		result = json.RawMessage("null")
+	}
+	s.writeMessage(lspMessage{ID: id, Result: result})
+}
+
+func (s *lspServer) replyError(id *json.RawMessage, code int, message string) {
+	if id == nil {
+		id = new(json.RawMessage)
+		*id = json.RawMessage("null")
+	}
+	s.writeMessage(lspMessage{ID: id, Error: &lspError{Code: code, Message: message}})
+}
+
+func (s *lspServer) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding LSP notification: %v\n", err)
+		return
+	}
+	s.writeMessage(lspMessage{Method: method, Params: data})
+}
+
+// handle dispatches a single request or notification. Requests (messages with
+// an ID) always receive a response; unknown notifications are ignored.
+func (s *lspServer) handle(msg *lspMessage) {
+	switch msg.Method {
+	case "initialize":
+		s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    lspSyncFull,
+					"save":      true,
+				},
+				"codeActionProvider": map[string]interface{}{
+					"codeActionKinds": []string{"quickfix", "source.fixAll"},
+				},
+			},
+			"serverInfo": map[string]string{
+				"name":    "goldmark-lint",
+				"version": version,
+			},
+		})
+	case "shutdown":
+		s.shutdown = true
+		s.reply(msg.ID, nil)
+	case "textDocument/didOpen":
+		var p lspDidOpenParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return
+		}
+		s.docs[p.TextDocument.URI] = p.TextDocument.Text
+		s.publishDiagnostics(p.TextDocument.URI)
+	case "textDocument/didChange":
+		var p lspDidChangeParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil || len(p.ContentChanges) == 0 {
+			return
+		}
+		// With full sync the last change carries the complete new text.
+		s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+		s.publishDiagnostics(p.TextDocument.URI)
+	case "textDocument/didSave":
+		var p lspTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return
+		}
+		// Saving a config file changes how every open document lints.
+		if s.dropChangedWorkspaces() {
+			s.publishAllDiagnostics()
+		} else if _, ok := s.docs[p.TextDocument.URI]; ok {
+			s.publishDiagnostics(p.TextDocument.URI)
+		}
+	case "workspace/didChangeWatchedFiles":
+		if s.dropChangedWorkspaces() {
+			s.publishAllDiagnostics()
+		}
+	case "textDocument/didClose":
+		var p lspTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return
+		}
+		delete(s.docs, p.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []lspDiagnostic{},
+		})
+	case "textDocument/codeAction":
+		var p lspCodeActionParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			s.replyError(msg.ID, lspInvalidParams, err.Error())
+			return
+		}
+		s.reply(msg.ID, s.codeActions(&p))
+	default:
+		if msg.ID != nil {
+			s.replyError(msg.ID, lspMethodNotFound, "method not found: "+msg.Method)
+		}
+	}
+}
+
+// uriToPath converts a file:// URI to a local filesystem path. Other URI
+// schemes (e.g. untitled:) yield an empty path.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// linterFor returns the Linter for the document at path, discovering the
+// config file the same way the CLI does and applying any matching overrides.
+// Documents below the working directory get the config chain a CLI run there
+// would give them; others use the config discovered from their directory. It
+// returns ok=false when the document is excluded by the config's ignores.
+func (s *lspServer) linterFor(path string) (linter *lint.Linter, ruleCfg map[string]interface{}, ok bool) {
+	cwd, _ := os.Getwd()
+	dir := cwd
+	if path != "" {
+		dir = filepath.Dir(path)
+	}
+	root := cwd
+	if root == "" || !isSubdir(root, dir) {
+		root = dir
+	}
+	w, ok := s.workspaces[root]
+	if !ok {
+		w = loadLSPWorkspace(root)
+		s.workspaces[root] = w
+	}
+	setup := w.setup
+	if path == "" {
+		return setup.linter, setup.ruleCfg, true
+	}
+	if setup.cfg != nil && isIgnored(path, setup.cfg.Ignores) {
+		return nil, nil, false
+	}
+	settings, own, err := setup.settingsFor(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+	}
+	if err != nil || !own {
+		return setup.linter, setup.ruleCfg, true
+	}
+	// encoding/json sorts map keys, so equal settings encode equally.
+	key, err := json.Marshal(settings)
+	if err != nil {
+		return newLinter(settings), settings.Config, true
+	}
+	linter, ok = w.linters[string(key)]
+	if !ok {
+		linter = newLinter(settings)
+		w.linters[string(key)] = linter
+	}
+	return linter, settings.Config, true
+}
+
+// dropChangedWorkspaces drops the config of the workspaces whose config
+// files changed, so that it is loaded again, and reports whether it dropped
+// any.
+func (s *lspServer) dropChangedWorkspaces() bool {
+	dropped := false
+	for root, w := range s.workspaces {
+		if w.changed(root) {
+			delete(s.workspaces, root)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// publishAllDiagnostics lints every open document, in order of URI.
+func (s *lspServer) publishAllDiagnostics() {
+	uris := make([]string, 0, len(s.docs))
+	for uri := range s.docs {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		s.publishDiagnostics(uri)
+	}
+}
+
+// publishDiagnostics lints the document identified by uri and sends the
+// resulting diagnostics to the client.
+func (s *lspServer) publishDiagnostics(uri string) {
+	text := s.docs[uri]
+	diagnostics := []lspDiagnostic{}
+	if linter, ruleCfg, ok := s.linterFor(uriToPath(uri)); ok {
+		lines := strings.Split(text, "\n")
+		for _, v := range linter.Lint([]byte(text)) {
+			diagnostics = append(diagnostics, violationToDiagnostic(v, lines, getRuleSeverity(v.Rule, ruleCfg)))
+		}
+	}
+	s.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// violationToDiagnostic converts a violation into an LSP diagnostic. The range
+// spans from the violation column to its end position, or to the end of the
+// line if the violation has none.
+func violationToDiagnostic(v lint.Violation, lines []string, severity string) lspDiagnostic {
+	lineText := func(line int) string {
+		if line < len(lines) {
+			return strings.TrimRight(lines[line], "\r")
+		}
+		return ""
+	}
+	line := max(v.Line-1, 0)
+	text := lineText(line)
+	end := lspPosition{Line: line, Character: utf16Column(text, len(text))}
+	if v.EndLine > 0 {
+		endLine := v.EndLine - 1
+		end = lspPosition{Line: endLine, Character: utf16Column(lineText(endLine), v.EndColumn-1)}
+	}
+	lspSeverity := lspSeverityError
+	switch severity {
+	case "warning":
+		lspSeverity = lspSeverityWarning
+	case "info":
+		lspSeverity = lspSeverityInformation
+	}
+	return lspDiagnostic{
+		Range: lspRange{
+			Start: lspPosition{Line: line, Character: utf16Column(text, v.Column-1)},
+			End:   end,
+		},
+		Severity:        lspSeverity,
+		Code:            v.Rule,
+		CodeDescription: &lspCodeDescription{Href: ruleInfoURL(v.Rule)},
+		Source:          "goldmark-lint",
+		Message:         v.Message,
+	}
+}
+
+// utf16Column converts a byte offset within line to the number of UTF-16 code
+// units that precede it, which is how LSP measures characters.
+func utf16Column(line string, byteOffset int) int {
+	if byteOffset <= 0 {
+		return 0
+	}
+	if byteOffset > len(line) {
+		byteOffset = len(line)
+	}
+	n := 0
+	for _, r := range line[:byteOffset] {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// wholeDocumentEdit returns a text edit that replaces the entirety of text
+// with newText.
+func wholeDocumentEdit(text, newText string) lspTextEdit {
+	lines := strings.Split(text, "\n")
+	last := len(lines) - 1
+	return lspTextEdit{
+		Range: lspRange{
+			End: lspPosition{Line: last, Character: utf16Column(lines[last], len(lines[last]))},
+		},
+		NewText: newText,
+	}
+}
+
+// offsetToPosition converts a byte offset into text to an LSP position.
+func offsetToPosition(text string, offset int) lspPosition {
+	offset = min(max(offset, 0), len(text))
+	line := strings.Count(text[:offset], "\n")
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	return lspPosition{Line: line, Character: utf16Column(text[lineStart:], offset-lineStart)}
+}
+
+// editsToTextEdits converts fix edits on text to LSP text edits.
+func editsToTextEdits(text string, edits []lint.Edit) []lspTextEdit {
+	out := make([]lspTextEdit, len(edits))
+	for i, e := range edits {
+		out[i] = lspTextEdit{
+			Range:   lspRange{Start: offsetToPosition(text, e.Start), End: offsetToPosition(text, e.End)},
+			NewText: e.NewText,
+		}
+	}
+	return out
+}
+
+// codeActions returns a quick fix for every fixable diagnostic in the request
+// context, plus a source.fixAll action that applies every fixable rule.
+// Diagnostics whose violation carries fix edits get a quick fix for just that
+// violation; otherwise the quick fix applies the rule to the whole document.
+func (s *lspServer) codeActions(p *lspCodeActionParams) []lspCodeAction {
+	actions := []lspCodeAction{}
+	uri := p.TextDocument.URI
+	text, ok := s.docs[uri]
+	if !ok {
+		return actions
+	}
+	linter, _, ok := s.linterFor(uriToPath(uri))
+	if !ok {
+		return actions
+	}
+	fixable := make(map[string]lint.FixableRule)
+	for _, r := range linter.Rules {
+		if fr, ok := r.(lint.FixableRule); ok {
+			fixable[r.ID()] = fr
+		}
+	}
+
+	wantKind := func(kind string) bool {
+		if len(p.Context.Only) == 0 {
+			return true
+		}
+		for _, only := range p.Context.Only {
+			if kind == only || strings.HasPrefix(kind, only+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if wantKind("quickfix") {
+		var violations []lint.Violation
+		if len(p.Context.Diagnostics) > 0 {
+			violations = linter.Lint([]byte(text))
+		}
+		lines := strings.Split(text, "\n")
+		seen := make(map[string]bool)
+		for _, d := range p.Context.Diagnostics {
+			rule, ok := fixable[d.Code]
+			if !ok || d.Source != "goldmark-lint" {
+				continue
+			}
+			if edits := diagnosticEdits(d, violations, lines); edits != nil {
+				actions = append(actions, lspCodeAction{
+					Title:       fmt.Sprintf("Fix %s: %s", rule.ID(), rule.Description()),
+					Kind:        "quickfix",
+					Diagnostics: []lspDiagnostic{d},
+					IsPreferred: true,
+					Edit: &lspWorkspaceEdit{Changes: map[string][]lspTextEdit{
+						uri: editsToTextEdits(text, edits),
+					}},
+				})
+				continue
+			}
+			if seen[d.Code] {
+				continue
+			}
+			seen[d.Code] = true
+			single := lint.NewLinter(rule)
+			single.NoInlineConfig = linter.NoInlineConfig
+			single.FrontMatterRegexp = linter.FrontMatterRegexp
+			single.Flavor = linter.Flavor
+			fixed := single.Fix([]byte(text))
+			if bytes.Equal(fixed, []byte(text)) {
+				continue
+			}
+			actions = append(actions, lspCodeAction{
+				Title:       fmt.Sprintf("Fix %s: %s", rule.ID(), rule.Description()),
+				Kind:        "quickfix",
+				Diagnostics: []lspDiagnostic{d},
+				IsPreferred: true,
+				Edit: &lspWorkspaceEdit{Changes: map[string][]lspTextEdit{
+					uri: {wholeDocumentEdit(text, string(fixed))},
+				}},
+			})
+		}
+	}
+
+	if wantKind("source.fixAll") {
+		// A fix-all that does not converge still leaves the document better
+		// off, so the partial result is offered anyway.
+		fixed, _ := linter.FixUntilStable([]byte(text))
+		if !bytes.Equal(fixed, []byte(text)) {
+			actions = append(actions, lspCodeAction{
+				Title: "Fix all auto-fixable goldmark-lint problems",
+				Kind:  "source.fixAll",
+				Edit: &lspWorkspaceEdit{Changes: map[string][]lspTextEdit{
+					uri: {wholeDocumentEdit(text, string(fixed))},
+				}},
+			})
+		}
+	}
+	return actions
+}
+
+// diagnosticEdits returns the fix edits of the violation that d was created
+// from, or nil if there is no such violation or it has no edits.
+func diagnosticEdits(d lspDiagnostic, violations []lint.Violation, lines []string) []lint.Edit {
+	for _, v := range violations {
+		if v.Rule != d.Code || len(v.Fix) == 0 {
+			continue
+		}
+		if violationToDiagnostic(v, lines, "").Range.Start == d.Range.Start {
+			return v.Fix
+		}
+	}
+	return nil
+}
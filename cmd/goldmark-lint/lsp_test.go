@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lspFrame encodes msg as a Content-Length framed LSP message.
+func lspFrame(t *testing.T, msg map[string]interface{}) string {
+	t.Helper()
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// runLSPSession feeds msgs to the server and returns the decoded responses
+// and notifications together with the exit code.
+func runLSPSession(t *testing.T, msgs ...map[string]interface{}) ([]lspMessage, int) {
+	t.Helper()
+	var in strings.Builder
+	for _, m := range msgs {
+		in.WriteString(lspFrame(t, m))
+	}
+	var out bytes.Buffer
+	code := runLSP(strings.NewReader(in.String()), &out)
+
+	s := &lspServer{in: bufio.NewReader(&out)}
+	var got []lspMessage
+	for {
+		body, err := s.readMessage()
+		if err != nil {
+			break
+		}
+		var m lspMessage
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatalf("invalid message from server: %v\n%s", err, body)
+		}
+		got = append(got, m)
+	}
+	return got, code
+}
+
+// lspFileURI returns the file:// URI for path.
+func lspFileURI(path string) string {
+	return "file://" + filepath.ToSlash(path)
+}
+
+func TestLSP_InitializeAndShutdown(t *testing.T) {
+	msgs, code := runLSPSession(t,
+		map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"id": 2, "method": "shutdown"},
+		map[string]interface{}{"method": "exit"},
+	)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %+v", len(msgs), msgs)
+	}
+	caps, ok := msgs[0].Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("initialize result has no capabilities: %+v", msgs[0].Result)
+	}
+	if _, ok := caps["codeActionProvider"]; !ok {
+		t.Error("expected codeActionProvider capability")
+	}
+}
+
+func TestLSP_ExitWithoutShutdown(t *testing.T) {
+	_, code := runLSPSession(t, map[string]interface{}{"method": "exit"})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
+
+func TestLSP_UnknownRequest(t *testing.T) {
+	msgs, _ := runLSPSession(t,
+		map[string]interface{}{"id": 1, "method": "textDocument/hover", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "exit"},
+	)
+	if len(msgs) != 1 || msgs[0].Error == nil || msgs[0].Error.Code != lspMethodNotFound {
+		t.Fatalf("expected MethodNotFound error, got %+v", msgs)
+	}
+}
+
+func TestLSP_DiagnosticsOnOpenAndChange(t *testing.T) {
+	dir := t.TempDir()
+	uri := lspFileURI(filepath.Join(dir, "doc.md"))
+	msgs, _ := runLSPSession(t,
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "markdown", "version": 1, "text": "# Title\n\nText   \n"},
+		}},
+		map[string]interface{}{"method": "textDocument/didChange", "params": map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+			"contentChanges": []map[string]interface{}{{"text": "# Title\n\nText\n"}},
+		}},
+		map[string]interface{}{"method": "exit"},
+	)
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 publishDiagnostics notifications, got %d", len(msgs))
+	}
+	var first, second lspPublishDiagnosticsParams
+	if err := json.Unmarshal(msgs[0].Params, &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(msgs[1].Params, &second); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Diagnostics) != 1 || first.Diagnostics[0].Code != "MD009" {
+		t.Fatalf("expected a single MD009 diagnostic after open, got %+v", first.Diagnostics)
+	}
+	d := first.Diagnostics[0]
+	if d.Range.Start.Line != 2 || d.Range.Start.Character != 4 {
+		t.Errorf("unexpected diagnostic range start: %+v", d.Range.Start)
+	}
+	if d.Severity != lspSeverityError {
+		t.Errorf("severity = %d, want %d", d.Severity, lspSeverityError)
+	}
+	if len(second.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics after change, got %+v", second.Diagnostics)
+	}
+}
+
+func TestLSP_HonoursConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := "config:\n  MD009: warning\n  MD041: false\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := lspFileURI(filepath.Join(dir, "doc.md"))
+	msgs, _ := runLSPSession(t,
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": "Text   \n"},
+		}},
+		map[string]interface{}{"method": "exit"},
+	)
+	var p lspPublishDiagnosticsParams
+	if err := json.Unmarshal(msgs[0].Params, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != "MD009" {
+		t.Fatalf("expected only MD009 (MD041 disabled), got %+v", p.Diagnostics)
+	}
+	if p.Diagnostics[0].Severity != lspSeverityWarning {
+		t.Errorf("severity = %d, want %d", p.Diagnostics[0].Severity, lspSeverityWarning)
+	}
+}
+
+func TestLSP_ReloadsChangedConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgPath, []byte("config:\n  MD041: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := lspFileURI(filepath.Join(dir, "doc.md"))
+	var out bytes.Buffer
+	s := &lspServer{out: &out, docs: make(map[string]string), workspaces: make(map[string]*lspWorkspace)}
+	send := func(method string, params map[string]interface{}) []lspPublishDiagnosticsParams {
+		t.Helper()
+		data, err := json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Reset()
+		s.handle(&lspMessage{Method: method, Params: data})
+		r := &lspServer{in: bufio.NewReader(&out)}
+		var got []lspPublishDiagnosticsParams
+		for {
+			body, err := r.readMessage()
+			if err != nil {
+				return got
+			}
+			var m lspMessage
+			var p lspPublishDiagnosticsParams
+			if err := json.Unmarshal(body, &m); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(m.Params, &p); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, p)
+		}
+	}
+
+	got := send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": "Text   \n"},
+	})
+	if len(got) != 1 || len(got[0].Diagnostics) != 1 || got[0].Diagnostics[0].Code != "MD009" {
+		t.Fatalf("expected a single MD009 diagnostic after open, got %+v", got)
+	}
+	w := s.workspaces[dir]
+	if w == nil {
+		t.Fatalf("expected the config of %s to be kept, got %v", dir, s.workspaces)
+	}
+	send("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": "Text    \n"}},
+	})
+	if s.workspaces[dir] != w {
+		t.Error("expected the config to be reused on change")
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("config:\n  MD009: false\n  MD041: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got = send("workspace/didChangeWatchedFiles", map[string]interface{}{
+		"changes": []map[string]interface{}{{"uri": lspFileURI(cfgPath), "type": 2}},
+	})
+	if len(got) != 1 || len(got[0].Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics after the config changed, got %+v", got)
+	}
+	if s.workspaces[dir] == w {
+		t.Error("expected the config to be loaded again")
+	}
+	if got := send("textDocument/didSave", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}); len(got) != 1 || len(got[0].Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics on save, got %+v", got)
+	}
+}
+
+func TestLSP_SeverityInOptions(t *testing.T) {
+	dir := t.TempDir()
+	cfg := "config:\n  MD009:\n    br_spaces: 4\n    severity: info\n  MD041: false\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := lspFileURI(filepath.Join(dir, "doc.md"))
+	msgs, _ := runLSPSession(t,
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": "Text   \n"},
+		}},
+		map[string]interface{}{"method": "exit"},
+	)
+	var p lspPublishDiagnosticsParams
+	if err := json.Unmarshal(msgs[0].Params, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != "MD009" {
+		t.Fatalf("expected only MD009, got %+v", p.Diagnostics)
+	}
+	if p.Diagnostics[0].Severity != lspSeverityInformation {
+		t.Errorf("severity = %d, want %d", p.Diagnostics[0].Severity, lspSeverityInformation)
+	}
+}
+
+func TestLSP_CodeActions(t *testing.T) {
+	dir := t.TempDir()
+	uri := lspFileURI(filepath.Join(dir, "doc.md"))
+	diag := map[string]interface{}{
+		"range":    map[string]interface{}{"start": map[string]int{"line": 2, "character": 4}, "end": map[string]int{"line": 2, "character": 7}},
+		"code":     "MD009",
+		"source":   "goldmark-lint",
+		"message":  "Trailing spaces",
+		"severity": 1,
+	}
+	msgs, _ := runLSPSession(t,
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": "# Title\n\nText   \n"},
+		}},
+		map[string]interface{}{"id": 7, "method": "textDocument/codeAction", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"range":        diag["range"],
+			"context":      map[string]interface{}{"diagnostics": []interface{}{diag}},
+		}},
+		map[string]interface{}{"method": "exit"},
+	)
+	if len(msgs) != 2 {
+		t.Fatalf("expected diagnostics notification and codeAction response, got %d", len(msgs))
+	}
+	data, err := json.Marshal(msgs[1].Result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []lspCodeAction
+	if err := json.Unmarshal(data, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("expected quickfix and fixAll actions, got %+v", actions)
+	}
+	if actions[0].Kind != "quickfix" || !strings.Contains(actions[0].Title, "MD009") {
+		t.Errorf("unexpected quickfix action: %+v", actions[0])
+	}
+	// MD009 attaches edits, so the quick fix only deletes the trailing spaces.
+	edits := actions[0].Edit.Changes[uri]
+	want := lspTextEdit{Range: lspRange{Start: lspPosition{Line: 2, Character: 4}, End: lspPosition{Line: 2, Character: 7}}}
+	if len(edits) != 1 || edits[0] != want {
+		t.Errorf("unexpected quickfix edit: %+v, want %+v", edits, want)
+	}
+	if actions[1].Kind != "source.fixAll" {
+		t.Errorf("expected source.fixAll action, got %+v", actions[1])
+	}
+}
+
+func TestOffsetToPosition(t *testing.T) {
+	text := "ab\näb\n"
+	tests := []struct {
+		offset int
+		want   lspPosition
+	}{
+		{0, lspPosition{0, 0}},
+		{2, lspPosition{0, 2}},
+		{3, lspPosition{1, 0}},
+		{5, lspPosition{1, 1}},
+		{7, lspPosition{2, 0}},
+		{99, lspPosition{2, 0}},
+	}
+	for _, tt := range tests {
+		if got := offsetToPosition(text, tt.offset); got != tt.want {
+			t.Errorf("offsetToPosition(%d) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestUTF16Column(t *testing.T) {
+	tests := []struct {
+		line   string
+		offset int
+		want   int
+	}{
+		{"abc", 2, 2},
+		{"äbc", 2, 1},
+		{"😀x", 4, 2},
+		{"abc", 10, 3},
+	}
+	for _, tt := range tests {
+		if got := utf16Column(tt.line, tt.offset); got != tt.want {
+			t.Errorf("utf16Column(%q, %d) = %d, want %d", tt.line, tt.offset, got, tt.want)
+		}
+	}
+}
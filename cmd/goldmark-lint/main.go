@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -25,6 +26,10 @@ https://github.com/mrueg/goldmark-lint
 Syntax: goldmark-lint glob0 [glob1] [...] [globN] [--fix] [--help] [--version]
         goldmark-lint - (read from stdin)
         goldmark-lint --format (read stdin, apply fixes, write stdout)
+        goldmark-lint --print-config file (print the config that applies to a file)
+        goldmark-lint lsp (serve the Language Server Protocol over stdio)
+        goldmark-lint config validate [file...] (check config files)
+        goldmark-lint config schema (print a JSON Schema of config files)
 
 Glob expressions:
 - * matches any number of characters, but not /
@@ -32,19 +37,25 @@ Glob expressions:
 - ** matches any number of characters, including /
 
 Optional parameters:
+- --baseline         path to a baseline file; violations recorded in it are not reported
+- --cache-location   path of the cache file, or a directory to keep .goldmark-lint-cache in
 - --config           path to config file (overrides auto-discovery)
+- --diff-base        report only violations on lines added or modified since the merge base with a git ref
 - --fail-on-warning  exit with code 1 even when all violations are warnings
 - --fix              updates files to resolve fixable issues
 - --fix-dry-run      show a diff of changes --fix would make, without modifying files
 - --format           read stdin, apply fixes, write stdout
-- --list-rules       print a table of all rules with their aliases, enabled/disabled state, and options
+- --list-rules       print a table of all rules with their aliases, tags, enabled/disabled state, and options
 - --no-cache         disable reading/writing the .goldmark-lint-cache file
 - --no-globs         ignore the globs config key at runtime
 - --output-format    output format: default, json, junit, tap, sarif, github (default: default)
+- --print-config     print the config that applies to a file, with the config file each setting comes from, and exit
 - --summary           print a count-per-rule breakdown after linting
 - --watch            re-lint files whenever they change (runs until Ctrl+C)
+- --watch-clear      with --watch, clear the screen and show all current violations after each change
 - --help             writes this message to the console and exits without doing anything else
 - --version          prints the version and exits
+- --write-baseline   record the current violations in the --baseline file and exit
 
 Config file:
 - Reads .markdownlint-cli2.yaml (or .yml, .jsonc, .json) from the current
@@ -52,12 +63,17 @@ Config file:
 - Also reads .markdownlint.yaml (or .yml, .jsonc, .json), which uses the
   simpler rule-only format (compatible with vscode-markdownlint).
   .markdownlint-cli2.* files take priority when both are present.
+- Config files in directories below the current directory apply to the files
+  under them: their "config" and "overrides" keys are layered on top of the
+  config of the parent directory.
+- Unknown keys, wrong option types and invalid values are reported as
+  warnings; "goldmark-lint config validate" reports them with exit code 1.
 - Supports "config" (rule enable/disable and options), "ignores",
   "overrides" (per-glob rule config overrides), "extends" (inherit
   configuration from another config file), "outputFormatters", "globs"
   (default input globs), "fix" (enable --fix from config), "frontMatter"
-  (custom front matter regex), and "gitignore" (auto-ignore .gitignore
-  entries) keys.
+  (custom front matter regex), "flavor" (Markdown flavor and extensions),
+  and "gitignore" (auto-ignore .gitignore entries) keys.
 
 Exit codes:
 - 0: Linting was successful and there were no errors
@@ -66,19 +82,34 @@ Exit codes:
 `
 
 func main() {
+	// Subcommands are dispatched before flag parsing so that they can own
+	// their arguments.
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		os.Exit(runLSP(os.Stdin, os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	baselinePath := flag.String("baseline", "", "path to a baseline file; violations recorded in it are not reported")
+	cacheLocation := flag.String("cache-location", "", "path of the cache file, or a directory to keep .goldmark-lint-cache in")
 	configPath := flag.String("config", "", "path to config file (overrides auto-discovery)")
+	diffBase := flag.String("diff-base", "", "report only violations on lines added or modified since the merge base with a git ref")
 	failOnWarning := flag.Bool("fail-on-warning", false, "exit with code 1 even when all violations are warnings")
 	fix := flag.Bool("fix", false, "updates files to resolve fixable issues")
 	fixDryRun := flag.Bool("fix-dry-run", false, "show a diff of changes --fix would make, without modifying files")
 	format := flag.Bool("format", false, "read stdin, apply fixes, write stdout")
 	help := flag.Bool("help", false, "writes help message and exits")
-	listRules := flag.Bool("list-rules", false, "print a table of all rules with their aliases, enabled/disabled state, and options")
+	listRules := flag.Bool("list-rules", false, "print a table of all rules with their aliases, tags, enabled/disabled state, and options")
 	ver := flag.Bool("version", false, "prints the version and exits")
 	noCache := flag.Bool("no-cache", false, "disable reading/writing the cache file")
 	noGlobs := flag.Bool("no-globs", false, "ignore the globs config key at runtime")
 	outputFormat := flag.String("output-format", "", "output format: default, json, junit, tap, sarif, github")
+	printConfig := flag.String("print-config", "", "print the config that applies to a file, with the config file each setting comes from, and exit")
 	summary := flag.Bool("summary", false, "print a count-per-rule breakdown after linting")
 	watch := flag.Bool("watch", false, "re-lint files whenever they change (runs until Ctrl+C)")
+	watchClear := flag.Bool("watch-clear", false, "with --watch, clear the screen and show all current violations after each change")
+	writeBaseline := flag.Bool("write-baseline", false, "record the current violations in the --baseline file and exit")
 	flag.Parse()
 
 	if *help {
@@ -96,6 +127,15 @@ func main() {
 		os.Exit(2)
 	}
 
+	if *writeBaseline && *baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --write-baseline requires --baseline")
+		os.Exit(2)
+	}
+	if *writeBaseline && *watch {
+		fmt.Fprintln(os.Stderr, "Error: --write-baseline and --watch are mutually exclusive")
+		os.Exit(2)
+	}
+
 	// Validate --output-format flag if specified.
 	if *outputFormat != "" {
 		switch *outputFormat {
@@ -108,38 +148,44 @@ func main() {
 
 	// Auto-discover config file starting from the current working directory,
 	// or use the explicitly specified --config path.
-	var cfg *ConfigFile
 	cwd, _ := os.Getwd()
-	if *configPath != "" {
-		loaded, err := loadConfig(*configPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", *configPath, err)
-			os.Exit(2)
-		}
-		cfg = loaded
-	} else if cwd != "" {
-		if cfgPath := findConfigFile(cwd); cfgPath != "" {
-			loaded, err := loadConfig(cfgPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", cfgPath, err)
-				os.Exit(2)
-			}
-			cfg = loaded
-		}
+	cfg, cfgPath, err := discoverConfig(*configPath, cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", cfgPath, err)
+		os.Exit(2)
+	}
+	if cfg != nil {
+		printConfigProblems(os.Stderr, cfg.problems)
 	}
 
 	// Determine the effective input globs: CLI args take priority, then config globs.
 	// When --no-globs is set, config globs are ignored.
-	inputGlobs := flag.Args()
-	if len(inputGlobs) == 0 && !*noGlobs && cfg != nil && len(cfg.Globs) > 0 {
-		inputGlobs = cfg.Globs
+	globsFor := func(cfg *ConfigFile) []string {
+		if len(flag.Args()) == 0 && !*noGlobs && cfg != nil && len(cfg.Globs) > 0 {
+			return cfg.Globs
+		}
+		return flag.Args()
 	}
+	inputGlobs := globsFor(cfg)
 	if *listRules {
 		var ruleCfgForList map[string]interface{}
+		var customRulesForList []CustomRuleConfig
 		if cfg != nil {
 			ruleCfgForList = cfg.Config
+			customRulesForList = cfg.CustomRules
+		}
+		printRulesTable(os.Stdout, ruleCfgForList, customRulesForList...)
+		os.Exit(0)
+	}
+	if *printConfig != "" {
+		setup, err := newLintSetup(cfg, cwd, *configPath == "")
+		if err == nil {
+			err = printEffectiveConfig(os.Stdout, setup, cwd, *printConfig)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
 		}
-		printRulesTable(os.Stdout, ruleCfgForList)
 		os.Exit(0)
 	}
 	if len(inputGlobs) == 0 && !*format {
@@ -147,34 +193,14 @@ func main() {
 		os.Exit(2)
 	}
 
-	var ruleCfg map[string]interface{}
-	var ignores []string
-	var overrides []GlobOverride
-	var noInlineConfig bool
-	// effectiveFix is true when --fix is passed on CLI or fix:true is in config.
-	effectiveFix := *fix
-	if cfg != nil {
-		ruleCfg = cfg.Config
-		ignores = cfg.Ignores
-		overrides = cfg.Overrides
-		noInlineConfig = cfg.NoInlineConfig
-		if cfg.Fix {
-			effectiveFix = true
-		}
-		// gitignore: read .gitignore files and add patterns to ignores.
-		if gitignoreIsEnabled(cfg.Gitignore) && cwd != "" {
-			pattern := gitignoreGlobPattern(cfg.Gitignore)
-			if pattern == "" {
-				// bool true: walk from cwd up to the git repository root.
-				ignores = append(ignores, collectGitignorePatterns(cwd)...)
-			} else {
-				// string: use the glob pattern to find gitignore files.
-				for _, f := range findFilesMatchingGlob(cwd, pattern) {
-					ignores = append(ignores, parseGitignore(f)...)
-				}
-			}
-		}
+	setup, err := newLintSetup(cfg, cwd, *configPath == "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
 	}
+	linter := setup.linter
+	// effectiveFix is true when --fix is passed on CLI or fix:true is in config.
+	effectiveFix := *fix || cfg != nil && cfg.Fix
 
 	// Determine the formatter specs to use.
 	// CLI flag takes priority; then config outputFormatters; then default.
@@ -188,24 +214,20 @@ func main() {
 		formatterSpecs = []outputFormatterSpec{{format: "default"}}
 	}
 
-	// Default linter (used when no overrides are defined or override doesn't match).
-	linter := newLinterFromConfig(ruleCfg)
-	linter.NoInlineConfig = noInlineConfig
-	// frontMatter: set custom front matter regexp on linter if configured.
-	if cfg != nil && cfg.FrontMatter != "" {
-		re, err := regexp.Compile(cfg.FrontMatter)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid frontMatter regex %q: %v\n", cfg.FrontMatter, err)
-			os.Exit(2)
-		}
-		linter.FrontMatterRegexp = re
-	}
-
 	// Load cache (skip when --no-cache, fix, fix-dry-run, or watch is used).
-	useCache := !*noCache && !effectiveFix && !*fixDryRun && !*watch
+	// Its keys are relative to the repository root, so that runs from any
+	// directory share it, and each entry records the settings the file was
+	// linted with.
+	useCache := !*noCache && !effectiveFix && !*fixDryRun && !*watch && cwd != ""
 	cache := make(lintCache)
-	if useCache && cwd != "" {
-		cache = loadCache(cwd)
+	var cacheDir, cacheFile string
+	settings := setup.settings
+	settings.Version = toolVersion()
+	settingsHash := settings.hash()
+	if useCache {
+		cacheDir = cacheRoot(cwd)
+		cacheFile = cachePath(cacheDir, *cacheLocation)
+		cache = loadCache(cacheFile)
 	}
 
 	// --format: read stdin, apply fixes, write stdout, then exit.
@@ -215,7 +237,10 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
 			os.Exit(2)
 		}
-		fixed := linter.Fix(source)
+		fixed, err := linter.FixUntilStable(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 		if _, err := os.Stdout.Write(fixed); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing stdout: %v\n", err)
 			os.Exit(2)
@@ -245,20 +270,40 @@ func main() {
 	}
 
 	// Collect all non-stdin files in order so that output remains deterministic.
-	var allFiles []string
-	for _, pattern := range inputGlobs {
-		if pattern == "-" {
-			continue
-		}
-		files, err := doublestar.FilepathGlob(pattern)
-		if err != nil || len(files) == 0 {
-			files = []string{pattern}
+	allFiles := expandGlobs(inputGlobs, setup.ignores)
+
+	// Resolve the config of each file's directory up front as well, so that
+	// errors in nested config files are reported before any file is linted.
+	if err := setup.resolveConfigs(allFiles); err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		os.Exit(2)
+	}
+	if setup.dirs != nil {
+		printConfigProblems(os.Stderr, setup.dirs.problems)
+	}
+
+	// --diff-base: find the changed lines up front, so that git errors are
+	// reported before any file is linted or fixed.
+	var changes *gitChanges
+	if *diffBase != "" {
+		c, err := loadGitChanges(cwd, *diffBase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --diff-base %s: %v\n", *diffBase, err)
+			os.Exit(2)
 		}
-		for _, file := range files {
-			if !isIgnored(file, ignores) {
-				allFiles = append(allFiles, file)
-			}
+		changes = c
+	}
+
+	// --baseline: load the known violations up front as well. When writing
+	// the baseline, a missing file simply means there is nothing to keep.
+	var known *baseline
+	if *baselinePath != "" {
+		b, err := loadBaseline(*baselinePath, *writeBaseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --baseline: %v\n", err)
+			os.Exit(2)
 		}
+		known = b
 	}
 
 	// fileResult carries the outcome of processing a single file.
@@ -268,6 +313,8 @@ func main() {
 		errCode    int
 		original   []byte // non-nil when --fix-dry-run: the content before fixing
 		fixed      []byte // non-nil when --fix-dry-run: the content after fixing
+		fixErr     error  // non-nil when the fixes did not converge
+		source     []byte // the linted content, after any fixes
 	}
 
 	results := make([]fileResult, len(allFiles))
@@ -293,28 +340,37 @@ func main() {
 			}
 
 			hash := hashContent(source)
+			fileSettings, ownConfig, err := setup.settingsFor(file)
+			if err != nil {
+				results[i] = fileResult{err: err, errCode: 2}
+				return
+			}
+			configHash := settingsHash
+			if ownConfig && useCache {
+				fileSettings.Version = settings.Version
+				configHash = fileSettings.hash()
+			}
 
-			// Cache hit: file unchanged, replay cached violations.
+			// Cache hit: file and settings unchanged, replay cached violations.
+			key := cacheKey(cacheDir, file)
 			if useCache {
-				if entry, ok := cache[file]; ok && entry.Hash == hash {
-					results[i] = fileResult{violations: entry.Violations}
+				if entry, ok := cache[key]; ok && entry.Hash == hash && entry.Config == configHash {
+					results[i] = fileResult{violations: entry.Violations, source: source}
 					return
 				}
 			}
 
 			// Determine the effective linter for this file.
 			fileLinter := linter
-			if len(overrides) > 0 {
-				fileCfg := effectiveConfigForFile(ruleCfg, overrides, file)
-				fileLinter = newLinterFromConfig(fileCfg)
-				fileLinter.NoInlineConfig = noInlineConfig
-				fileLinter.FrontMatterRegexp = linter.FrontMatterRegexp
+			if ownConfig {
+				fileLinter = newLinter(fileSettings)
 			}
 
 			// Apply fixes if requested.
 			var origContent, fixedContent []byte
+			var fixErr error
 			if effectiveFix {
-				fixedContent = fileLinter.Fix(source)
+				fixedContent, fixErr = fileLinter.FixUntilStable(source)
 				if err := os.WriteFile(file, fixedContent, 0644); err != nil {
 					results[i] = fileResult{err: err, errCode: 2}
 					return
@@ -322,25 +378,42 @@ func main() {
 				source = fixedContent
 				hash = hashContent(source)
 			} else if *fixDryRun {
-				fixedContent = fileLinter.Fix(source)
+				fixedContent, fixErr = fileLinter.FixUntilStable(source)
 				origContent = source
 				source = fixedContent
 				hash = hashContent(source)
 			}
 
 			violations := fileLinter.Lint(source)
-			results[i] = fileResult{violations: violations, original: origContent, fixed: fixedContent}
+			results[i] = fileResult{violations: violations, original: origContent, fixed: fixedContent, fixErr: fixErr, source: source}
 
 			// Store the new cache entry.
 			if useCache {
 				mu.Lock()
-				newEntries[file] = cacheEntry{Hash: hash, Violations: violations}
+				newEntries[key] = cacheEntry{Hash: hash, Config: configHash, Violations: violations}
 				mu.Unlock()
 			}
 		}(i, file)
 	}
 	wg.Wait()
 
+	// Project rules such as GL001 see all files at once, so they run after
+	// the per-file pass and their results are never cached.
+	sources := make(map[string][]byte, len(allFiles))
+	for i, file := range allFiles {
+		if results[i].err == nil {
+			sources[file] = results[i].source
+		}
+	}
+	projectViolations := lintProject(setup, sources)
+	for i, file := range allFiles {
+		if pv := projectViolations[file]; len(pv) > 0 {
+			merged := append(append([]lint.Violation(nil), results[i].violations...), pv...)
+			lint.SortViolations(merged)
+			results[i].violations = merged
+		}
+	}
+
 	// Collect file results in original order for deterministic output.
 	for i, file := range allFiles {
 		r := results[i]
@@ -351,7 +424,40 @@ func main() {
 			}
 			continue
 		}
-		allViolations = append(allViolations, fileViolation{File: file, Violations: r.violations})
+		if r.fixErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", file, r.fixErr)
+		}
+		violations := r.violations
+		if *writeBaseline {
+			known.record(file, r.source, violations)
+			continue
+		}
+		if changes != nil {
+			violations = changes.filter(file, violations)
+		}
+		if known != nil {
+			violations = known.suppress(file, r.source, violations)
+		}
+		allViolations = append(allViolations, fileViolation{File: file, Violations: violations})
+	}
+
+	// --write-baseline: save the recorded violations instead of reporting
+	// them. Entries of files that were not linted in this run are kept.
+	if *writeBaseline {
+		if err := known.save(*baselinePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing baseline %s: %v\n", *baselinePath, err)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d baseline entries to %s\n", len(known.entries), *baselinePath)
+		os.Exit(exitCode)
+	}
+
+	// Baseline entries that no longer match are left over from fixed
+	// violations; report them so that the baseline can be refreshed.
+	if known != nil {
+		for _, e := range known.stale() {
+			fmt.Fprintf(os.Stderr, "Warning: %s: stale baseline entry for %s:%d %s\n", *baselinePath, e.File, e.Line, e.Rule)
+		}
 	}
 
 	// --fix-dry-run: output a unified diff for every file that would be changed.
@@ -366,17 +472,13 @@ func main() {
 	}
 
 	// Apply per-violation severity so formatters can use it.
-	for i := range allViolations {
-		for j := range allViolations[i].Violations {
-			allViolations[i].Violations[j].Severity = getRuleSeverity(allViolations[i].Violations[j].Rule, ruleCfg)
-		}
-	}
+	setup.setSeverities(allViolations)
 
 	// Calculate violation-based exit code (only upgrade, never downgrade from 2).
 	if exitCode < 1 {
 		for _, fv := range allViolations {
 			for _, v := range fv.Violations {
-				if v.Severity != "warning" || *failOnWarning {
+				if severityFails(v.Severity, *failOnWarning) {
 					exitCode = 1
 					break
 				}
@@ -436,22 +538,25 @@ func main() {
 		formatSummary(allViolations, os.Stderr)
 	}
 
-	// Persist updated cache entries.
-	if useCache && cwd != "" && len(newEntries) > 0 {
+	// Persist updated cache entries, dropping those of deleted files.
+	if useCache {
 		for k, v := range newEntries {
 			cache[k] = v
 		}
-		if err := saveCache(cwd, cache); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not save cache: %v\n", err)
+		if pruned := pruneCache(cacheDir, cache); pruned || len(newEntries) > 0 {
+			if err := saveCache(cacheFile, cache); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not save cache: %v\n", err)
+			}
 		}
 	}
 
-	// --watch: after the initial lint run, poll files for changes and re-lint.
-	// Violations found during watch cycles are printed to stderr but do not
-	// affect the exit code – the process exits 0 on interrupt (Ctrl+C) since
-	// watch mode is an interactive session, not a one-shot check.
+	// --watch: after the initial lint run, watch the files and the config
+	// for changes and re-lint. Violations found during watch cycles are
+	// printed to stderr but do not affect the exit code – the process exits 0
+	// on interrupt (Ctrl+C) since watch mode is an interactive session, not a
+	// one-shot check.
 	if *watch {
-		runWatch(allFiles, func(changed []string) {
+		relint := func(setup *lintSetup, changed, all []string) []fileViolation {
 			var watchViolations []fileViolation
 			for _, file := range changed {
 				source, err := os.ReadFile(file)
@@ -459,57 +564,356 @@ func main() {
 					fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", file, err)
 					continue
 				}
+				fileLinter, err := setup.linterFor(file)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error %v\n", err)
+					continue
+				}
 				if effectiveFix {
-					fixed := linter.Fix(source)
-					if err := os.WriteFile(file, fixed, 0644); err != nil {
-						fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", file, err)
-						continue
+					fixed, err := fileLinter.FixUntilStable(source)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", file, err)
+					}
+					// Writing unchanged content would be seen as another change.
+					if !bytes.Equal(fixed, source) {
+						if err := os.WriteFile(file, fixed, 0644); err != nil {
+							fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", file, err)
+							continue
+						}
 					}
 					source = fixed
 				}
-				fileLinter := linter
-				if len(overrides) > 0 {
-					fileCfg := effectiveConfigForFile(ruleCfg, overrides, file)
-					fileLinter = newLinterFromConfig(fileCfg)
-					fileLinter.NoInlineConfig = noInlineConfig
-					fileLinter.FrontMatterRegexp = linter.FrontMatterRegexp
-				}
 				violations := fileLinter.Lint(source)
-				for j := range violations {
-					violations[j].Severity = getRuleSeverity(violations[j].Rule, ruleCfg)
-				}
 				watchViolations = append(watchViolations, fileViolation{File: file, Violations: violations})
 			}
-			formatDefault(watchViolations, os.Stderr)
-		})
+			if _, project := setup.projectLinters(all); project {
+				sources := make(map[string][]byte, len(all))
+				for _, file := range all {
+					if source, err := os.ReadFile(file); err == nil {
+						sources[file] = source
+					}
+				}
+				project := lintProject(setup, sources)
+				for j, fv := range watchViolations {
+					fv.Violations = append(fv.Violations, project[fv.File]...)
+					lint.SortViolations(fv.Violations)
+					watchViolations[j] = fv
+				}
+			}
+			if *diffBase != "" {
+				// Edits made while watching change the diff, so reload it.
+				if c, err := loadGitChanges(cwd, *diffBase); err == nil {
+					changes = c
+				}
+				for j, fv := range watchViolations {
+					watchViolations[j].Violations = changes.filter(fv.File, fv.Violations)
+				}
+			}
+			if known != nil {
+				for j, fv := range watchViolations {
+					if source, err := os.ReadFile(fv.File); err == nil {
+						watchViolations[j].Violations = known.suppress(fv.File, source, fv.Violations)
+					}
+				}
+			}
+			setup.setSeverities(watchViolations)
+			return watchViolations
+		}
+		runWatch(watchOptions{
+			configPath: *configPath,
+			cwd:        cwd,
+			globs:      func(setup *lintSetup) []string { return globsFor(setup.cfg) },
+			clear:      *watchClear,
+		}, setup, allFiles, allViolations, relint)
 		os.Exit(0)
 	}
 
 	os.Exit(exitCode)
 }
 
+// discoverConfig loads the config file at configPath or, when configPath is
+// empty, the one findConfigFile discovers from cwd. It returns a nil config
+// when there is none, and the path of the config file in either case.
+func discoverConfig(configPath, cwd string) (*ConfigFile, string, error) {
+	path := configPath
+	if path == "" && cwd != "" {
+		path = findConfigFile(cwd)
+	}
+	if path == "" {
+		return nil, "", nil
+	}
+	cfg, err := loadConfig(path)
+	return cfg, path, err
+}
+
+// lintSetup holds the settings that a config file gives a lint run.
+type lintSetup struct {
+	cfg       *ConfigFile // nil without a config file
+	ruleCfg   map[string]interface{}
+	ignores   []string // including gitignore patterns
+	overrides []GlobOverride
+	// settings are those of the default linter.
+	settings cacheSettings
+	// dirs resolves the config files of the directories below cwd, nil
+	// when they are not discovered.
+	dirs *dirConfigs
+	// linter is the default linter, used for files no override or nested
+	// config file applies to.
+	linter *lint.Linter
+}
+
+// newLintSetup derives the settings of a lint run in cwd from cfg, which may
+// be nil. With discover set, the config files of the directories below cwd
+// are layered on top of cfg for the files in them.
+func newLintSetup(cfg *ConfigFile, cwd string, discover bool) (*lintSetup, error) {
+	s := &lintSetup{cfg: cfg}
+	if discover && cwd != "" {
+		s.dirs = newDirConfigs(cwd, cfg)
+	}
+	if cfg != nil {
+		s.ruleCfg = cfg.Config
+		s.ignores = cfg.Ignores
+		s.overrides = cfg.Overrides
+		// gitignore: read .gitignore files and add patterns to ignores.
+		for _, f := range gitignoreFiles(cwd, cfg.Gitignore) {
+			s.ignores = append(s.ignores, parseGitignore(f)...)
+		}
+		if cfg.FrontMatter != "" {
+			if _, err := regexp.Compile(cfg.FrontMatter); err != nil {
+				return nil, fmt.Errorf("invalid frontMatter regex %q: %v", cfg.FrontMatter, err)
+			}
+		}
+	}
+	s.settings = linterSettings(cfg, s.ruleCfg)
+	s.linter = newLinter(s.settings)
+	return s, nil
+}
+
+// linterSettings returns the settings of a linter for the rule config
+// ruleCfg and the other settings of cfg, which may be nil. Their Version is
+// left empty.
+func linterSettings(cfg *ConfigFile, ruleCfg map[string]interface{}) cacheSettings {
+	settings := cacheSettings{Config: ruleCfg}
+	if cfg != nil {
+		settings.CustomRules = cfg.CustomRules
+		settings.NoInlineConfig = cfg.NoInlineConfig
+		settings.FrontMatter = cfg.FrontMatter
+		settings.Flavor = cfg.Flavor
+	}
+	return settings
+}
+
+// newLinter returns a linter with the given settings. Config files are only
+// loaded with a valid frontMatter regex.
+func newLinter(settings cacheSettings) *lint.Linter {
+	l := newLinterFromConfig(settings.Config, settings.CustomRules...)
+	l.NoInlineConfig = settings.NoInlineConfig
+	if settings.FrontMatter != "" {
+		l.FrontMatterRegexp, _ = regexp.Compile(settings.FrontMatter)
+	}
+	l.Flavor = settings.Flavor
+	return l
+}
+
+// configFor returns the config for file: that of the nearest config chain.
+func (s *lintSetup) configFor(file string) (*ConfigFile, error) {
+	if s.dirs == nil {
+		return s.cfg, nil
+	}
+	return s.dirs.forFile(file)
+}
+
+// ruleConfigFor returns the rule config for file: that of the nearest config
+// chain, with any matching overrides applied. The bool reports whether it may
+// differ from ruleCfg, in which case file needs a linter of its own.
+func (s *lintSetup) ruleConfigFor(file string) (map[string]interface{}, bool, error) {
+	cfg, err := s.configFor(file)
+	if err != nil {
+		return nil, false, err
+	}
+	if cfg == s.cfg && len(s.overrides) == 0 {
+		return s.ruleCfg, false, nil
+	}
+	if cfg == nil {
+		return nil, true, nil
+	}
+	return cfg.ruleConfigForFile(file), true, nil
+}
+
+// settingsFor returns the settings of the linter for file: the rule config
+// that ruleConfigFor gives, and the other settings of its config chain. The
+// bool reports whether they may differ from those of the default linter.
+func (s *lintSetup) settingsFor(file string) (cacheSettings, bool, error) {
+	ruleCfg, own, err := s.ruleConfigFor(file)
+	if err != nil || !own {
+		return s.settings, false, err
+	}
+	cfg, err := s.configFor(file)
+	if err != nil {
+		return cacheSettings{}, false, err
+	}
+	return linterSettings(cfg, ruleCfg), true, nil
+}
+
+// resolveConfigs resolves the config chains of the directories of files,
+// returning the first error.
+func (s *lintSetup) resolveConfigs(files []string) error {
+	for _, file := range files {
+		if _, _, err := s.ruleConfigFor(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linterFor returns the linter for file, applying its config chain and any
+// matching overrides.
+func (s *lintSetup) linterFor(file string) (*lint.Linter, error) {
+	settings, own, err := s.settingsFor(file)
+	if err != nil || !own {
+		return s.linter, err
+	}
+	return newLinter(settings), nil
+}
+
+// setSeverities sets the severity of each violation from the rule config of
+// its file, so that overrides and nested config files can change it.
+func (s *lintSetup) setSeverities(violations []fileViolation) {
+	for _, fv := range violations {
+		ruleCfg := s.ruleCfg
+		if fv.File != "stdin" {
+			if cfg, _, err := s.ruleConfigFor(fv.File); err == nil {
+				ruleCfg = cfg
+			}
+		}
+		for j := range fv.Violations {
+			fv.Violations[j].Severity = getRuleSeverity(fv.Violations[j].Rule, ruleCfg)
+		}
+	}
+}
+
+// severityFails reports whether a violation of the given severity makes the
+// run fail: errors always do, warnings only with failOnWarning, and info
+// never does.
+func severityFails(severity string, failOnWarning bool) bool {
+	switch severity {
+	case "warning":
+		return failOnWarning
+	case "info":
+		return false
+	}
+	return true
+}
+
+// expandGlobs returns the files matching globs, in order, leaving out those
+// matching ignores. A glob that matches nothing is kept as a file name, so
+// that reading it reports the error. Stdin ("-") is skipped.
+func expandGlobs(globs, ignores []string) []string {
+	var allFiles []string
+	for _, pattern := range globs {
+		if pattern == "-" {
+			continue
+		}
+		files, err := doublestar.FilepathGlob(pattern)
+		if err != nil || len(files) == 0 {
+			files = []string{pattern}
+		}
+		for _, file := range files {
+			if !isIgnored(file, ignores) {
+				allFiles = append(allFiles, file)
+			}
+		}
+	}
+	return allFiles
+}
+
+// hasProjectRules reports whether l runs any lint.ProjectRule.
+func hasProjectRules(l *lint.Linter) bool {
+	for _, r := range l.Rules {
+		if _, ok := r.(lint.ProjectRule); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// projectLinters returns the linter of each of files, files that share the
+// settings of a linter sharing it, and whether any of them runs a project
+// rule. Files whose config cannot be resolved are left out.
+func (s *lintSetup) projectLinters(files []string) (map[string]*lint.Linter, bool) {
+	linters := make(map[string]*lint.Linter, len(files))
+	byKey := make(map[string]*lint.Linter)
+	project := false
+	for _, file := range files {
+		settings, own, err := s.settingsFor(file)
+		if err != nil {
+			continue
+		}
+		key := ""
+		if own {
+			// encoding/json sorts map keys, so equal settings encode equally.
+			data, err := json.Marshal(settings)
+			if err != nil {
+				data = []byte(file)
+			}
+			key = "config:" + string(data)
+		}
+		l, ok := byKey[key]
+		if !ok {
+			l = s.linter
+			if own {
+				l = newLinter(settings)
+			}
+			byKey[key] = l
+			project = project || hasProjectRules(l)
+		}
+		linters[file] = l
+	}
+	return linters, project
+}
+
+// lintProject runs the project rules of setup over sources, a map from file
+// path to content. Each project rule runs once, over all of sources, so that
+// links between files with different configs resolve, and each file gets
+// the violations of the project rules its own config enables.
+func lintProject(setup *lintSetup, sources map[string][]byte) map[string][]lint.Violation {
+	files := make([]string, 0, len(sources))
+	for file := range sources {
+		files = append(files, file)
+	}
+	linters, project := setup.projectLinters(files)
+	if !project {
+		return nil
+	}
+	return lint.LintProjectFiles(sources, func(file string) *lint.Linter { return linters[file] })
+}
+
 // printRulesTable writes a human-readable table of all known rules to w.
-// Each row shows the rule ID, aliases, enabled/disabled state, and current
+// Each row shows the rule ID, aliases, tags, enabled/disabled state, and current
 // option values (as a JSON object, omitting empty values).
-func printRulesTable(w io.Writer, cfg map[string]interface{}) {
+func printRulesTable(w io.Writer, cfg map[string]interface{}, custom ...CustomRuleConfig) {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	if _, err := fmt.Fprintln(tw, "RULE\tALIASES\tENABLED\tOPTIONS"); err != nil {
+	if _, err := fmt.Fprintln(tw, "RULE\tALIASES\tTAGS\tENABLED\tOPTIONS"); err != nil {
 		return
 	}
-	if _, err := fmt.Fprintln(tw, "----\t-------\t-------\t-------"); err != nil {
+	if _, err := fmt.Fprintln(tw, "----\t-------\t----\t-------\t-------"); err != nil {
 		return
 	}
-	for _, info := range buildAllRulesInfo(cfg) {
+	for _, info := range buildAllRulesInfo(cfg, custom...) {
 		aliases := ""
 		if ar, ok := info.rule.(lint.AliasedRule); ok {
 			aliases = strings.Join(ar.Aliases(), ", ")
 		}
+		tags := ""
+		if tr, ok := info.rule.(lint.TaggedRule); ok {
+			tags = strings.Join(tr.Tags(), ", ")
+		}
 		enabled := "true"
 		if !info.enabled {
 			enabled = "false"
 		}
 		options := ruleOptionsJSON(info.rule)
-		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", info.rule.ID(), aliases, enabled, options); err != nil {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", info.rule.ID(), aliases, tags, enabled, options); err != nil {
 			return
 		}
 	}
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -163,6 +165,55 @@ func TestCLI_Fix(t *testing.T) {
 	}
 }
 
+func TestCLI_Fix_RespectsInlineDisables(t *testing.T) {
+	bin := buildBinary(t)
+
+	mdFile := filepath.Join(t.TempDir(), "test.md")
+	content := "# Heading\n\n<!-- markdownlint-disable MD010 -->\n| a\tb |\n<!-- markdownlint-enable MD010 -->\n\nText\twith tab\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--fix", mdFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected exit 0 after fixing, got: %v\n%s", err, out)
+	}
+
+	fixed, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Heading\n\n<!-- markdownlint-disable MD010 -->\n| a\tb |\n<!-- markdownlint-enable MD010 -->\n\nText    with tab\n"
+	if string(fixed) != want {
+		t.Errorf("fixed content = %q, want %q", string(fixed), want)
+	}
+}
+
+func TestCLI_Fix_UntilStable(t *testing.T) {
+	bin := buildBinary(t)
+
+	// Removing the whitespace-only line (MD009) leaves two consecutive blank
+	// lines (MD012), which a single fix pass would not catch.
+	mdFile := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Heading\n\nText\n\n   \n\nMore\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--fix", mdFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected exit 0 after a single --fix run, got: %v\n%s", err, out)
+	}
+
+	fixed, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Heading\n\nText\n\nMore\n"
+	if string(fixed) != want {
+		t.Errorf("fixed content = %q, want %q", string(fixed), want)
+	}
+}
+
 func TestCLI_Stdin_NoViolations(t *testing.T) {
 	bin := buildBinary(t)
 	cmd := exec.Command(bin, "-")
@@ -263,6 +314,178 @@ func TestCLI_ConfigFlag(t *testing.T) {
 	}
 }
 
+func TestCLI_CrossFileLinks(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A\n\n[b](b.md#setup) [c](c.md)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.md"), []byte("# B\n\n## Install\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	glob := filepath.Join(dir, "*.md")
+
+	// GL001 is opt-in, so the broken links go unreported by default.
+	if out, err := exec.Command(bin, glob).CombinedOutput(); err != nil {
+		t.Fatalf("expected exit 0 without GL001 enabled, got: %v\n%s", err, out)
+	}
+
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("config:\n  GL001: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(bin, "--config", cfgPath, glob).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1 with GL001 enabled, output:\n%s", out)
+	}
+	for _, want := range []string{"Fragment not found: b.md#setup", "File not found: c.md"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestCLI_ExternalLinks(t *testing.T) {
+	bin := buildBinary(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	content := "# Links\n\n[ok](" + srv.URL + "/ok)\n\n[gone](" + srv.URL + "/gone)\n"
+	if err := os.WriteFile(filepath.Join(dir, "doc.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "config:\n  GL004:\n    cache_file: .links.json\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "--no-cache", "doc.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1 for a dead link, output:\n%s", out)
+	}
+	if !strings.Contains(string(out), "doc.md:5:1 GL004") || !strings.Contains(string(out), "/gone: 404 Not Found") {
+		t.Errorf("expected a GL004 violation on line 5, got:\n%s", out)
+	}
+	if strings.Contains(string(out), "/ok") {
+		t.Errorf("expected the live link not to be reported, got:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".links.json")); err != nil {
+		t.Errorf("expected the link cache to be written: %v", err)
+	}
+}
+
+func TestCLI_FrontMatterSyntax(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "post.md")
+	content := "+++\ntitle = \"Post\"\ndraft = yes\n+++\n\nText\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(bin, "--no-cache", mdFile).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1 for invalid front matter, output:\n%s", out)
+	}
+	if !strings.Contains(string(out), "post.md:3:1 GL002 Invalid TOML front matter") {
+		t.Errorf("expected a GL002 violation on line 3, got:\n%s", out)
+	}
+
+	// Once fixed, the title in the TOML front matter satisfies MD041.
+	content = "+++\ntitle = \"Post\"\ndraft = true\n+++\n\nText\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command(bin, "--no-cache", mdFile).CombinedOutput(); err != nil {
+		t.Errorf("expected exit 0 for valid front matter, got %v:\n%s", err, out)
+	}
+}
+
+func TestCLI_UnusedSuppressions(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	cfg := "config:\n  GL006:\n    require_reason: true\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := "# Doc\n\n<!-- markdownlint-disable-next-line MD033 -- embedded video -->\n<video src=\"a.mp4\"></video>\n\n<!-- markdownlint-disable-next-line no-bare-urls -->\nText\n"
+	if err := os.WriteFile(filepath.Join(dir, "doc.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "--no-cache", "doc.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1 for an unused suppression, output:\n%s", out)
+	}
+	for _, want := range []string{
+		"doc.md:6:1 GL006 Inline disable comments should be necessary [markdownlint-disable-next-line suppresses no violations of no-bare-urls]",
+		"doc.md:6:1 GL006 Inline disable comments should be necessary [markdownlint-disable-next-line should give a reason after \"--\"]",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), ":3:") || strings.Contains(string(out), "MD033") {
+		t.Errorf("expected the justified suppression to pass, got:\n%s", out)
+	}
+}
+
+func TestCLI_FrontMatterSchemaOverrides(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "blog"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	post := "---\ntitle: Post\ndraft: \"no\"\n---\n\n# Post\n"
+	if err := os.WriteFile(filepath.Join(dir, "blog", "post.md"), []byte(post), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `config:
+  GL003:
+    required: [title]
+overrides:
+  - files: ["blog/**"]
+    config:
+      GL003:
+        required: [title, date]
+        properties:
+          draft:
+            type: boolean
+`
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "--no-cache", "**/*.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected exit 1, output:\n%s", out)
+	}
+	for _, want := range []string{
+		`README.md:1:1 GL003 Missing required front matter key "title"`,
+		`post.md:1:1 GL003 Missing required front matter key "date"`,
+		`post.md:3:1 GL003 Front matter key "draft": expected boolean, got string`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
 func TestCLI_ConfigFlag_BadPath(t *testing.T) {
 	bin := buildBinary(t)
 	cmd := exec.Command(bin, "--config", "/nonexistent/config.yaml", "somefile.md")
@@ -328,6 +551,9 @@ func TestCLI_ListRules(t *testing.T) {
 	if !strings.Contains(outStr, "heading-increment") {
 		t.Errorf("expected alias 'heading-increment' in --list-rules output, got:\n%s", outStr)
 	}
+	if !strings.Contains(outStr, "TAGS") || !strings.Contains(outStr, "headings, blank_lines") {
+		t.Errorf("expected the tags of MD022 in --list-rules output, got:\n%s", outStr)
+	}
 
 	// With a config that disables MD001: should show enabled=false for MD001.
 	dir := t.TempDir()
@@ -385,6 +611,74 @@ func TestCLI_FailOnWarning(t *testing.T) {
 	}
 }
 
+func TestCLI_SeverityInOptions(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "strict"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Each file has an MD013 violation only.
+	long := "# Title\n\n" + strings.Repeat("word ", 10) + "end\n"
+	for _, name := range []string{"doc.md", "strict/doc.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(long), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfgContent := "config:\n  MD013:\n    line_length: 40\n    severity: warning\n" +
+		"overrides:\n  - files: [\"strict/**\"]\n    config:\n      MD013:\n        severity: error\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfgContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The options apply along with the severity: a warning exits 0.
+	cmd := exec.Command(bin, "--no-cache", "--output-format", "github", "doc.md")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Errorf("expected exit 0 for a warning, got %v", err)
+	}
+	if !strings.Contains(string(out), "::warning file=doc.md,line=3") {
+		t.Errorf("expected a warning annotation for MD013, got:\n%s", out)
+	}
+
+	// The override makes it an error for the files under strict.
+	cmd = exec.Command(bin, "--no-cache", "--output-format", "github", "strict/doc.md")
+	cmd.Dir = dir
+	out, err = cmd.Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Errorf("expected exit 1 for an error from the override, got %v", err)
+	}
+	if !strings.Contains(string(out), "::error file=strict/doc.md,line=3") {
+		t.Errorf("expected an error annotation for MD013, got:\n%s", out)
+	}
+}
+
+func TestCLI_InfoSeverityNeverFails(t *testing.T) {
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("Not a heading\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgContent := "config:\n  MD041: info\n"
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte(cfgContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "--fail-on-warning", "--output-format", "sarif", mdFile)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Errorf("expected exit 0 for info violations even with --fail-on-warning, got: %v", err)
+	}
+	if !strings.Contains(string(out), `"level": "note"`) {
+		t.Errorf("expected SARIF level note, got:\n%s", out)
+	}
+}
+
 func TestCLI_NoGlobs(t *testing.T) {
 	bin := buildBinary(t)
 
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// tracedValue is a config value with the file it comes from. Since the keys
+// of an object are merged one by one, an object holds the traced values of
+// its keys in fields; source is then the file that last set it as a whole.
+type tracedValue struct {
+	value  interface{}
+	source string
+	fields map[string]*tracedValue // nil unless the value is an object
+}
+
+// newTracedValue returns v, and all of its keys, traced to source.
+func newTracedValue(v interface{}, source string) *tracedValue {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return &tracedValue{value: v, source: source}
+	}
+	t := &tracedValue{source: source, fields: make(map[string]*tracedValue, len(m))}
+	for k, fv := range m {
+		t.fields[k] = newTracedValue(fv, source)
+	}
+	return t
+}
+
+// plain returns the value without its sources.
+func (t *tracedValue) plain() interface{} {
+	if t.fields == nil {
+		return t.value
+	}
+	return plainConfig(t.fields)
+}
+
+// plainConfig returns the config map of the traced values.
+func plainConfig(values map[string]*tracedValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v.plain()
+	}
+	return m
+}
+
+// traceMerge merges overlay, read from source, into values as mergeConfigs
+// merges it into a config map.
+func traceMerge(values map[string]*tracedValue, overlay map[string]interface{}, source string) {
+	for k, v := range overlay {
+		if old, ok := values[k]; ok && old.fields != nil {
+			if m, ok := v.(map[string]interface{}); ok {
+				traceMerge(old.fields, m, source)
+				continue
+			}
+		}
+		values[k] = newTracedValue(v, source)
+	}
+}
+
+// traceRuleConfig returns the rule config that cfg gives file, as
+// ConfigFile.ruleConfigForFile does, with the file each value comes from.
+// name returns the name by which to refer to a config file.
+func traceRuleConfig(cfg *ConfigFile, file string, name func(string) string) map[string]*tracedValue {
+	values := make(map[string]*tracedValue)
+	if cfg == nil {
+		return values
+	}
+	for _, dir := range cfg.dirLayers() {
+		// The "config" keys of a directory's config and the files it
+		// extends are merged before any of their overrides applies.
+		for _, layer := range dir.layers {
+			traceMerge(values, layer.cfg.Config, name(layer.source))
+		}
+		for _, layer := range dir.layers {
+			for i, ov := range layer.cfg.Overrides {
+				if matchesAnyPattern(file, ov.Files) {
+					traceMerge(values, ov.Config, fmt.Sprintf("%s (overrides[%d])", name(layer.source), i))
+				}
+			}
+		}
+	}
+	return values
+}
+
+// traceConfigureFile applies the markdownlint-configure-file comment of
+// source, read from file, to values, as lint.Linter applies it: an options
+// object replaces those of the rule, true enables a disabled rule with its
+// default options and false disables the rule. The values it sets are traced
+// to the line of the comment. Tags are not applied.
+func traceConfigureFile(values map[string]*tracedValue, source []byte, custom []CustomRuleConfig, file string) {
+	inline, line := lint.ConfigureFile(source)
+	from := fmt.Sprintf("%s:%d (markdownlint-configure-file)", file, line)
+	keys := make([]string, 0, len(inline))
+	for key := range inline {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ids, kind := ruleKeyIDs(key, custom)
+		if len(ids) == 0 || kind == ruleKeyTag {
+			continue
+		}
+		switch v := inline[key].(type) {
+		case map[string]interface{}:
+			values[ids[0]] = newTracedValue(v, from)
+		case bool:
+			if !v {
+				values[ids[0]] = newTracedValue(false, from)
+			} else if !isRuleEnabled(ids[0], plainConfig(values)) {
+				values[ids[0]] = newTracedValue(true, from)
+			}
+		}
+	}
+}
+
+// ignoredBy returns the ignore pattern of s that file matches and the file
+// the pattern comes from, or empty strings when file is not ignored.
+func (s *lintSetup) ignoredBy(file, cwd string) (pattern, source string) {
+	if s.cfg == nil {
+		return "", ""
+	}
+	for _, layer := range s.cfg.layers {
+		for _, p := range layer.cfg.Ignores {
+			if matchesAnyPattern(file, []string{p}) {
+				return p, layer.source
+			}
+		}
+	}
+	for _, path := range gitignoreFiles(cwd, s.cfg.Gitignore) {
+		for _, p := range parseGitignore(path) {
+			if matchesAnyPattern(file, []string{p}) {
+				return p, path
+			}
+		}
+	}
+	return "", ""
+}
+
+// appendedSettings are the top-level config keys whose lists extends
+// concatenates, rather than the extending file replacing them.
+var appendedSettings = map[string]bool{"ignores": true, "customRules": true}
+
+// printEffectiveConfig writes the config that applies to file in a lint run
+// of setup in cwd as YAML, with a comment giving the file each setting comes
+// from. The header says which config files apply and whether file is
+// ignored. The markdownlint-configure-file comment of file, if it exists and
+// inline config is not disabled, is applied on top of the rule config.
+func printEffectiveConfig(w io.Writer, setup *lintSetup, cwd, file string) error {
+	name := func(path string) string {
+		if cwd != "" && isSubdir(cwd, path) {
+			if rel, err := filepath.Rel(cwd, path); err == nil {
+				return rel
+			}
+		}
+		return path
+	}
+
+	cfg, err := setup.configFor(file)
+	if err != nil {
+		return err
+	}
+	rules := traceRuleConfig(cfg, file, name)
+	settings, _, err := setup.settingsFor(file)
+	if err != nil {
+		return err
+	}
+	if !settings.NoInlineConfig {
+		source, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		traceConfigureFile(rules, source, settings.CustomRules, file)
+	}
+
+	header := []string{"Effective configuration for " + file}
+	if cfg == nil {
+		header = append(header, "No config file applies.")
+	} else {
+		header = append(header, "Config files, in the order they apply:")
+		for _, layer := range cfg.layers {
+			header = append(header, "  - "+name(layer.source))
+		}
+	}
+	if pattern, source := setup.ignoredBy(file, cwd); pattern != "" {
+		header = append(header, fmt.Sprintf("Ignored by %q from %s.", pattern, name(source)))
+	} else {
+		header = append(header, "Not ignored.")
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	if len(rules) > 0 {
+		root.Content = append(root.Content, scalarNode("config"), tracedMapNode(rules))
+	}
+	if cfg != nil {
+		settings, err := settingNodes(cfg, name)
+		if err != nil {
+			return err
+		}
+		root.Content = append(root.Content, settings...)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: strings.Join(header, "\n"), Content: []*yaml.Node{root}}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// settingNodes returns the key and value nodes of the top-level settings of
+// cfg other than "extends", "config" and "overrides" that are set, each
+// commented with the file it comes from.
+func settingNodes(cfg *ConfigFile, name func(string) string) ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	t := reflect.TypeOf(*cfg)
+	merged := reflect.ValueOf(*cfg)
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		switch key {
+		case "", "extends", "config", "overrides":
+			continue
+		}
+		if merged.Field(i).IsZero() {
+			continue
+		}
+		if appendedSettings[key] {
+			// The lists of the files of a directory come after those of
+			// the directories above it, so a list that is not layered
+			// ends before those of nested config files.
+			seq := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, layer := range cfg.layers {
+				items := reflect.ValueOf(*layer.cfg).Field(i)
+				for j := 0; j < items.Len() && len(seq.Content) < merged.Field(i).Len(); j++ {
+					n, err := valueNode(items.Index(j).Interface(), name(layer.source))
+					if err != nil {
+						return nil, err
+					}
+					seq.Content = append(seq.Content, n)
+				}
+			}
+			nodes = append(nodes, scalarNode(key), seq)
+			continue
+		}
+		// The value comes from the last file that sets it.
+		var source string
+		for _, layer := range cfg.layers {
+			if reflect.DeepEqual(reflect.ValueOf(*layer.cfg).Field(i).Interface(), merged.Field(i).Interface()) {
+				source = name(layer.source)
+			}
+		}
+		n, err := valueNode(merged.Field(i).Interface(), source)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, scalarNode(key), n)
+	}
+	return nodes, nil
+}
+
+// tracedMapNode returns the mapping node of values, with "default" first and
+// the other keys sorted.
+func tracedMapNode(values map[string]*tracedValue) *yaml.Node {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "default") != (keys[j] == "default") {
+			return keys[i] == "default"
+		}
+		return keys[i] < keys[j]
+	})
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range keys {
+		v := values[key]
+		var vn *yaml.Node
+		if len(v.fields) > 0 {
+			vn = tracedMapNode(v.fields)
+		} else {
+			// Values decoded from a config file always encode.
+			vn, _ = valueNode(v.plain(), v.source)
+		}
+		n.Content = append(n.Content, scalarNode(key), vn)
+	}
+	return n
+}
+
+// valueNode returns the node of v, commented with source. Lists and objects
+// are written on one line, so that the comment covers all of them.
+func valueNode(v interface{}, source string) (*yaml.Node, error) {
+	n := &yaml.Node{}
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.SequenceNode || n.Kind == yaml.MappingNode {
+		n.Style = yaml.FlowStyle
+	}
+	n.LineComment = source
+	return n, nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePrintConfigFixture writes a project whose config extends a base
+// config, has an override for docs/** and a nested config in docs, and
+// returns its directory.
+func writePrintConfigFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": "config:\n  default: true\n  MD013:\n    line_length: 100\n    code_blocks: false\nignores:\n  - \"vendor/**\"\n",
+		".markdownlint-cli2.yaml": "extends: base.yaml\nconfig:\n  line-length:\n    line_length: 120\n  MD033: false\n" +
+			"overrides:\n  - files: [\"docs/**\"]\n    config:\n      MD013:\n        tables: false\n",
+		"docs/.markdownlint.yaml": "MD041: false\n",
+		"docs/guide.md":           "# Guide\n\n<!-- markdownlint-configure-file { \"no-hard-tabs\": { \"spaces_per_tab\": 2 }, \"MD033\": true, \"no-trailing-punctuation\": false } -->\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPrintEffectiveConfig(t *testing.T) {
+	dir := writePrintConfigFixture(t)
+	cfg, _, err := discoverConfig("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup, err := newLintSetup(cfg, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "docs", "guide.md")
+	var buf bytes.Buffer
+	if err := printEffectiveConfig(&buf, setup, dir, file); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"#   - base.yaml\n#   - .markdownlint-cli2.yaml\n#   - docs/.markdownlint.yaml\n# Not ignored.\n",
+		"  default: true # base.yaml\n",
+		"    code_blocks: false # base.yaml\n",
+		"    line_length: 120 # .markdownlint-cli2.yaml\n",
+		"    tables: false # .markdownlint-cli2.yaml (overrides[0])\n",
+		"  MD041: false # docs/.markdownlint.yaml\n",
+		"    spaces_per_tab: 2 # " + file + ":3 (markdownlint-configure-file)\n",
+		"  MD033: true # " + file + ":3 (markdownlint-configure-file)\n",
+		"  MD026: false # " + file + ":3 (markdownlint-configure-file)\n",
+		"  - vendor/** # base.yaml\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := printEffectiveConfig(&buf, setup, dir, filepath.Join(dir, "vendor", "lib.md")); err != nil {
+		t.Fatal(err)
+	}
+	if want := `# Ignored by "vendor/**" from base.yaml.`; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestTraceRuleConfig_MatchesRuleConfigFor(t *testing.T) {
+	dir := writePrintConfigFixture(t)
+	cfg, _, err := discoverConfig("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup, err := newLintSetup(cfg, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"README.md", "docs/guide.md"} {
+		file := filepath.Join(dir, name)
+		want, _, err := setup.ruleConfigFor(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileCfg, err := setup.configFor(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := plainConfig(traceRuleConfig(fileCfg, file, func(path string) string { return path }))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: traced config = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestCLI_PrintConfig(t *testing.T) {
+	bin := buildBinary(t)
+	dir := writePrintConfigFixture(t)
+
+	cmd := exec.Command(bin, "--print-config", "docs/guide.md")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected exit 0, got %v:\n%s", err, out)
+	}
+	if !strings.HasPrefix(string(out), "# Effective configuration for docs/guide.md\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if want := "  MD041: false # docs/.markdownlint.yaml\n"; !strings.Contains(string(out), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+
+	// With --config, config files in subdirectories are not discovered.
+	cmd = exec.Command(bin, "--config", "base.yaml", "--print-config", "docs/guide.md")
+	cmd.Dir = dir
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected exit 0, got %v:\n%s", err, out)
+	}
+	if strings.Contains(string(out), "MD041") || !strings.Contains(string(out), "    line_length: 100 # base.yaml\n") {
+		t.Errorf("expected only the config of base.yaml, got:\n%s", out)
+	}
+}
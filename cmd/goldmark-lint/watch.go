@@ -2,54 +2,350 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+const (
+	// watchInterval is how often files are checked where no event-based
+	// watcher is available.
+	watchInterval = 500 * time.Millisecond
+	// watchDebounce is how long the watcher waits for changes to settle, so
+	// that editors saving a file in several steps cause a single re-lint.
+	watchDebounce = 100 * time.Millisecond
 )
 
-const watchInterval = 500 * time.Millisecond
+// dirWatcher reports changes to the entries of a set of directories.
+type dirWatcher interface {
+	// Watch sets the directories to watch, replacing the previous set.
+	Watch(dirs []string) error
+	// Events receives a value after something changed in a watched
+	// directory. Changes close together may be reported once.
+	Events() <-chan struct{}
+	Close() error
+}
+
+// pollWatcher is the dirWatcher used where no event-based one is available.
+// It reports a possible change every watchInterval, leaving it to the caller
+// to compare the files.
+type pollWatcher struct {
+	ticker *time.Ticker
+	events chan struct{}
+	done   chan struct{}
+}
+
+func newPollWatcher() *pollWatcher {
+	w := &pollWatcher{
+		ticker: time.NewTicker(watchInterval),
+		events: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-w.ticker.C:
+				select {
+				case w.events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return w
+}
+
+func (w *pollWatcher) Watch(dirs []string) error { return nil }
+func (w *pollWatcher) Events() <-chan struct{}   { return w.events }
+
+func (w *pollWatcher) Close() error {
+	w.ticker.Stop()
+	close(w.done)
+	return nil
+}
+
+// watchOptions configures runWatch.
+type watchOptions struct {
+	// configPath is the --config path, or "" to discover the config file
+	// from cwd.
+	configPath string
+	cwd        string
+	// globs returns the input globs for a setup.
+	globs func(setup *lintSetup) []string
+	// clear redraws the violations of all files after each change, instead
+	// of printing those of the changed files.
+	clear bool
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampFile returns the stamp of the file at path, and false if it is not a
+// regular file.
+func stampFile(path string) (fileStamp, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return fileStamp{}, false
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, true
+}
+
+// watchSession is the state of a watch: the setup in use, the files the
+// globs match, and their latest violations.
+type watchSession struct {
+	watchOptions
+	relint func(setup *lintSetup, changed, all []string) []fileViolation
+
+	setup *lintSetup
+	// configFile is the config file in use, "" if none; configStamps holds
+	// the stamps of it and the files it extends.
+	configFile   string
+	configStamps map[string]fileStamp
+
+	files      []string
+	stamps     map[string]fileStamp
+	violations map[string][]lint.Violation
+}
 
-// runWatch polls allFiles for modification-time changes every watchInterval and
-// calls relintFn with each batch of changed files. It runs until the process
-// receives an interrupt or SIGTERM signal.
-func runWatch(allFiles []string, relintFn func(files []string)) {
-	// Seed initial modification times.
-	mtimes := make(map[string]time.Time, len(allFiles))
-	for _, f := range allFiles {
-		if fi, err := os.Stat(f); err == nil {
-			mtimes[f] = fi.ModTime()
+// runWatch re-lints files whenever they change until the process receives an
+// interrupt or SIGTERM signal. On Linux it waits for inotify events, and
+// elsewhere (or when inotify fails) it polls every watchInterval. The globs
+// are expanded again after each change, so that new files are linted and
+// deleted ones dropped, and the setup is reloaded when a config file changes,
+// re-linting all files. files and violations are the result of the initial
+// run, and relint lints the changed files of all with setup.
+func runWatch(opts watchOptions, setup *lintSetup, files []string, violations []fileViolation, relint func(setup *lintSetup, changed, all []string) []fileViolation) {
+	s := &watchSession{
+		watchOptions: opts,
+		relint:       relint,
+		setup:        setup,
+		stamps:       make(map[string]fileStamp),
+		violations:   make(map[string][]lint.Violation),
+	}
+	s.setConfig(setup.cfg, s.findConfig())
+	for _, f := range files {
+		if st, ok := stampFile(f); ok {
+			s.files = append(s.files, f)
+			s.stamps[f] = st
 		}
 	}
+	for _, fv := range violations {
+		s.violations[fv.File] = fv.Violations
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(sigCh)
 
-	fmt.Fprintf(os.Stderr, "Watching %d file(s) for changes... (press Ctrl+C to stop)\n", len(allFiles))
+	w := newDirWatcher()
+	defer func() { _ = w.Close() }()
+	watchDirs := func() {
+		if err := w.Watch(s.dirs()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; checking for changes every %v instead\n", err, watchInterval)
+			_ = w.Close()
+			w = newPollWatcher()
+		}
+	}
+	watchDirs()
 
-	ticker := time.NewTicker(watchInterval)
-	defer ticker.Stop()
+	fmt.Fprintf(os.Stderr, "Watching %d file(s) for changes... (press Ctrl+C to stop)\n", len(s.files))
 
+	var debounce <-chan time.Time
 	for {
 		select {
 		case <-sigCh:
 			return
-		case <-ticker.C:
-			var changed []string
-			for _, f := range allFiles {
-				fi, err := os.Stat(f)
-				if err != nil {
-					continue
-				}
-				if fi.ModTime() != mtimes[f] {
-					mtimes[f] = fi.ModTime()
-					changed = append(changed, f)
-				}
-			}
-			if len(changed) > 0 {
-				relintFn(changed)
+		case <-w.Events():
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			debounce = nil
+			s.update()
+			watchDirs()
+		}
+	}
+}
+
+// findConfig returns the path of the config file to use, "" if none.
+func (s *watchSession) findConfig() string {
+	if s.configPath != "" {
+		return s.configPath
+	}
+	if s.cwd == "" {
+		return ""
+	}
+	return findConfigFile(s.cwd)
+}
+
+// setConfig records the config file at path, loaded as cfg (nil when it
+// could not be loaded), and the stamps of the files it was read from.
+func (s *watchSession) setConfig(cfg *ConfigFile, path string) {
+	s.configFile = path
+	s.configStamps = make(map[string]fileStamp)
+	sources := []string{path}
+	if cfg != nil && len(cfg.sources) > 0 {
+		sources = cfg.sources
+	}
+	for _, src := range sources {
+		if src != "" {
+			st, _ := stampFile(src)
+			s.configStamps[src] = st
+		}
+	}
+}
+
+// configChanged reports whether another config file was discovered or a
+// config file changed since setConfig, including those of the directories
+// below the working directory.
+func (s *watchSession) configChanged() bool {
+	if s.findConfig() != s.configFile {
+		return true
+	}
+	if s.setup.dirs != nil && s.setup.dirs.changed() {
+		return true
+	}
+	for path, old := range s.configStamps {
+		if st, _ := stampFile(path); st != old {
+			return true
+		}
+	}
+	return false
+}
+
+// update reloads the config if it changed, expands the globs again, and
+// re-lints the files that are new or changed.
+func (s *watchSession) update() {
+	reloaded := false
+	if s.configChanged() {
+		cfg, path, err := discoverConfig(s.configPath, s.cwd)
+		var setup *lintSetup
+		if err == nil {
+			setup, err = newLintSetup(cfg, s.cwd, s.configPath == "")
+		}
+		if err != nil {
+			// Keep the previous setup until the config is fixed.
+			fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", path, err)
+			s.setConfig(nil, path)
+		} else {
+			if cfg != nil {
+				printConfigProblems(os.Stderr, cfg.problems)
 			}
+			s.setup = setup
+			s.setConfig(cfg, path)
+			reloaded = true
+		}
+	}
+
+	var files, changed, removed []string
+	current := make(map[string]bool)
+	for _, f := range expandGlobs(s.globs(s.setup), s.setup.ignores) {
+		st, ok := stampFile(f)
+		if !ok || current[f] {
+			continue
+		}
+		current[f] = true
+		files = append(files, f)
+		if old, seen := s.stamps[f]; reloaded || !seen || old != st {
+			changed = append(changed, f)
+		}
+		s.stamps[f] = st
+	}
+	for _, f := range s.files {
+		if !current[f] {
+			removed = append(removed, f)
+			delete(s.stamps, f)
+			delete(s.violations, f)
+		}
+	}
+	s.files = files
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+
+	results := s.relint(s.setup, changed, files)
+	for _, fv := range results {
+		s.violations[fv.File] = fv.Violations
+	}
+	// Fixes made while re-linting are not changes to lint again.
+	for _, f := range changed {
+		if st, ok := stampFile(f); ok {
+			s.stamps[f] = st
+		}
+	}
+	s.report(results, removed, reloaded)
+}
+
+// report prints the outcome of an update: the violations of the re-linted
+// files, or with the clear option the violations of all files.
+func (s *watchSession) report(results []fileViolation, removed []string, reloaded bool) {
+	if !s.clear {
+		if reloaded {
+			fmt.Fprintf(os.Stderr, "Config changed, re-linted %d file(s)\n", len(s.files))
+		}
+		for _, f := range removed {
+			fmt.Fprintf(os.Stderr, "Stopped watching %s (removed)\n", f)
+		}
+		formatDefault(results, os.Stderr)
+		return
+	}
+	all := make([]fileViolation, 0, len(s.files))
+	problems := 0
+	for _, f := range s.files {
+		all = append(all, fileViolation{File: f, Violations: s.violations[f]})
+		problems += len(s.violations[f])
+	}
+	// Move the cursor home and clear the screen.
+	fmt.Fprint(os.Stderr, "\x1b[H\x1b[2J")
+	formatDefault(all, os.Stderr)
+	fmt.Fprintf(os.Stderr, "%d problem(s) in %d file(s); watching for changes... (press Ctrl+C to stop)\n", problems, len(s.files))
+}
+
+// dirs returns the directories to watch: those the globs can match files
+// in, the working directory, and those holding the config files.
+func (s *watchSession) dirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if dir != "" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, glob := range s.globs(s.setup) {
+		if glob == "-" {
+			continue
+		}
+		base, pattern := doublestar.SplitPattern(filepath.ToSlash(glob))
+		base = filepath.FromSlash(base)
+		if !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**") {
+			add(base)
+			continue
 		}
+		_ = filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if path != base && (d.Name() == ".git" || isIgnored(path, s.setup.ignores)) {
+				return filepath.SkipDir
+			}
+			add(path)
+			return nil
+		})
+	}
+	add(s.cwd)
+	for path := range s.configStamps {
+		add(filepath.Dir(path))
 	}
+	return dirs
 }
@@ -0,0 +1,91 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// inotifyMask selects the inotify events that can change which files the
+// globs match or what they contain.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher is the dirWatcher used on Linux.
+type inotifyWatcher struct {
+	fd     int
+	file   *os.File // wraps fd, so that Close interrupts a pending read
+	events chan struct{}
+
+	mu      sync.Mutex
+	watches map[string]int // watch descriptors by directory
+}
+
+// newDirWatcher returns an inotifyWatcher, or a pollWatcher if inotify is
+// not available.
+func newDirWatcher() dirWatcher {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: inotify: %v; checking for changes every %v instead\n", err, watchInterval)
+		return newPollWatcher()
+	}
+	w := &inotifyWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		events:  make(chan struct{}, 1),
+		watches: make(map[string]int),
+	}
+	go w.read()
+	return w
+}
+
+// read forwards inotify events until the watcher is closed. The events
+// themselves are not decoded: the watch loop compares the files instead.
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		if _, err := w.file.Read(buf); err != nil {
+			return
+		}
+		select {
+		case w.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *inotifyWatcher) Watch(dirs []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watches := make(map[string]int, len(dirs))
+	inUse := make(map[int]bool, len(dirs))
+	for _, dir := range dirs {
+		// Adding a watch again is cheap, and picks up directories that were
+		// removed and created again.
+		wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+		if err == syscall.ENOSPC {
+			return fmt.Errorf("inotify: too many watches (raise fs.inotify.max_user_watches)")
+		}
+		if err != nil {
+			// The directory may have been removed in the meantime.
+			continue
+		}
+		watches[dir] = wd
+		inUse[wd] = true
+	}
+	// Paths of the same directory share a watch descriptor.
+	for _, wd := range w.watches {
+		if !inUse[wd] {
+			_, _ = syscall.InotifyRmWatch(w.fd, uint32(wd))
+		}
+	}
+	w.watches = watches
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan struct{} { return w.events }
+func (w *inotifyWatcher) Close() error            { return w.file.Close() }
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// newDirWatcher returns a pollWatcher: event-based watching is only
+// implemented for Linux.
+func newDirWatcher() dirWatcher {
+	return newPollWatcher()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a strings.Builder that is safe to write from a running
+// process while the test reads it.
+type syncBuffer struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+// startWatch starts bin with args in dir and waits until it is watching.
+// The process is interrupted when the test ends.
+func startWatch(t *testing.T, bin, dir string, args ...string) *syncBuffer {
+	t.Helper()
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	stderr := &syncBuffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start --watch process: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("--watch process: %v", err)
+		}
+	})
+	waitForOutput(t, stderr, "Watching")
+	return stderr
+}
+
+// waitForOutput waits until out contains want.
+func waitForOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q; stderr: %s", want, out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCLI_Watch_FollowsFilesAndConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signal-based test not supported on Windows")
+	}
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stderr := startWatch(t, bin, dir, "--watch", "--no-cache", "docs/**/*.md", "*.md")
+
+	// A file created after startup, in a directory created after startup.
+	if err := os.MkdirAll(filepath.Join(dir, "docs", "guide"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * watchDebounce)
+	newFile := filepath.Join(dir, "docs", "guide", "new.md")
+	if err := os.WriteFile(newFile, []byte("# New\n\nTrailing   \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "new.md:3:9 MD009")
+
+	// A config file created after startup applies to all files.
+	if err := os.WriteFile(filepath.Join(dir, ".markdownlint-cli2.yaml"), []byte("config:\n  MD009: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "Config changed, re-linted 2 file(s)")
+	if got := strings.Count(stderr.String(), "MD009"); got != 1 {
+		t.Errorf("MD009 reported %d times, want once before the config change; stderr: %s", got, stderr.String())
+	}
+
+	// Deleted files are dropped.
+	if err := os.Remove(newFile); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "Stopped watching docs/guide/new.md (removed)")
+}
+
+func TestCLI_Watch_InvalidConfigKeepsSetup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signal-based test not supported on Windows")
+	}
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, ".markdownlint-cli2.yaml")
+	if err := os.WriteFile(cfgFile, []byte("config:\n  MD009: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mdFile := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(mdFile, []byte("# A\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stderr := startWatch(t, bin, dir, "--watch", "--no-cache", "*.md")
+
+	if err := os.WriteFile(cfgFile, []byte("config: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "Error loading config")
+
+	// The previous config stays in effect: MD009 remains disabled.
+	if err := os.WriteFile(mdFile, []byte("# A\n\nTrailing   \nNo final newline"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "a.md:4:17 MD047")
+	if strings.Contains(stderr.String(), "MD009") {
+		t.Errorf("MD009 reported after an invalid config change; stderr: %s", stderr.String())
+	}
+}
+
+func TestCLI_WatchClear(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signal-based test not supported on Windows")
+	}
+	bin := buildBinary(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.md": "# A\n\nTrailing   \n",
+		"b.md": "# B\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stderr := startWatch(t, bin, dir, "--watch", "--watch-clear", "--no-cache", "*.md")
+
+	// Changing b.md redraws the violations of a.md as well.
+	if err := os.WriteFile(filepath.Join(dir, "b.md"), []byte("# B\n\nMore   \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, stderr, "2 problem(s) in 2 file(s)")
+	screen := stderr.String()
+	screen = screen[strings.LastIndex(screen, "\x1b[2J"):]
+	for _, want := range []string{"a.md:3:9 MD009", "b.md:3:5 MD009"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("redrawn screen missing %q; got: %s", want, screen)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package lint
+
+import "bytes"
+
+// lineHunk is a change between two versions of a document: the lines
+// a[aStart:aEnd] of the old version replaced by b[bStart:bEnd] of the new.
+type lineHunk struct {
+	aStart, aEnd int
+	bStart, bEnd int
+}
+
+// splitLinesAfter splits source into lines that keep their line endings, so
+// that joining them gives back source. Unlike splitLines, it returns no empty
+// line after a final newline.
+func splitLinesAfter(source []byte) []string {
+	var lines []string
+	for len(source) > 0 {
+		i := bytes.IndexByte(source, '\n') + 1
+		if i == 0 {
+			i = len(source)
+		}
+		lines = append(lines, string(source[:i]))
+		source = source[i:]
+	}
+	return lines
+}
+
+// diffLines returns the hunks that turn the lines a into the lines b, in
+// order, using the linear space variant of Myers' difference algorithm.
+func diffLines(a, b []string) []lineHunk {
+	d := &lineDiff{
+		a:        a,
+		b:        b,
+		deleted:  make([]bool, len(a)),
+		inserted: make([]bool, len(b)),
+	}
+	d.compare(0, len(a), 0, len(b))
+
+	var hunks []lineHunk
+	n, m := len(a), len(b)
+	for i, j := 0, 0; i < n || j < m; {
+		if i < n && j < m && !d.deleted[i] && !d.inserted[j] {
+			i++
+			j++
+			continue
+		}
+		h := lineHunk{aStart: i, bStart: j}
+		for i < n && d.deleted[i] || j < m && d.inserted[j] {
+			for i < n && d.deleted[i] {
+				i++
+			}
+			for j < m && d.inserted[j] {
+				j++
+			}
+		}
+		h.aEnd, h.bEnd = i, j
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+// lineDiff is the state of diffLines: the lines it compares, the lines of a
+// that the shortest edit script deletes and those of b that it inserts.
+type lineDiff struct {
+	a, b              []string
+	deleted, inserted []bool
+	// forward and backward hold the furthest reaching x of each diagonal
+	// in bisect. They are shared by all its calls, which do not overlap.
+	forward, backward []int
+}
+
+// compare marks the lines that turn a[aLo:aHi] into b[bLo:bHi]. It splits
+// the ranges at a point of a shortest edit script and compares both halves,
+// so that it needs memory linear in the number of lines rather than in the
+// number of lines times the number of changes.
+func (d *lineDiff) compare(aLo, aHi, bLo, bHi int) {
+	// Common leading and trailing lines are not part of any hunk; leaving
+	// them out keeps the search small, as fixes tend to change few lines.
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := d.bisect(aLo, aHi, bLo, bHi); ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+			return
+		}
+	}
+	for i := aLo; i < aHi; i++ {
+		d.deleted[i] = true
+	}
+	for j := bLo; j < bHi; j++ {
+		d.inserted[j] = true
+	}
+}
+
+// bisect returns the point (x, y) where a shortest edit script of
+// a[aLo:aHi] and b[bLo:bHi] is split in the middle, found by searching from
+// both ends at once until the paths overlap. It reports false when the
+// ranges have no line in common.
+func (d *lineDiff) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset, size := maxD, 2*maxD+1
+	if cap(d.forward) < size {
+		d.forward = make([]int, size)
+		d.backward = make([]int, size)
+	}
+	forward, backward := d.forward[:size], d.backward[:size]
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	// The paths can only meet in the forward search when delta is odd,
+	// and in the backward search when it is even. Diagonals that run off
+	// the edge of the graph are not followed any further.
+	delta := n - m
+	odd := delta%2 != 0
+	var fStart, fEnd, bStart, bEnd int
+	for step := 0; step < maxD; step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			var x1 int
+			if k == -step || k != step && forward[offset+k-1] < forward[offset+k+1] {
+				x1 = forward[offset+k+1]
+			} else {
+				x1 = forward[offset+k-1] + 1
+			}
+			y1 := x1 - k
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			forward[offset+k] = x1
+			switch {
+			case x1 > n:
+				fEnd += 2
+			case y1 > m:
+				fStart += 2
+			case odd:
+				if i := offset + delta - k; i >= 0 && i < len(backward) && backward[i] != -1 && x1 >= n-backward[i] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			var x2 int
+			if k == -step || k != step && backward[offset+k-1] < backward[offset+k+1] {
+				x2 = backward[offset+k+1]
+			} else {
+				x2 = backward[offset+k-1] + 1
+			}
+			y2 := x2 - k
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			backward[offset+k] = x2
+			switch {
+			case x2 > n:
+				bEnd += 2
+			case y2 > m:
+				bStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < len(forward) && forward[i] != -1 && forward[i] >= n-x2 {
+					x1 := forward[i]
+					return aLo + x1, bLo + x1 - (delta - k), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// keepSuppressed returns fixed, a rewrite of source, with the changes to the
+// lines of source for which suppressed reports true undone. Lines are
+// numbered from 0. A change that only inserts lines is undone when a line
+// next to the insertion is suppressed.
+func keepSuppressed(source, fixed []byte, suppressed func(line int) bool) []byte {
+	a, b := splitLinesAfter(source), splitLinesAfter(fixed)
+	var out bytes.Buffer
+	i := 0
+	for _, h := range diffLines(a, b) {
+		for ; i < h.aStart; i++ {
+			out.WriteString(a[i])
+		}
+		keep := h.aStart == h.aEnd && (suppressed(h.aStart-1) || suppressed(h.aStart))
+		for line := h.aStart; line < h.aEnd && !keep; line++ {
+			keep = suppressed(line)
+		}
+		lines := b[h.bStart:h.bEnd]
+		if keep {
+			lines = a[h.aStart:h.aEnd]
+		}
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+		i = h.aEnd
+	}
+	for ; i < len(a); i++ {
+		out.WriteString(a[i])
+	}
+	return out.Bytes()
+}
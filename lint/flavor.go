@@ -0,0 +1,99 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// DefaultFlavor is the flavor documents are parsed as when Linter.Flavor is
+// empty.
+const DefaultFlavor = "gfm"
+
+// Names of the goldmark extensions a flavor can enable.
+const (
+	ExtTable           = "table"
+	ExtStrikethrough   = "strikethrough"
+	ExtTaskList        = "tasklist"
+	ExtLinkify         = "linkify"
+	ExtFootnotes       = "footnotes"
+	ExtDefinitionLists = "definition-lists"
+	ExtTypographer     = "typographer"
+	ExtAttributes      = "attributes"
+	ExtCJK             = "cjk"
+)
+
+// flavors maps the base flavor names to the extensions they enable.
+var flavors = map[string][]string{
+	"commonmark": nil,
+	"gfm":        {ExtTable, ExtStrikethrough, ExtTaskList, ExtLinkify},
+	"php-extra":  {ExtTable, ExtFootnotes, ExtDefinitionLists, ExtAttributes},
+}
+
+// extensionOptions maps extension names to the goldmark options that enable
+// them.
+var extensionOptions = map[string]goldmark.Option{
+	ExtTable:           goldmark.WithExtensions(extension.Table),
+	ExtStrikethrough:   goldmark.WithExtensions(extension.Strikethrough),
+	ExtTaskList:        goldmark.WithExtensions(extension.TaskList),
+	ExtLinkify:         goldmark.WithExtensions(extension.Linkify),
+	ExtFootnotes:       goldmark.WithExtensions(extension.Footnote),
+	ExtDefinitionLists: goldmark.WithExtensions(extension.DefinitionList),
+	ExtTypographer:     goldmark.WithExtensions(extension.Typographer),
+	ExtAttributes:      goldmark.WithParserOptions(parser.WithAttribute()),
+	ExtCJK:             goldmark.WithExtensions(extension.CJK),
+}
+
+// ParseFlavor returns the set of extensions enabled by flavor: a base flavor
+// ("commonmark", "gfm", or "php-extra") optionally followed by extensions
+// joined with "+", such as "gfm+footnotes" or "commonmark+table+footnotes".
+// An empty flavor means DefaultFlavor.
+func ParseFlavor(flavor string) (map[string]bool, error) {
+	if flavor == "" {
+		flavor = DefaultFlavor
+	}
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(flavor)), "+")
+	base, ok := flavors[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown flavor %q (want one of %s)", parts[0], strings.Join(sortedKeys(flavors), ", "))
+	}
+	extensions := make(map[string]bool)
+	for _, name := range base {
+		extensions[name] = true
+	}
+	for _, name := range parts[1:] {
+		if _, ok := extensionOptions[name]; !ok {
+			return nil, fmt.Errorf("unknown extension %q in flavor %q (want one of %s)", name, flavor, strings.Join(sortedKeys(extensionOptions), ", "))
+		}
+		extensions[name] = true
+	}
+	return extensions, nil
+}
+
+// markdown returns a goldmark instance for l.Flavor, along with the set of
+// enabled extensions. An invalid flavor falls back to DefaultFlavor.
+func (l *Linter) markdown() (goldmark.Markdown, map[string]bool) {
+	extensions, err := ParseFlavor(l.Flavor)
+	if err != nil {
+		extensions, _ = ParseFlavor(DefaultFlavor)
+	}
+	var options []goldmark.Option
+	for _, name := range sortedKeys(extensions) {
+		options = append(options, extensionOptions[name])
+	}
+	return goldmark.New(options...), extensions
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,214 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Front matter formats, as reported by Document.FrontMatterFormat.
+const (
+	FrontMatterYAML = "yaml"
+	FrontMatterTOML = "toml"
+)
+
+// FrontMatterError describes a syntax error in a document's front matter.
+type FrontMatterError struct {
+	Line    int // 1-based line in the document
+	Message string
+}
+
+func (e *FrontMatterError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// frontMatter is the result of parsing a front matter block.
+type frontMatter struct {
+	tree     map[string]interface{}
+	format   string
+	err      *FrontMatterError
+	keyLines map[string]int // see Document.FrontMatterKeyLines
+}
+
+// parseFrontMatter parses the front matter block, the first end bytes of
+// source. Blocks delimited by "---" are read as YAML and blocks delimited by
+// "+++" as TOML. Blocks matched by a custom front matter pattern with other
+// delimiters are read as YAML on a best-effort basis: their syntax errors are
+// not reported, as they may not be YAML at all.
+func parseFrontMatter(source []byte, end int) frontMatter {
+	if end == 0 {
+		return frontMatter{}
+	}
+	block := source[:end]
+	first := bytes.IndexByte(block, '\n')
+	if first < 0 {
+		return frontMatter{}
+	}
+	delimiter := string(bytes.TrimRight(block[:first], "\r"))
+	// The body runs from the line after the opening delimiter to the start of
+	// the closing one.
+	body := bytes.TrimRight(block[first+1:], "\r\n")
+	if last := bytes.LastIndexByte(body, '\n'); last >= 0 {
+		body = body[:last+1]
+	} else {
+		body = nil
+	}
+
+	fm := frontMatter{keyLines: make(map[string]int)}
+	switch delimiter {
+	case "+++":
+		fm.tree, fm.err = parseTOML(string(body), fm.keyLines)
+		fm.format = FrontMatterTOML
+	default:
+		fm.tree, fm.err = parseYAMLFrontMatter(body, fm.keyLines)
+		fm.format = FrontMatterYAML
+		if delimiter != "---" && fm.err != nil {
+			return frontMatter{}
+		}
+	}
+	// The body starts on the line after the opening delimiter.
+	if fm.err != nil {
+		fm.err.Line++
+		return frontMatter{format: fm.format, err: fm.err}
+	}
+	for path := range fm.keyLines {
+		fm.keyLines[path]++
+	}
+	return fm
+}
+
+// yamlErrorLineRE extracts the line number and message from a YAML error.
+var yamlErrorLineRE = regexp.MustCompile(`line (\d+): (.*)`)
+
+// parseYAMLFrontMatter parses a YAML front matter body, recording the lines
+// of its keys in keyLines. Errors and keyLines report 1-based lines of body.
+func parseYAMLFrontMatter(body []byte, keyLines map[string]int) (map[string]interface{}, *FrontMatterError) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(body, &node); err != nil {
+		fmErr := &FrontMatterError{Line: 1, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlErrorLineRE.FindStringSubmatch(err.Error()); m != nil {
+			fmErr.Line, _ = strconv.Atoi(m[1])
+			fmErr.Message = m[2]
+		}
+		return nil, fmErr
+	}
+	if len(node.Content) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	root := node.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &FrontMatterError{Line: root.Line, Message: "front matter must be a mapping of keys to values"}
+	}
+	c := &yamlConverter{keyLines: keyLines, expanding: make(map[*yaml.Node]bool)}
+	value, err := c.value(root, "")
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]interface{}), nil
+}
+
+// maxFrontMatterValues is the number of values, counting each expansion of an
+// alias anew, above which YAML front matter is rejected, so that a small block
+// of nested aliases cannot make linting a document take unbounded time.
+const maxFrontMatterValues = 100000
+
+// yamlConverter converts YAML nodes to front matter values, recording the
+// lines of their keys in keyLines.
+type yamlConverter struct {
+	keyLines  map[string]int
+	expanding map[*yaml.Node]bool // anchored nodes whose alias is being expanded
+	values    int                 // values converted so far
+}
+
+// value converts a YAML node at path to the value types used for front
+// matter: mappings become map[string]interface{} with their keys as strings,
+// and timestamps are kept as strings, as they are for TOML front matter.
+func (c *yamlConverter) value(node *yaml.Node, path string) (interface{}, *FrontMatterError) {
+	c.values++
+	if c.values > maxFrontMatterValues {
+		return nil, &FrontMatterError{Line: node.Line, Message: fmt.Sprintf("front matter has more than %d values after expanding aliases", maxFrontMatterValues)}
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		if c.expanding[node.Alias] {
+			return nil, &FrontMatterError{Line: node.Line, Message: fmt.Sprintf("anchor %q is used within itself", node.Value)}
+		}
+		c.expanding[node.Alias] = true
+		defer delete(c.expanding, node.Alias)
+		return c.value(node.Alias, path)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinKeyPath(path, key.Value)
+			v, err := c.value(value, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			// Merge keys (<<: *base) copy the keys of the referenced
+			// mapping that are not set explicitly.
+			if key.Tag == "!!merge" {
+				if base, ok := v.(map[string]interface{}); ok {
+					for k, bv := range base {
+						if _, set := m[k]; !set {
+							m[k] = bv
+						}
+					}
+				}
+				continue
+			}
+			m[key.Value] = v
+			c.keyLines[keyPath] = key.Line
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]interface{}, 0, len(node.Content))
+		for i, item := range node.Content {
+			itemPath := joinKeyPath(path, strconv.Itoa(i))
+			v, err := c.value(item, itemPath)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			c.keyLines[itemPath] = item.Line
+		}
+		return s, nil
+	}
+	switch node.ShortTag() {
+	case "!!str", "!!timestamp", "!!binary":
+		return node.Value, nil
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, &FrontMatterError{Line: node.Line, Message: err.Error()}
+	}
+	return v, nil
+}
+
+// joinKeyPath appends key to the dotted key path path.
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// frontMatterFields returns the scalar values at the top level of fm as
+// strings.
+func frontMatterFields(fm map[string]interface{}) map[string]string {
+	fields := make(map[string]string, len(fm))
+	for k, v := range fm {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+		case nil:
+			fields[k] = ""
+		default:
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
@@ -2,14 +2,14 @@ package lint
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
 
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 )
@@ -27,6 +27,74 @@ type FixableRule interface {
 	Fix(source []byte) []byte
 }
 
+// EditFixableRule is an optional interface for rules whose Check method
+// attaches Edits to the violations it reports. Linter.Fix applies those edits
+// instead of calling FixableRule.Fix, which lets several rules fix the same
+// document in one pass without clobbering each other's changes.
+type EditFixableRule interface {
+	Rule
+	// FixesWithEdits reports whether Check populates Violation.Fix.
+	FixesWithEdits() bool
+}
+
+// ProjectRule is an optional interface for rules that check relationships
+// between documents, such as links from one file to another. Linter.Lint only
+// calls Check, which sees a single document; LintProject calls CheckProject
+// once with every document of a lint run.
+type ProjectRule interface {
+	Rule
+	// CheckProject returns the violations found in docs, keyed by the Path
+	// of the document they belong to.
+	CheckProject(docs []*Document) map[string][]Violation
+}
+
+// SuppressionRule is an optional interface for rules that check the
+// markdownlint inline disable comments of a document rather than its content.
+// Linter.Lint calls CheckSuppressions after running the other rules, once it
+// knows which comments suppressed a violation.
+type SuppressionRule interface {
+	Rule
+	CheckSuppressions(doc *Document, suppressions []Suppression) []Violation
+}
+
+// Suppression is a markdownlint inline comment that disables rules:
+// markdownlint-disable, disable-line, disable-next-line, or disable-file.
+type Suppression struct {
+	Line    int    // 1-based line of the comment
+	Column  int    // 1-based column of the comment
+	Command string // "disable", "disable-line", "disable-next-line", or "disable-file"
+	// Rules are the rules the comment names; it disables all rules if empty.
+	Rules []SuppressedRule
+	// Reason is the text after "--" in the comment, as in
+	// <!-- markdownlint-disable MD033 -- embedded video -->.
+	Reason string
+	// Unused reports whether a comment that names no rules suppressed no
+	// violation. It is false when the linter runs project rules, whose
+	// violations Lint does not see.
+	Unused bool
+	// Unclosed reports whether a markdownlint-disable comment is still in
+	// effect at the end of the document, with no enable comment to end it.
+	Unclosed bool
+
+	used bool
+}
+
+// SuppressedRule is a rule named by a Suppression.
+type SuppressedRule struct {
+	Name string // as written in the comment
+	ID   string // canonical rule ID; Name upper-cased if no rule matches
+	// Known reports whether the rule is one the linter runs or can build
+	// with BuildRule, such as a rule its config disables.
+	Known bool
+	// Unused reports whether the comment suppressed no violation of the
+	// rule. It is false for rules whose violations Lint does not see:
+	// rules the linter does not run, ProjectRule and SuppressionRule
+	// implementations.
+	Unused bool
+
+	used bool
+}
+
 // AliasedRule is an optional interface for rules that have human-readable
 // aliases (e.g. "heading-increment" for MD001), matching markdownlint aliases.
 type AliasedRule interface {
@@ -34,35 +102,102 @@ type AliasedRule interface {
 	Aliases() []string
 }
 
+// TaggedRule is an optional interface for rules that belong to groups named by
+// tags (e.g. "headings" for MD001), matching markdownlint tags, so that a
+// config can enable or disable a group of rules at once.
+type TaggedRule interface {
+	Rule
+	Tags() []string
+}
+
 // Violation represents a lint violation found in a document.
 type Violation struct {
-	Rule     string
-	Line     int
-	Column   int
-	Message  string
-	Severity string // "error" or "warning"; defaults to "error" when empty
+	Rule   string
+	Line   int
+	Column int
+	// EndLine and EndColumn locate the end of the offending text; EndColumn
+	// is the 1-based column just past its last byte. Rules that leave them
+	// zero get a range extending to the end of Line.
+	EndLine   int `json:",omitempty"`
+	EndColumn int `json:",omitempty"`
+	Message   string
+	Severity  string // "error", "warning" or "info"; defaults to "error" when empty
+	// Fix lists the edits that resolve this violation, if the rule can fix it.
+	// Offsets refer to the source passed to Linter.Lint. The edits of a single
+	// violation never overlap each other.
+	Fix []Edit `json:",omitempty"`
+}
+
+// Edit replaces the bytes in the half-open range [Start, End) of a document
+// with NewText. Start == End denotes an insertion.
+type Edit struct {
+	Start   int
+	End     int
+	NewText string
+}
+
+// overlaps reports whether e and o touch the same bytes. Two insertions at the
+// same offset also overlap, since their relative order would be ambiguous.
+func (e Edit) overlaps(o Edit) bool {
+	if e.Start == e.End && o.Start == o.End {
+		return e.Start == o.Start
+	}
+	return e.Start < o.End && o.Start < e.End
 }
 
 // Document holds the parsed markdown document along with source.
 type Document struct {
+	// Path is the file the document was read from, as passed to LintProject.
+	// It is empty for documents linted with Lint.
+	Path              string
 	Source            []byte
 	Lines             []string
 	AST               ast.Node
-	FrontMatterFields map[string]string // key-value pairs from YAML front matter, if any
+	FrontMatterFields map[string]string // top-level scalar values of FrontMatter as strings
 	FrontMatterLines  int               // number of lines occupied by front matter (0 if none)
+	// FrontMatter is the parsed YAML or TOML front matter, if any. Mappings
+	// and tables are map[string]interface{} and lists []interface{}; dates
+	// and times are kept as strings.
+	FrontMatter map[string]interface{}
+	// FrontMatterFormat is FrontMatterYAML or FrontMatterTOML, or empty for
+	// documents without front matter.
+	FrontMatterFormat string
+	// FrontMatterError is the syntax error in the front matter, if any. The
+	// GL002 rule reports it.
+	FrontMatterError *FrontMatterError
+	// FrontMatterKeyLines maps the dotted path of each key in FrontMatter,
+	// such as "params.author", to its 1-based line in the document. List
+	// elements are numbered from 0, as in "tags.1"; TOML arrays and inline
+	// tables have no entries for their contents.
+	FrontMatterKeyLines map[string]int
 	// LinkRefs maps a normalised link label to its destination URL as parsed by
 	// goldmark. It covers all link reference definitions in the document and is
 	// derived from the goldmark parser context rather than a hand-rolled regex,
 	// so it correctly handles angle-bracket destinations, title-on-next-line, etc.
 	LinkRefs map[string][]byte
+	// Extensions is the set of goldmark extensions the document was parsed
+	// with, by name (ExtTable, ExtFootnotes, ...). Rules can use it to tell
+	// whether syntax such as footnotes appears as its own AST nodes.
+	Extensions map[string]bool
 }
 
 // Linter holds the list of rules and runs them on documents.
 type Linter struct {
-	Rules              []Rule
-	aliasMap           map[string]string // upper(alias) → canonical rule ID
-	NoInlineConfig     bool
-	FrontMatterRegexp  *regexp.Regexp    // custom front matter pattern; nil uses default
+	Rules             []Rule
+	aliasMap          map[string]string // upper(alias) → canonical rule ID
+	NoInlineConfig    bool
+	FrontMatterRegexp *regexp.Regexp // custom front matter pattern; nil uses default
+	MaxFixPasses      int            // pass limit for FixUntilStable; 0 uses DefaultMaxFixPasses
+	// Flavor selects the Markdown dialect documents are parsed as; see
+	// ParseFlavor. Empty means DefaultFlavor.
+	Flavor string
+	// BuildRule creates the rule named id (an ID or alias) with options, an
+	// object from a markdownlint config map; nil options mean the default
+	// settings. When set, markdownlint-configure-file comments that give a
+	// rule an options object, or enable a rule the linter does not run,
+	// rebuild that rule for the document. Without it only true and false
+	// values are honoured.
+	BuildRule func(id string, options map[string]interface{}) (Rule, error)
 }
 
 // NewLinter creates a new Linter with the given rules.
@@ -90,7 +225,8 @@ func (l *Linter) resolveRuleID(name string) string {
 }
 
 // fmEnd returns the byte offset of the end of the front matter block in source,
-// using the custom FrontMatterRegexp if set, otherwise the default YAML --- detection.
+// using the custom FrontMatterRegexp if set, otherwise the default detection of
+// YAML (---) and TOML (+++) front matter.
 func (l *Linter) fmEnd(source []byte) int {
 	if l.FrontMatterRegexp != nil {
 		loc := l.FrontMatterRegexp.FindIndex(source)
@@ -102,23 +238,450 @@ func (l *Linter) fmEnd(source []byte) int {
 	return frontMatterEnd(source)
 }
 
+// fixesWithEdits reports whether rule attaches edits to its violations.
+func fixesWithEdits(rule Rule) bool {
+	er, ok := rule.(EditFixableRule)
+	return ok && er.FixesWithEdits()
+}
+
 // Fix applies all fixable rules to source and returns the corrected content.
-// Front matter is preserved unchanged.
+// Rules implementing EditFixableRule are fixed first, in a single pass whose
+// non-overlapping edits are merged; the remaining FixableRule implementations
+// then rewrite the document in registration order.
+// Front matter is preserved unchanged, and so are the lines on which inline
+// comments disable a rule: edits reported there are dropped, and the changes
+// a FixableRule makes to them are undone.
+//
+// A fix can introduce a violation of a rule that has already run; use
+// FixUntilStable to repeat the pass until the output stops changing.
 func (l *Linter) Fix(source []byte) []byte {
+	fixed, _ := l.fixPass(source)
+	return fixed
+}
+
+// DefaultMaxFixPasses is the number of passes FixUntilStable makes when
+// Linter.MaxFixPasses is not set.
+const DefaultMaxFixPasses = 10
+
+// FixNotConvergedError is returned by FixUntilStable when the fixes keep
+// changing the document.
+type FixNotConvergedError struct {
+	Passes int      // number of passes made
+	Cycle  bool     // true when a pass reproduced the output of an earlier one
+	Rules  []string // IDs of the rules that changed the output in the last pass
+}
+
+func (e *FixNotConvergedError) Error() string {
+	reason := fmt.Sprintf("still changing after %d passes", e.Passes)
+	if e.Cycle {
+		reason = fmt.Sprintf("cycling after %d passes", e.Passes)
+	}
+	return fmt.Sprintf("fixes did not converge (%s); rules changing the output: %s",
+		reason, strings.Join(e.Rules, ", "))
+}
+
+// FixUntilStable applies Fix repeatedly until a pass leaves the document
+// unchanged, so that violations introduced by one rule's fix are fixed by the
+// rules that already ran. It gives up after MaxFixPasses passes or as soon as
+// a pass reproduces an earlier output, returning the last output together
+// with a *FixNotConvergedError naming the rules that kept changing it.
+func (l *Linter) FixUntilStable(source []byte) ([]byte, error) {
+	maxPasses := l.MaxFixPasses
+	if maxPasses <= 0 {
+		maxPasses = DefaultMaxFixPasses
+	}
+	seen := map[[sha256.Size]byte]bool{sha256.Sum256(source): true}
+	var changed []string
+	for pass := 1; pass <= maxPasses; pass++ {
+		var fixed []byte
+		fixed, changed = l.fixPass(source)
+		if bytes.Equal(fixed, source) {
+			return fixed, nil
+		}
+		source = fixed
+		sum := sha256.Sum256(source)
+		if seen[sum] {
+			return source, &FixNotConvergedError{Passes: pass, Cycle: true, Rules: changed}
+		}
+		seen[sum] = true
+	}
+	return source, &FixNotConvergedError{Passes: maxPasses, Rules: changed}
+}
+
+// fixPass performs a single Fix pass over source and returns the result
+// along with the IDs of the rules that changed it, in registration order.
+func (l *Linter) fixPass(source []byte) ([]byte, []string) {
 	fmEnd := l.fmEnd(source)
+	docRules := l.documentRules(source[fmEnd:])
+	var editRules []Rule
+	for _, rule := range docRules {
+		if fixesWithEdits(rule) {
+			editRules = append(editRules, rule)
+		}
+	}
+	changedRules := make(map[string]bool)
+	if len(editRules) > 0 {
+		doc, offset := l.parse(source)
+		var disabled []disableSet
+		if !l.NoInlineConfig {
+			disabled, _ = parseInlineDisables(doc.Lines, l.resolveRuleID)
+		}
+		var applied []Violation
+		source, applied = applyFixes(source, l.check(doc, offset, editRules, disabled))
+		for _, v := range applied {
+			changedRules[v.Rule] = true
+		}
+	}
+
+	fmEnd = l.fmEnd(source)
 	rest := source[fmEnd:]
-	for _, rule := range l.Rules {
+	for _, rule := range docRules {
+		if fixesWithEdits(rule) {
+			continue
+		}
 		if fixable, ok := rule.(FixableRule); ok {
-			rest = fixable.Fix(rest)
+			fixed := l.fixRule(fixable, rest)
+			if !bytes.Equal(fixed, rest) {
+				changedRules[rule.ID()] = true
+			}
+			rest = fixed
+		}
+	}
+
+	var changed []string
+	for _, rule := range docRules {
+		if changedRules[rule.ID()] {
+			changed = append(changed, rule.ID())
+		}
+	}
+	return append(source[:fmEnd:fmEnd], rest...), changed
+}
+
+// fixRule applies the Fix method of rule to source, which must not include
+// front matter, and undoes its changes to the lines on which inline comments
+// disable the rule.
+func (l *Linter) fixRule(rule FixableRule, source []byte) []byte {
+	fixed := rule.Fix(source)
+	if l.NoInlineConfig || bytes.Equal(fixed, source) {
+		return fixed
+	}
+	disabled, _ := parseInlineDisables(splitLines(source), l.resolveRuleID)
+	suppressed := func(line int) bool {
+		return line >= 0 && line < len(disabled) && disabled[line].contains(rule.ID())
+	}
+	for line := range disabled {
+		if suppressed(line) {
+			return keepSuppressed(source, fixed, suppressed)
+		}
+	}
+	return fixed
+}
+
+// FixWithEdits runs rule on source and applies the edits attached to its
+// violations. EditFixableRule implementations can use it to provide the
+// FixableRule.Fix method.
+func FixWithEdits(rule Rule, source []byte) []byte {
+	l := NewLinter(rule)
+	doc, offset := l.parse(source)
+	fixed, _ := ApplyFixes(source, l.check(doc, offset, l.Rules, nil))
+	return fixed
+}
+
+// ApplyFixes applies the Fix edits of violations to source and returns the
+// result along with the number of violations that were fixed. The edits of a
+// violation are applied all together or not at all: a violation is skipped
+// when any of its edits overlaps an edit of an earlier violation. Skipped
+// violations are typically resolved by linting and fixing the result again.
+func ApplyFixes(source []byte, violations []Violation) ([]byte, int) {
+	fixed, applied := applyFixes(source, violations)
+	return fixed, len(applied)
+}
+
+// applyFixes implements ApplyFixes, returning the violations that were fixed.
+func applyFixes(source []byte, violations []Violation) ([]byte, []Violation) {
+	var accepted []Edit // kept sorted by Start
+	conflicts := func(e Edit) bool {
+		if e.Start < 0 || e.End < e.Start || e.End > len(source) {
+			return true
+		}
+		// Accepted edits do not overlap each other, so on the left only the
+		// closest one can reach e; on the right, scan until past e.End.
+		i := sort.Search(len(accepted), func(i int) bool { return accepted[i].Start >= e.Start })
+		if i > 0 && e.overlaps(accepted[i-1]) {
+			return true
+		}
+		for ; i < len(accepted) && accepted[i].Start <= e.End; i++ {
+			if e.overlaps(accepted[i]) {
+				return true
+			}
 		}
+		return false
+	}
+	var applied []Violation
+	for _, v := range violations {
+		if len(v.Fix) == 0 {
+			continue
+		}
+		ok := true
+		for _, e := range v.Fix {
+			if conflicts(e) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		for _, e := range v.Fix {
+			i := sort.Search(len(accepted), func(i int) bool { return accepted[i].Start >= e.Start })
+			accepted = append(accepted, Edit{})
+			copy(accepted[i+1:], accepted[i:])
+			accepted[i] = e
+		}
+		applied = append(applied, v)
+	}
+	return ApplyEdits(source, accepted), applied
+}
+
+// ApplyEdits returns source with edits applied. The edits must not overlap;
+// they may be given in any order. source itself is never modified.
+func ApplyEdits(source []byte, edits []Edit) []byte {
+	if len(edits) == 0 {
+		return source
+	}
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var b bytes.Buffer
+	b.Grow(len(source))
+	pos := 0
+	for _, e := range sorted {
+		b.Write(source[pos:e.Start])
+		b.WriteString(e.NewText)
+		pos = e.End
 	}
-	return append(source[:fmEnd:fmEnd], rest...)
+	b.Write(source[pos:])
+	return b.Bytes()
 }
 
 // Lint parses source and runs all rules on it, returning violations sorted by line.
 func (l *Linter) Lint(source []byte) []Violation {
+	doc, offset := l.parse(source)
+
+	var disabled []disableSet
+	var suppressions []*Suppression
+	if !l.NoInlineConfig {
+		disabled, suppressions = parseInlineDisables(doc.Lines, l.resolveRuleID)
+	}
+
+	rules := l.documentRules(doc.Source)
+	violations := l.check(doc, offset, rules, disabled)
+	violations = append(violations, checkSuppressions(doc, offset, rules, disabled, suppressions, l.canBuild)...)
+	SortViolations(violations)
+	return violations
+}
+
+// canBuild reports whether BuildRule builds the rule named name.
+func (l *Linter) canBuild(name string) bool {
+	if l.BuildRule == nil {
+		return false
+	}
+	rule, err := l.BuildRule(name, nil)
+	return err == nil && rule != nil
+}
+
+// documentRules returns the rules to run on source, which must not include
+// front matter: l.Rules, with each rule that a markdownlint-configure-file
+// comment gives an options object rebuilt by BuildRule. A rule set to true
+// keeps its current options, or is built with its default settings when it
+// is not among l.Rules. Rules that BuildRule cannot build are left as they
+// are, like a payload that is not valid JSON.
+func (l *Linter) documentRules(source []byte) []Rule {
+	if l.NoInlineConfig || l.BuildRule == nil {
+		return l.Rules
+	}
+	cfg, _ := ConfigureFile(source)
+	if cfg == nil {
+		return l.Rules
+	}
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := l.Rules
+	copied := false
+	for _, key := range keys {
+		id := l.resolveRuleID(key)
+		index := ruleIndex(result, id)
+		var options map[string]interface{}
+		switch v := cfg[key].(type) {
+		case map[string]interface{}:
+			options = v
+		case bool:
+			if !v || index >= 0 {
+				continue
+			}
+		default:
+			continue
+		}
+		rule, err := l.BuildRule(id, options)
+		if err != nil || rule == nil {
+			continue
+		}
+		if !copied {
+			result = append([]Rule(nil), result...)
+			copied = true
+		}
+		if index < 0 {
+			index = ruleIndex(result, rule.ID())
+		}
+		if index >= 0 {
+			result[index] = rule
+		} else {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// LintProject runs the ProjectRule implementations among the linter's rules
+// over files, a map from file path to content, and returns the violations of
+// each file sorted by line. Rules that only implement Rule are not run; use
+// Lint for those.
+func (l *Linter) LintProject(files map[string][]byte) map[string][]Violation {
+	return LintProjectFiles(files, func(string) *Linter { return l })
+}
+
+// LintProjectFiles is LintProject for files that are not all linted with the
+// same linter: linterFor returns the linter of each file, or nil to leave the
+// file out. Every document is parsed with the linter of its file. A project
+// rule that several linters run with the same options, as told by its ID and
+// JSON encoding, runs once over all the documents, and its violations are
+// kept for the files whose linter runs it.
+func LintProjectFiles(files map[string][]byte, linterFor func(path string) *Linter) map[string][]Violation {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// The distinct project rules, in order of first use, with the files
+	// whose linter runs each of them.
+	type projectRule struct {
+		rule  ProjectRule
+		paths map[string]bool
+	}
+	var projectRules []*projectRule
+	index := make(map[string]*projectRule)
+	linters := make(map[string]*Linter, len(paths))
+	for _, path := range paths {
+		l := linterFor(path)
+		if l == nil {
+			continue
+		}
+		linters[path] = l
+		for _, rule := range l.Rules {
+			pr, ok := rule.(ProjectRule)
+			if !ok {
+				continue
+			}
+			key := projectRuleKey(l, pr)
+			r, ok := index[key]
+			if !ok {
+				r = &projectRule{rule: pr, paths: make(map[string]bool)}
+				index[key] = r
+				projectRules = append(projectRules, r)
+			}
+			r.paths[path] = true
+		}
+	}
+	if len(projectRules) == 0 {
+		return nil
+	}
+
+	type parsed struct {
+		doc      *Document
+		offset   int
+		disabled []disableSet
+	}
+	byPath := make(map[string]parsed, len(linters))
+	docs := make([]*Document, 0, len(linters))
+	for _, path := range paths {
+		l, ok := linters[path]
+		if !ok {
+			continue
+		}
+		doc, offset := l.parse(files[path])
+		doc.Path = path
+		p := parsed{doc: doc, offset: offset}
+		if !l.NoInlineConfig {
+			p.disabled, _ = parseInlineDisables(doc.Lines, l.resolveRuleID)
+		}
+		byPath[path] = p
+		docs = append(docs, doc)
+	}
+
+	result := make(map[string][]Violation)
+	for _, r := range projectRules {
+		for path, violations := range r.rule.CheckProject(docs) {
+			p, ok := byPath[path]
+			if !ok || !r.paths[path] {
+				continue
+			}
+			for _, v := range violations {
+				if v, ok := accept(v, p.doc, p.offset, p.disabled); ok {
+					result[path] = append(result[path], v)
+				}
+			}
+		}
+	}
+	for _, violations := range result {
+		SortViolations(violations)
+	}
+	return result
+}
+
+// projectRuleKey returns the key that tells the project rules of linters
+// apart: their ID and options. A rule of l whose options cannot be encoded is
+// only the same as itself.
+func projectRuleKey(l *Linter, r ProjectRule) string {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Sprintf("%s %p", r.ID(), l)
+	}
+	return r.ID() + " " + string(data)
+}
+
+// ruleIndex returns the index of the rule with the given ID in rules, or -1.
+func ruleIndex(rules []Rule, id string) int {
+	for i, r := range rules {
+		if r.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// SortViolations sorts violations by line and then by rule ID, the order in
+// which Lint returns them.
+func SortViolations(violations []Violation) {
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		return violations[i].Rule < violations[j].Rule
+	})
+}
+
+// parse strips the front matter from source and parses the remainder into a
+// Document. The front matter is replaced by its line breaks so that line
+// numbers are preserved; offset is the number of bytes removed that way, which
+// must be added to byte offsets in doc.Source to map them back to source.
+func (l *Linter) parse(source []byte) (doc *Document, offset int) {
 	end := l.fmEnd(source)
-	fmFields := parseFrontMatterFieldsAt(source, end)
+	fm := parseFrontMatter(source, end)
 	// Count the number of lines consumed by the front matter block.
 	fmLines := 0
 	for _, b := range source[:end] {
@@ -126,11 +689,12 @@ func (l *Linter) Lint(source []byte) []Violation {
 			fmLines++
 		}
 	}
-	source = stripFrontMatterAt(source, end)
+	stripped := stripFrontMatterAt(source, end)
+	offset = len(source) - len(stripped)
 
 	pctx := parser.NewContext()
-	reader := text.NewReader(source)
-	md := goldmark.New(goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.TaskList))
+	reader := text.NewReader(stripped)
+	md, extensions := l.markdown()
 	node := md.Parser().Parse(reader, parser.WithContext(pctx))
 
 	// Build a normalised label → destination map from goldmark's parsed references.
@@ -140,90 +704,137 @@ func (l *Linter) Lint(source []byte) []Violation {
 		linkRefs[key] = ref.Destination()
 	}
 
-	lines := splitLines(source)
-	doc := &Document{
-		Source:            source,
-		Lines:             lines,
-		AST:               node,
-		FrontMatterFields: fmFields,
-		FrontMatterLines:  fmLines,
-		LinkRefs:          linkRefs,
-	}
+	return &Document{
+		Source:              stripped,
+		Lines:               splitLines(stripped),
+		AST:                 node,
+		FrontMatter:         fm.tree,
+		FrontMatterFormat:   fm.format,
+		FrontMatterError:    fm.err,
+		FrontMatterKeyLines: fm.keyLines,
+		FrontMatterFields:   frontMatterFields(fm.tree),
+		FrontMatterLines:    fmLines,
+		LinkRefs:            linkRefs,
+		Extensions:          extensions,
+	}, offset
+}
 
-	var disabled []disableSet
-	if !l.NoInlineConfig {
-		disabled = parseInlineDisables(lines, l.resolveRuleID)
+// withDefaultRange fills in the end position of v when the rule left it unset:
+// the range then extends from Column to the end of Line.
+func withDefaultRange(v Violation, lines []string) Violation {
+	if v.EndLine == 0 {
+		v.EndLine = v.Line
+		if v.EndColumn == 0 && v.Line >= 1 && v.Line <= len(lines) {
+			v.EndColumn = len(lines[v.Line-1]) + 1
+		}
+	}
+	if v.EndLine < v.Line || v.EndLine == v.Line && v.EndColumn < v.Column {
+		v.EndLine, v.EndColumn = v.Line, v.Column
 	}
+	return v
+}
 
+// check runs rules on doc and returns their violations, dropping those that
+// disabled suppresses. Fix edits are shifted by offset so that they refer to
+// the original source rather than doc.Source.
+func (l *Linter) check(doc *Document, offset int, rules []Rule, disabled []disableSet) []Violation {
 	var violations []Violation
-	for _, rule := range l.Rules {
+	for _, rule := range rules {
 		for _, v := range rule.Check(doc) {
-			idx := v.Line - 1 // convert 1-based to 0-based
-			if idx < len(disabled) && disabled[idx].contains(v.Rule) {
-				continue
+			if v, ok := accept(v, doc, offset, disabled); ok {
+				violations = append(violations, v)
 			}
-			violations = append(violations, v)
 		}
 	}
-
-	sort.Slice(violations, func(i, j int) bool {
-		if violations[i].Line != violations[j].Line {
-			return violations[i].Line < violations[j].Line
-		}
-		return violations[i].Rule < violations[j].Rule
-	})
-
 	return violations
 }
 
-// parseFrontMatterFieldsAt parses YAML front matter up to end bytes in source.
-func parseFrontMatterFieldsAt(source []byte, end int) map[string]string {
-	fields := make(map[string]string)
-	if end == 0 {
-		return fields
+// checkSuppressions runs the SuppressionRule implementations among rules on
+// suppressions, the inline disable comments of doc, once check has recorded
+// which of them suppressed a violation. canBuild reports whether a rule the
+// linter does not run is known to it all the same.
+func checkSuppressions(doc *Document, offset int, rules []Rule, disabled []disableSet, suppressions []*Suppression, canBuild func(name string) bool) []Violation {
+	var suppressionRules []SuppressionRule
+	known := make(map[string]bool, len(rules))
+	// checked holds the rules whose violations Lint sees.
+	checked := make(map[string]bool, len(rules))
+	allChecked := true
+	for _, rule := range rules {
+		known[rule.ID()] = true
+		switch r := rule.(type) {
+		case SuppressionRule:
+			suppressionRules = append(suppressionRules, r)
+		case ProjectRule:
+			allChecked = false
+		default:
+			checked[rule.ID()] = true
+		}
 	}
-	// Skip the opening "---\n" line.
-	start := bytes.IndexByte(source, '\n')
-	if start < 0 {
-		return fields
+	if len(suppressionRules) == 0 {
+		return nil
 	}
-	start++ // skip past opening newline
-	for start < end {
-		next := bytes.IndexByte(source[start:], '\n')
-		var lineEnd int
-		if next < 0 {
-			lineEnd = end
-		} else {
-			lineEnd = start + next
-		}
-		line := strings.TrimRight(string(source[start:lineEnd]), "\r")
-		if line == "---" || line == "..." {
-			break
+
+	list := make([]Suppression, len(suppressions))
+	for i, s := range suppressions {
+		s.Unused = len(s.Rules) == 0 && !s.used && allChecked
+		for j := range s.Rules {
+			r := &s.Rules[j]
+			r.Known = known[r.ID] || canBuild(r.Name)
+			r.Unused = !r.used && checked[r.ID]
 		}
-		if idx := strings.Index(line, ":"); idx > 0 {
-			key := strings.TrimSpace(line[:idx])
-			val := strings.TrimSpace(line[idx+1:])
-			// Strip surrounding quotes.
-			if len(val) >= 2 &&
-				((val[0] == '"' && val[len(val)-1] == '"') ||
-					(val[0] == '\'' && val[len(val)-1] == '\'')) {
-				val = val[1 : len(val)-1]
+		list[i] = *s
+	}
+	// Only comments that name a suppression rule disable it, so that a
+	// comment disabling all rules does not hide its own report.
+	named := make([]disableSet, len(disabled))
+	for i, d := range disabled {
+		named[i] = disableSet{rules: d.rules}
+	}
+	var violations []Violation
+	for _, rule := range suppressionRules {
+		for _, v := range rule.CheckSuppressions(doc, list) {
+			if v, ok := accept(v, doc, offset, named); ok {
+				violations = append(violations, v)
 			}
-			fields[key] = val
 		}
-		if next < 0 {
-			break
+	}
+	return violations
+}
+
+// accept prepares a violation reported for doc for the caller: it reports
+// false if disabled suppresses the violation, recording the use of the
+// comments that suppress it, and otherwise fills in its default range and
+// shifts its Fix edits by offset.
+func accept(v Violation, doc *Document, offset int, disabled []disableSet) (Violation, bool) {
+	idx := v.Line - 1 // convert 1-based to 0-based
+	if idx >= 0 && idx < len(disabled) && disabled[idx].suppress(v.Rule) {
+		return v, false
+	}
+	v = withDefaultRange(v, doc.Lines)
+	if offset > 0 && len(v.Fix) > 0 {
+		shifted := make([]Edit, len(v.Fix))
+		for i, e := range v.Fix {
+			e.Start += offset
+			e.End += offset
+			shifted[i] = e
 		}
-		start = lineEnd + 1
+		v.Fix = shifted
 	}
-	return fields
+	return v, true
 }
 
+// frontMatterEnd returns the byte offset of the end of the
 // front matter block, or 0 if the source does not begin with valid front matter.
-// Front matter starts with "---" on the very first line and ends with a line
-// containing only "---" or "...".
+// YAML front matter starts with "---" on the very first line and ends with a
+// line containing only "---" or "...". TOML front matter starts and ends with
+// a line containing only "+++".
 func frontMatterEnd(source []byte) int {
-	if !bytes.HasPrefix(source, []byte("---\n")) && !bytes.HasPrefix(source, []byte("---\r\n")) {
+	closer, altCloser := "---", "..."
+	switch {
+	case bytes.HasPrefix(source, []byte("---\n")) || bytes.HasPrefix(source, []byte("---\r\n")):
+	case bytes.HasPrefix(source, []byte("+++\n")) || bytes.HasPrefix(source, []byte("+++\r\n")):
+		closer, altCloser = "+++", "+++"
+	default:
 		return 0
 	}
 	// Advance past the opening delimiter line.
@@ -245,7 +856,7 @@ func frontMatterEnd(source []byte) int {
 		if len(line) > 0 && line[len(line)-1] == '\r' {
 			line = line[:len(line)-1]
 		}
-		if bytes.Equal(line, []byte("---")) || bytes.Equal(line, []byte("...")) {
+		if string(line) == closer || string(line) == altCloser {
 			end := lineEnd
 			if end < len(source) && source[end] == '\n' {
 				end++
@@ -297,11 +908,12 @@ func splitLines(source []byte) []string {
 }
 
 // markdownlintCommentRE matches markdownlint inline disable/enable comments.
-// It captures the command and optional rule IDs.
+// It captures the command, optional rule IDs, and the optional reason after
+// "--", as in <!-- markdownlint-disable MD033 -- embedded video -->.
 // Rule names and aliases may contain hyphens (e.g. "heading-increment"), so
-// [\w-]+ is used instead of \w+.
+// \w[\w-]* is used instead of \w+.
 var markdownlintCommentRE = regexp.MustCompile(
-	`<!--\s*markdownlint-(disable-next-line|disable-line|disable-file|enable-file|disable|enable|capture|restore)((?:\s+[\w-]+)*)\s*-->`,
+	`<!--\s*markdownlint-(disable-next-line|disable-line|disable-file|enable-file|disable|enable|capture|restore)((?:\s+\w[\w-]*)*)(?:\s+--\s*(.*?))?\s*-->`,
 )
 
 // markdownlintConfigureFileRE matches markdownlint-configure-file comments
@@ -310,16 +922,27 @@ var markdownlintConfigureFileRE = regexp.MustCompile(
 	`(?s)<!--\s*markdownlint-configure-file\s*(\{.*?\})\s*-->`,
 )
 
-// parseMarkdownlintComment extracts the command and rule list from a markdownlint
-// inline comment, if one is found anywhere on the given line.
-func parseMarkdownlintComment(line string) (cmd string, ruleIDs []string) {
-	m := markdownlintCommentRE.FindStringSubmatch(line)
+// inlineComment is a markdownlint inline comment found on a line.
+type inlineComment struct {
+	cmd     string
+	ruleIDs []string
+	reason  string
+	column  int // 1-based column of "<!--"
+}
+
+// parseMarkdownlintComment extracts the command, rule list, and reason from a
+// markdownlint inline comment, if one is found anywhere on the given line.
+func parseMarkdownlintComment(line string) inlineComment {
+	m := markdownlintCommentRE.FindStringSubmatchIndex(line)
 	if m == nil {
-		return "", nil
+		return inlineComment{}
 	}
-	cmd = m[1]
-	ruleIDs = append(ruleIDs, strings.Fields(m[2])...)
-	return cmd, ruleIDs
+	c := inlineComment{cmd: line[m[2]:m[3]], column: m[0] + 1}
+	c.ruleIDs = strings.Fields(line[m[4]:m[5]])
+	if m[6] >= 0 {
+		c.reason = line[m[6]:m[7]]
+	}
+	return c
 }
 
 // parseConfigureFileComment extracts the JSON payload from a markdownlint-configure-file
@@ -332,8 +955,25 @@ func parseConfigureFileComment(source string) string {
 	return strings.TrimSpace(m[1])
 }
 
+// ConfigureFile returns the rule config that the markdownlint-configure-file
+// comment of source gives, with its keys as written, and the 1-based line the
+// comment starts on. The config is nil when there is no such comment or its
+// payload is not a valid JSON object.
+func ConfigureFile(source []byte) (map[string]interface{}, int) {
+	m := markdownlintConfigureFileRE.FindSubmatchIndex(source)
+	if m == nil {
+		return nil, 0
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(source[m[2]:m[3]]), &cfg); err != nil {
+		return nil, 0
+	}
+	return cfg, bytes.Count(source[:m[0]], []byte("\n")) + 1
+}
+
 // applyConfigureFile parses a markdownlint-configure-file JSON payload and returns
 // file-level disable/enable overrides. Values of false disable a rule; true enables it.
+// Options objects are applied by Linter.documentRules.
 func applyConfigureFile(jsonPayload string, fileDis *disableSet, resolve func(string) string) {
 	var cfg map[string]interface{}
 	if err := json.Unmarshal([]byte(jsonPayload), &cfg); err != nil {
@@ -344,7 +984,7 @@ func applyConfigureFile(jsonPayload string, fileDis *disableSet, resolve func(st
 		switch v := val.(type) {
 		case bool:
 			if !v {
-				fileDis.rules[id] = true
+				fileDis.rules[id] = []disableSource{{}}
 			} else {
 				delete(fileDis.rules, id)
 			}
@@ -352,90 +992,136 @@ func applyConfigureFile(jsonPayload string, fileDis *disableSet, resolve func(st
 	}
 }
 
-// disableSet tracks which rules are disabled for a single line.
+// disableSource is a comment that disables a rule. used points to the flag
+// that records whether the comment suppressed a violation of the rule; it is
+// nil for markdownlint-configure-file settings, which are not tracked.
+type disableSource struct {
+	suppression *Suppression
+	used        *bool
+}
+
+// disableSet tracks which rules are disabled for a single line, and by which
+// comments.
 type disableSet struct {
-	all   bool
-	rules map[string]bool
+	all   []disableSource
+	rules map[string][]disableSource
+}
+
+// newDisableSet returns an empty disableSet.
+func newDisableSet() disableSet {
+	return disableSet{rules: make(map[string][]disableSource)}
 }
 
 // contains reports whether rule is suppressed.
 func (d disableSet) contains(rule string) bool {
-	return d.all || d.rules[rule]
+	return len(d.all) > 0 || len(d.rules[rule]) > 0
+}
+
+// suppress reports whether rule is suppressed, recording that the comments
+// which disable it suppressed a violation.
+func (d disableSet) suppress(rule string) bool {
+	if !d.contains(rule) {
+		return false
+	}
+	for _, src := range d.all {
+		*src.used = true
+	}
+	for _, src := range d.rules[rule] {
+		if src.used != nil {
+			*src.used = true
+		}
+	}
+	return true
+}
+
+// add disables the rules that s names in d, or all rules if it names none.
+func (d *disableSet) add(s *Suppression) {
+	if len(s.Rules) == 0 {
+		d.all = append(d.all, disableSource{s, &s.used})
+		return
+	}
+	for i := range s.Rules {
+		r := &s.Rules[i]
+		d.rules[r.ID] = append(d.rules[r.ID], disableSource{s, &r.used})
+	}
+}
+
+// merge disables the rules of o in d as well.
+func (d *disableSet) merge(o disableSet) {
+	d.all = append(d.all, o.all...)
+	for r, srcs := range o.rules {
+		d.rules[r] = append(d.rules[r], srcs...)
+	}
 }
 
 // copyDisableSet returns a deep copy of d.
 func copyDisableSet(d disableSet) disableSet {
-	c := disableSet{all: d.all, rules: make(map[string]bool, len(d.rules))}
-	for r := range d.rules {
-		c.rules[r] = true
+	c := disableSet{all: append([]disableSource(nil), d.all...), rules: make(map[string][]disableSource, len(d.rules))}
+	for r, srcs := range d.rules {
+		c.rules[r] = append([]disableSource(nil), srcs...)
 	}
 	return c
 }
 
 // parseInlineDisables scans source lines for markdownlint inline disable
-// comments and returns a per-line (0-based) slice of disableSet values.
+// comments and returns a per-line (0-based) slice of disableSet values,
+// together with the disable comments found, in line order.
 // resolve maps a rule name (alias or ID) to its canonical uppercase rule ID.
-func parseInlineDisables(lines []string, resolve func(string) string) []disableSet {
+func parseInlineDisables(lines []string, resolve func(string) string) ([]disableSet, []*Suppression) {
 	n := len(lines)
 	result := make([]disableSet, n)
-	for i := range result {
-		result[i] = disableSet{rules: make(map[string]bool)}
+	var suppressions []*Suppression
+	suppression := func(i int, c inlineComment) *Suppression {
+		s := &Suppression{Line: i + 1, Column: c.column, Command: c.cmd, Reason: c.reason}
+		for _, name := range c.ruleIDs {
+			s.Rules = append(s.Rules, SuppressedRule{Name: name, ID: resolve(name)})
+		}
+		suppressions = append(suppressions, s)
+		return s
 	}
 
 	// First pass: collect file-level disable/enable commands (disable-file,
 	// enable-file, configure-file), which apply to every line in the file.
 	// fileDisable tracks disable-file/enable-file; fileConfig tracks configure-file.
 	// They are kept separate so that enable-file does not undo configure-file settings.
-	fileDisable := disableSet{rules: make(map[string]bool)}
-	fileConfig := disableSet{rules: make(map[string]bool)}
+	fileDisable := newDisableSet()
+	fileConfig := newDisableSet()
 	fullSource := strings.Join(lines, "\n")
 	if payload := parseConfigureFileComment(fullSource); payload != "" {
 		applyConfigureFile(payload, &fileConfig, resolve)
 	}
-	for _, line := range lines {
-		cmd, ruleIDs := parseMarkdownlintComment(line)
-		switch cmd {
+	for i, line := range lines {
+		c := parseMarkdownlintComment(line)
+		switch c.cmd {
 		case "disable-file":
-			if len(ruleIDs) == 0 {
-				fileDisable.all = true
-			} else {
-				for _, r := range ruleIDs {
-					fileDisable.rules[resolve(r)] = true
-				}
-			}
+			fileDisable.add(suppression(i, c))
 		case "enable-file":
-			if len(ruleIDs) == 0 {
-				fileDisable = disableSet{rules: make(map[string]bool)}
+			if len(c.ruleIDs) == 0 {
+				fileDisable = newDisableSet()
 			} else {
-				for _, r := range ruleIDs {
+				for _, r := range c.ruleIDs {
 					delete(fileDisable.rules, resolve(r))
 				}
 			}
 		}
 	}
 
-	current := disableSet{rules: make(map[string]bool)}
+	current := newDisableSet()
 	var captured *disableSet
-	// nextLineRules holds the extra disable state to apply to the next line.
+	// nextLineExtra holds the extra disable state to apply to the next line.
 	var nextLineExtra *disableSet
 
 	for i, line := range lines {
-		cmd, ruleIDs := parseMarkdownlintComment(line)
+		c := parseMarkdownlintComment(line)
 
-		switch cmd {
+		switch c.cmd {
 		case "disable":
-			if len(ruleIDs) == 0 {
-				current.all = true
-			} else {
-				for _, r := range ruleIDs {
-					current.rules[resolve(r)] = true
-				}
-			}
+			current.add(suppression(i, c))
 		case "enable":
-			if len(ruleIDs) == 0 {
-				current = disableSet{rules: make(map[string]bool)}
+			if len(c.ruleIDs) == 0 {
+				current = newDisableSet()
 			} else {
-				for _, r := range ruleIDs {
+				for _, r := range c.ruleIDs {
 					delete(current.rules, resolve(r))
 				}
 			}
@@ -450,53 +1136,39 @@ func parseInlineDisables(lines []string, resolve func(string) string) []disableS
 
 		// Base state for this line comes from running state merged with file-level.
 		result[i] = copyDisableSet(current)
-		if fileDisable.all {
-			result[i].all = true
-		} else {
-			for r := range fileDisable.rules {
-				result[i].rules[r] = true
-			}
-		}
-		for r := range fileConfig.rules {
-			result[i].rules[r] = true
-		}
+		result[i].merge(fileDisable)
+		result[i].merge(fileConfig)
 
 		// Apply disable-next-line extras carried over from previous line.
 		if nextLineExtra != nil {
-			if nextLineExtra.all {
-				result[i].all = true
-			} else {
-				for r := range nextLineExtra.rules {
-					result[i].rules[r] = true
-				}
-			}
+			result[i].merge(*nextLineExtra)
 			nextLineExtra = nil
 		}
 
-		// Apply disable-line to the current line.
-		if cmd == "disable-line" {
-			if len(ruleIDs) == 0 {
-				result[i].all = true
-			} else {
-				for _, r := range ruleIDs {
-					result[i].rules[resolve(r)] = true
-				}
-			}
+		switch c.cmd {
+		case "disable-line":
+			// Apply disable-line to the current line.
+			result[i].add(suppression(i, c))
+		case "disable-next-line":
+			// Prepare disable-next-line extra for the next line.
+			extra := newDisableSet()
+			extra.add(suppression(i, c))
+			nextLineExtra = &extra
 		}
+	}
 
-		// Prepare disable-next-line extra for the next line.
-		if cmd == "disable-next-line" {
-			extra := disableSet{rules: make(map[string]bool)}
-			if len(ruleIDs) == 0 {
-				extra.all = true
-			} else {
-				for _, r := range ruleIDs {
-					extra.rules[resolve(r)] = true
-				}
-			}
-			nextLineExtra = &extra
+	// Disable comments still in effect at the end have no matching enable.
+	for _, src := range current.all {
+		src.suppression.Unclosed = true
+	}
+	for _, srcs := range current.rules {
+		for _, src := range srcs {
+			src.suppression.Unclosed = true
 		}
 	}
 
-	return result
+	sort.SliceStable(suppressions, func(i, j int) bool {
+		return suppressions[i].Line < suppressions[j].Line
+	})
+	return result, suppressions
 }
@@ -2,14 +2,20 @@ package lint_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/mrueg/goldmark-lint/lint"
 	"github.com/mrueg/goldmark-lint/lint/rules"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
 )
 
 func newDefaultLinter() *lint.Linter {
@@ -291,6 +297,22 @@ func TestMD012_Fix(t *testing.T) {
 	}
 }
 
+func TestMD012_Fix_CRLF(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"a\r\n\r\n\r\n\r\nb", "a\r\n\r\nb"},
+		{"a\r\n\r\n\r\nb\r\n", "a\r\n\r\nb\r\n"},
+		{"a\r\n\r\n\r\n", "a\r\n"},
+		{"a\r\n\r\n\r\n\r\n", "a\r\n"},
+		{"a\n\n\n\n", "a\n"},
+		{"a\r\n\r\n\r\n  ", "a\r\n"},
+	}
+	for _, tt := range tests {
+		if got := fixString(t, rules.MD012{}, tt.src); got != tt.want {
+			t.Errorf("Fix(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
 func TestMD047_Fix(t *testing.T) {
 	src := "Content"
 	got := fixString(t, rules.MD047{}, src)
@@ -319,6 +341,236 @@ func TestLinter_Fix(t *testing.T) {
 	}
 }
 
+func TestMD009_FixEdits(t *testing.T) {
+	src := "Text   \nMore\t \n"
+	v := lintString(t, rules.MD009{}, src)
+	if len(v) != 2 {
+		t.Fatalf("expected 2 violations, got %d: %v", len(v), v)
+	}
+	want := []lint.Edit{{Start: 4, End: 7}}
+	if len(v[0].Fix) != 1 || v[0].Fix[0] != want[0] {
+		t.Errorf("Fix = %+v, want %+v", v[0].Fix, want)
+	}
+	want = []lint.Edit{{Start: 12, End: 14}}
+	if len(v[1].Fix) != 1 || v[1].Fix[0] != want[0] {
+		t.Errorf("Fix = %+v, want %+v", v[1].Fix, want)
+	}
+}
+
+func TestFixEdits_FrontMatterOffset(t *testing.T) {
+	// Edit offsets must refer to the original source, front matter included.
+	src := "---\ntitle: x\n---\n# Title\n\nText   \n"
+	l := lint.NewLinter(rules.MD009{})
+	v := l.Lint([]byte(src))
+	if len(v) != 1 || len(v[0].Fix) != 1 {
+		t.Fatalf("expected 1 violation with 1 edit, got %v", v)
+	}
+	e := v[0].Fix[0]
+	if got := src[e.Start:e.End]; got != "   " {
+		t.Errorf("edit covers %q, want trailing spaces", got)
+	}
+	got := string(l.Fix([]byte(src)))
+	want := "---\ntitle: x\n---\n# Title\n\nText\n"
+	if got != want {
+		t.Errorf("Fix() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyFixes_SkipsOverlapping(t *testing.T) {
+	src := []byte("abcdef")
+	violations := []lint.Violation{
+		{Rule: "A", Fix: []lint.Edit{{Start: 1, End: 3, NewText: "X"}}},
+		// Overlaps the first edit, so the whole violation is skipped.
+		{Rule: "B", Fix: []lint.Edit{{Start: 5, End: 6, NewText: "Z"}, {Start: 2, End: 4, NewText: "Y"}}},
+		// Adjacent edits and insertions do not overlap.
+		{Rule: "C", Fix: []lint.Edit{{Start: 3, End: 4}, {Start: 6, End: 6, NewText: "!"}}},
+		// A second insertion at the same offset is ambiguous.
+		{Rule: "D", Fix: []lint.Edit{{Start: 6, End: 6, NewText: "?"}}},
+		{Rule: "E"},
+	}
+	got, fixed := lint.ApplyFixes(src, violations)
+	if string(got) != "aXef!" {
+		t.Errorf("ApplyFixes() = %q, want %q", got, "aXef!")
+	}
+	if fixed != 2 {
+		t.Errorf("fixed = %d, want 2", fixed)
+	}
+	if string(src) != "abcdef" {
+		t.Errorf("source was modified: %q", src)
+	}
+}
+
+func TestLinter_Fix_MergesEdits(t *testing.T) {
+	// MD010 and MD009 both touch the last line; MD019 and MD018 fix headings.
+	src := "##  Title\n\n#Other\n\nTab\there \t  \n\n\n\nEnd"
+	l := lint.NewLinter(rules.MD009{}, rules.MD010{}, rules.MD012{}, rules.MD018{}, rules.MD019{}, rules.MD047{})
+	got := string(l.Fix([]byte(src)))
+	want := "## Title\n\n# Other\n\nTab    here\n\nEnd\n"
+	if got != want {
+		t.Errorf("Fix() = %q, want %q", got, want)
+	}
+}
+
+func TestLinter_FixUntilStable(t *testing.T) {
+	// Removing the whitespace-only line (MD009) leaves two consecutive blank
+	// lines behind, which MD012 only sees on the next pass.
+	src := "# Title\n\nText\n\n   \n\nMore\n"
+	l := lint.NewLinter(rules.MD009{}, rules.MD012{})
+	if got := string(l.Fix([]byte(src))); got != "# Title\n\nText\n\n\nMore\n" {
+		t.Fatalf("single pass Fix() = %q, expected a leftover MD012 violation", got)
+	}
+	got, err := l.FixUntilStable([]byte(src))
+	if err != nil {
+		t.Fatalf("FixUntilStable() error: %v", err)
+	}
+	want := "# Title\n\nText\n\nMore\n"
+	if string(got) != want {
+		t.Errorf("FixUntilStable() = %q, want %q", got, want)
+	}
+}
+
+// togglingRule flips the last byte of the document between 'a' and 'b', so
+// its fixes never converge.
+type togglingRule struct{}
+
+func (togglingRule) ID() string                            { return "TOGGLE" }
+func (togglingRule) Description() string                   { return "Toggles" }
+func (togglingRule) Check(*lint.Document) []lint.Violation { return nil }
+func (togglingRule) Fix(source []byte) []byte {
+	out := append([]byte(nil), source...)
+	if n := len(out); n > 0 {
+		out[n-1] ^= 'a' ^ 'b'
+	}
+	return out
+}
+
+// growingRule appends a byte on every fix, so its output never repeats.
+type growingRule struct{}
+
+func (growingRule) ID() string                            { return "GROW" }
+func (growingRule) Description() string                   { return "Grows" }
+func (growingRule) Check(*lint.Document) []lint.Violation { return nil }
+func (growingRule) Fix(source []byte) []byte              { return append(source[:len(source):len(source)], 'x') }
+
+func TestLinter_FixUntilStable_Cycle(t *testing.T) {
+	l := lint.NewLinter(rules.MD009{}, togglingRule{})
+	got, err := l.FixUntilStable([]byte("Text   \na"))
+	var nc *lint.FixNotConvergedError
+	if !errors.As(err, &nc) {
+		t.Fatalf("expected FixNotConvergedError, got %v", err)
+	}
+	if !nc.Cycle || nc.Passes != 3 {
+		t.Errorf("got Cycle=%v Passes=%d, want a cycle after 3 passes", nc.Cycle, nc.Passes)
+	}
+	if len(nc.Rules) != 1 || nc.Rules[0] != "TOGGLE" {
+		t.Errorf("Rules = %v, want [TOGGLE]", nc.Rules)
+	}
+	if string(got) != "Text\nb" {
+		t.Errorf("FixUntilStable() = %q, want %q", got, "Text\nb")
+	}
+}
+
+func TestLinter_FixUntilStable_MaxPasses(t *testing.T) {
+	l := lint.NewLinter(growingRule{})
+	l.MaxFixPasses = 3
+	got, err := l.FixUntilStable([]byte("a"))
+	var nc *lint.FixNotConvergedError
+	if !errors.As(err, &nc) {
+		t.Fatalf("expected FixNotConvergedError, got %v", err)
+	}
+	if nc.Cycle || nc.Passes != 3 {
+		t.Errorf("got Cycle=%v Passes=%d, want 3 passes without a cycle", nc.Cycle, nc.Passes)
+	}
+	if !strings.Contains(err.Error(), "GROW") {
+		t.Errorf("error %q does not name the rule", err)
+	}
+	if string(got) != "axxx" {
+		t.Errorf("FixUntilStable() = %q, want %q", got, "axxx")
+	}
+}
+
+func TestLinter_Fix_RespectsInlineDisables(t *testing.T) {
+	l := lint.NewLinter(rules.MD009{}, rules.MD010{}, rules.MD026{}, rules.MD032{})
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			name: "disable block",
+			src:  "# Heading\n\n<!-- markdownlint-disable MD010 -->\n| a\tb |\n<!-- markdownlint-enable MD010 -->\n\nText\twith tab   \n",
+			want: "# Heading\n\n<!-- markdownlint-disable MD010 -->\n| a\tb |\n<!-- markdownlint-enable MD010 -->\n\nText    with tab\n",
+		},
+		{
+			name: "disable-next-line",
+			src:  "# Heading\n\n## Changed!\n\n<!-- markdownlint-disable-next-line MD026 -->\n## Kept!\n",
+			want: "# Heading\n\n## Changed\n\n<!-- markdownlint-disable-next-line MD026 -->\n## Kept!\n",
+		},
+		{
+			name: "inserted lines",
+			src:  "Intro\n- kept <!-- markdownlint-disable-line MD032 -->\n\nPara\n- fixed\n",
+			want: "Intro\n- kept <!-- markdownlint-disable-line MD032 -->\n\nPara\n\n- fixed\n",
+		},
+		{
+			name: "disable-file",
+			src:  "<!-- markdownlint-disable-file no-trailing-punctuation -->\n# Title!\n\nText   \n",
+			want: "<!-- markdownlint-disable-file no-trailing-punctuation -->\n# Title!\n\nText\n",
+		},
+		{
+			name: "configure-file",
+			src:  "# Title!\n\nText\ttab   \n\n<!-- markdownlint-configure-file { \"MD009\": false, \"MD010\": false } -->\n",
+			want: "# Title\n\nText\ttab   \n\n<!-- markdownlint-configure-file { \"MD009\": false, \"MD010\": false } -->\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(l.Fix([]byte(tt.src))); got != tt.want {
+				t.Errorf("Fix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A long document with a change on every other line is diffed without
+	// memory growing with the number of changes times the number of lines.
+	var long, longWant strings.Builder
+	long.WriteString("<!-- markdownlint-disable-next-line MD009 -->\nKept   \n")
+	longWant.WriteString("<!-- markdownlint-disable-next-line MD009 -->\nKept   \n")
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&long, "\nLine %d   \n", i)
+		fmt.Fprintf(&longWant, "\nLine %d\n", i)
+	}
+	if got := string(l.Fix([]byte(long.String()))); got != longWant.String() {
+		t.Errorf("Fix() of a long document did not keep only the suppressed line")
+	}
+
+	// With NoInlineConfig, the comments are ignored.
+	l.NoInlineConfig = true
+	src := "<!-- markdownlint-disable-file -->\n# Title!\n"
+	if got, want := string(l.Fix([]byte(src))), "<!-- markdownlint-disable-file -->\n# Title\n"; got != want {
+		t.Errorf("Fix() with NoInlineConfig = %q, want %q", got, want)
+	}
+}
+
+func TestMD021_FixEdits(t *testing.T) {
+	src := "#  Title  #\n"
+	got := fixString(t, rules.MD021{}, src)
+	want := "# Title #\n"
+	if got != want {
+		t.Errorf("Fix() = %q, want %q", got, want)
+	}
+}
+
+func TestMD011_FixEdits(t *testing.T) {
+	src := "See (one)[a.md] and (two)[b.md].\n"
+	v := lintString(t, rules.MD011{}, src)
+	if len(v) != 2 {
+		t.Fatalf("expected 2 violations, got %d", len(v))
+	}
+	got := fixString(t, rules.MD011{}, src)
+	want := "See [one](a.md) and [two](b.md).\n"
+	if got != want {
+		t.Errorf("Fix() = %q, want %q", got, want)
+	}
+}
+
 func TestMD003_Valid(t *testing.T) {
 	src := "# Heading 1\n\n## Heading 2\n"
 	v := lintString(t, rules.MD003{}, src)
@@ -1863,6 +2115,81 @@ func TestInlineDisable_ConfigureFile_EnableRuleByAlias(t *testing.T) {
 	}
 }
 
+func TestInlineDisable_ConfigureFile_RuleOptions(t *testing.T) {
+	// configure-file with an options object rebuilds the rule for the file.
+	long := strings.Repeat("word ", 19) + "word\n"
+	src := "<!-- markdownlint-configure-file { \"line-length\": { \"line_length\": 120 } } -->\n" + long
+	l := lint.NewLinter(rules.MD013{})
+	l.BuildRule = rules.Build
+	if v := l.Lint([]byte(src)); len(v) != 0 {
+		t.Errorf("expected no violations (configure-file line_length:120), got %v", v)
+	}
+	if v := l.Lint([]byte(long)); len(v) != 1 {
+		t.Errorf("expected one MD013 violation without the comment, got %v", v)
+	}
+	if len(l.Rules) != 1 || l.Rules[0].(rules.MD013).LineLength != 0 {
+		t.Errorf("expected the linter's rules to be left unchanged, got %#v", l.Rules)
+	}
+}
+
+func TestConfigureFile(t *testing.T) {
+	src := "# Title\n\n<!-- markdownlint-configure-file {\n  \"MD013\": { \"line_length\": 120 },\n  \"no-hard-tabs\": false\n} -->\n"
+	got, line := lint.ConfigureFile([]byte(src))
+	want := map[string]interface{}{
+		"MD013":        map[string]interface{}{"line_length": float64(120)},
+		"no-hard-tabs": false,
+	}
+	if !reflect.DeepEqual(got, want) || line != 3 {
+		t.Errorf("ConfigureFile() = %v, %d, want %v, 3", got, line, want)
+	}
+	for _, src := range []string{"# Title\n", "<!-- markdownlint-configure-file { not json } -->\n"} {
+		if got, line := lint.ConfigureFile([]byte(src)); got != nil || line != 0 {
+			t.Errorf("ConfigureFile(%q) = %v, %d, want nil, 0", src, got, line)
+		}
+	}
+}
+
+func TestInlineDisable_ConfigureFile_RuleOptionsWithoutBuildRule(t *testing.T) {
+	// Without BuildRule, options objects cannot be applied and are ignored.
+	src := "<!-- markdownlint-configure-file { \"MD013\": { \"line_length\": 120 } } -->\n" + strings.Repeat("word ", 19) + "word\n"
+	l := lint.NewLinter(rules.MD013{})
+	if v := l.Lint([]byte(src)); len(v) != 1 {
+		t.Errorf("expected one MD013 violation, got %v", v)
+	}
+}
+
+func TestInlineDisable_ConfigureFile_EnablesRule(t *testing.T) {
+	// configure-file can enable a rule the linter does not run.
+	src := "<!-- markdownlint-configure-file { \"MD013\": true } -->\n" + strings.Repeat("word ", 19) + "word\n"
+	l := lint.NewLinter(rules.MD001{})
+	l.BuildRule = rules.Build
+	v := l.Lint([]byte(src))
+	if len(v) != 1 || v[0].Rule != "MD013" {
+		t.Errorf("expected one MD013 violation, got %v", v)
+	}
+}
+
+func TestInlineDisable_ConfigureFile_InvalidOptions(t *testing.T) {
+	// Options the rule cannot accept leave the configured rule in place.
+	src := "<!-- markdownlint-configure-file { \"MD013\": { \"line_length\": \"long\" } } -->\n" + strings.Repeat("word ", 19) + "word\n"
+	l := lint.NewLinter(rules.MD013{LineLength: 120})
+	l.BuildRule = rules.Build
+	if v := l.Lint([]byte(src)); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+}
+
+func TestFix_ConfigureFile_RuleOptions(t *testing.T) {
+	// Fixes use the options from configure-file as well.
+	src := "<!-- markdownlint-configure-file { \"MD049\": { \"style\": \"underscore\" } } -->\n\nSome *text*\n"
+	l := lint.NewLinter(rules.MD049{})
+	l.BuildRule = rules.Build
+	want := "<!-- markdownlint-configure-file { \"MD049\": { \"style\": \"underscore\" } } -->\n\nSome _text_\n"
+	if got := string(l.Fix([]byte(src))); got != want {
+		t.Errorf("Fix() = %q, want %q", got, want)
+	}
+}
+
 func TestInlineDisable_DisableByAlias(t *testing.T) {
 	// Inline markdownlint-disable should accept rule aliases.
 	src := "<!-- markdownlint-disable heading-increment -->\n# Heading 1\n\n### Heading 3\n"
@@ -1913,6 +2240,71 @@ func TestInlineDisable_ConfigureFile_DoesNotDisableOtherRules(t *testing.T) {
 	}
 }
 
+func TestGL006(t *testing.T) {
+	src := "# Heading 1\n" +
+		"\n" +
+		"<!-- markdownlint-disable-next-line MD001 -- legacy heading -->\n" +
+		"### Heading 3\n" +
+		"\n" +
+		"<!-- markdownlint-disable MD009 no-such-rule -->\n" +
+		"Short line <!-- markdownlint-disable-line line-length -- long URL -->\n" +
+		"<!-- markdownlint-enable -->\n" +
+		"\n" +
+		"<!-- markdownlint-disable -->\n" +
+		"Trailing spaces   \n"
+	l := lint.NewLinter(rules.MD001{}, rules.MD009{}, rules.MD013{}, rules.GL006{RequireReason: true})
+	want := []struct {
+		line, column int
+		msg          string
+	}{
+		{6, 1, "Unknown rule in markdownlint-disable: no-such-rule"},
+		{6, 1, "markdownlint-disable suppresses no violations of MD009"},
+		{6, 1, `markdownlint-disable should give a reason after "--"`},
+		{7, 12, "markdownlint-disable-line suppresses no violations of line-length"},
+		{10, 1, "markdownlint-disable has no matching markdownlint-enable"},
+		{10, 1, `markdownlint-disable should give a reason after "--"`},
+	}
+	got := l.Lint([]byte(src))
+	if len(got) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Rule != "GL006" || got[i].Line != w.line || got[i].Column != w.column || !strings.Contains(got[i].Message, "["+w.msg+"]") {
+			t.Errorf("violation %d: got %d:%d %s %q, want %d:%d %q", i, got[i].Line, got[i].Column, got[i].Rule, got[i].Message, w.line, w.column, w.msg)
+		}
+	}
+}
+
+func TestGL006_Options(t *testing.T) {
+	src := "<!-- markdownlint-disable-file -->\n<!-- markdownlint-disable-next-line MD009 -->\nText\n"
+
+	v := lint.NewLinter(rules.MD009{}, rules.GL006{}).Lint([]byte(src))
+	if len(v) != 2 || !strings.Contains(v[0].Message, "markdownlint-disable-file suppresses no violations]") ||
+		!strings.Contains(v[1].Message, "markdownlint-disable-next-line suppresses no violations of MD009]") {
+		t.Errorf("expected two unused comments, got %+v", v)
+	}
+
+	v = lint.NewLinter(rules.MD009{}, rules.GL006{AllowUnused: true, RequireSpecific: true}).Lint([]byte(src))
+	if len(v) != 1 || v[0].Line != 1 || !strings.Contains(v[0].Message, "markdownlint-disable-file should name the rules it disables") {
+		t.Errorf("expected a non-specific comment on line 1, got %+v", v)
+	}
+
+	// Comments suppressing GL006 itself are never reported as unused.
+	src = "<!-- markdownlint-disable-next-line GL006 -->\n<!-- markdownlint-disable MD009 -->\n"
+	if v := lint.NewLinter(rules.MD009{}, rules.GL006{}).Lint([]byte(src)); len(v) != 0 {
+		t.Errorf("expected the GL006 violation to be suppressed, got %+v", v)
+	}
+}
+
+func TestInlineDisable_Reason(t *testing.T) {
+	// The reason after "--" is not read as a list of rules.
+	src := "# Heading 1\n<!-- markdownlint-disable-next-line MD001 -- kept from the old site -->\n### Heading 3\n\nText   \n"
+	v := lint.NewLinter(rules.MD001{}, rules.MD009{}).Lint([]byte(src))
+	if len(v) != 1 || v[0].Rule != "MD009" {
+		t.Errorf("expected only the MD009 violation, got %+v", v)
+	}
+}
+
 func TestNoInlineConfig_IgnoresDisableComment(t *testing.T) {
 	// When NoInlineConfig is true, inline disable comments are ignored.
 	src := "<!-- markdownlint-disable MD001 -->\n# Heading 1\n\n### Heading 3\n"
@@ -2202,6 +2594,295 @@ func TestFrontMatter_Fix_PreservesFrontMatter(t *testing.T) {
 	}
 }
 
+// docRule records the document it checks.
+type docRule struct{ doc **lint.Document }
+
+func (docRule) ID() string          { return "DOC" }
+func (docRule) Description() string { return "Record the document" }
+func (r docRule) Check(doc *lint.Document) []lint.Violation {
+	*r.doc = doc
+	return nil
+}
+
+// parseDoc returns the document the linter parses from source.
+func parseDoc(t *testing.T, source string) *lint.Document {
+	t.Helper()
+	var doc *lint.Document
+	lint.NewLinter(docRule{&doc}).Lint([]byte(source))
+	if doc == nil {
+		t.Fatal("rule was not run")
+	}
+	return doc
+}
+
+func TestFrontMatter_YAMLTree(t *testing.T) {
+	src := "---\ntitle: My Page\ndate: 2024-01-02\ndraft: false\n" +
+		"tags:\n  - go\n  - lint\nparams:\n  author: Jane\n" +
+		"summary: |\n  Line one\n  Line two\n---\n\n# Heading\n"
+	doc := parseDoc(t, src)
+	if doc.FrontMatterFormat != lint.FrontMatterYAML || doc.FrontMatterError != nil {
+		t.Fatalf("format = %q, error = %v", doc.FrontMatterFormat, doc.FrontMatterError)
+	}
+	want := map[string]interface{}{
+		"title":   "My Page",
+		"date":    "2024-01-02",
+		"draft":   false,
+		"tags":    []interface{}{"go", "lint"},
+		"params":  map[string]interface{}{"author": "Jane"},
+		"summary": "Line one\nLine two\n",
+	}
+	if !reflect.DeepEqual(doc.FrontMatter, want) {
+		t.Errorf("FrontMatter = %#v, want %#v", doc.FrontMatter, want)
+	}
+	if got := doc.FrontMatterFields["title"]; got != "My Page" {
+		t.Errorf("FrontMatterFields[title] = %q", got)
+	}
+	if _, ok := doc.FrontMatterFields["params"]; ok {
+		t.Error("expected nested mappings to be left out of FrontMatterFields")
+	}
+	if doc.FrontMatterLines != 13 {
+		t.Errorf("FrontMatterLines = %d, want 13", doc.FrontMatterLines)
+	}
+	for path, line := range map[string]int{"title": 2, "tags.1": 7, "params.author": 9} {
+		if got := doc.FrontMatterKeyLines[path]; got != line {
+			t.Errorf("FrontMatterKeyLines[%q] = %d, want %d", path, got, line)
+		}
+	}
+}
+
+func TestFrontMatter_TOMLTree(t *testing.T) {
+	src := "+++\n" +
+		"title = \"My Page\" # comment\n" +
+		"date = 2024-01-02T15:04:05Z\n" +
+		"weight = 1_000\n" +
+		"ratio = 0.5\n" +
+		"tags = [\"go\",\n  'lint',\n]\n" +
+		"summary = \"\"\"\nLine one\nLine two\"\"\"\n" +
+		"point = { x = 1, y.z = 2 }\n" +
+		"[params]\n" +
+		"author.name = \"Jane\\u00e9\"\n" +
+		"[[menu]]\nname = \"a\"\n[[menu]]\nname = \"b\"\n" +
+		"+++\n\n# Heading\n"
+	doc := parseDoc(t, src)
+	if doc.FrontMatterFormat != lint.FrontMatterTOML || doc.FrontMatterError != nil {
+		t.Fatalf("format = %q, error = %v", doc.FrontMatterFormat, doc.FrontMatterError)
+	}
+	want := map[string]interface{}{
+		"title":   "My Page",
+		"date":    "2024-01-02T15:04:05Z",
+		"weight":  int64(1000),
+		"ratio":   0.5,
+		"tags":    []interface{}{"go", "lint"},
+		"summary": "Line one\nLine two",
+		"point":   map[string]interface{}{"x": int64(1), "y": map[string]interface{}{"z": int64(2)}},
+		"params":  map[string]interface{}{"author": map[string]interface{}{"name": "Jane\u00e9"}},
+		"menu": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+		},
+	}
+	if !reflect.DeepEqual(doc.FrontMatter, want) {
+		t.Errorf("FrontMatter = %#v, want %#v", doc.FrontMatter, want)
+	}
+	if doc.FrontMatterLines != 19 {
+		t.Errorf("FrontMatterLines = %d, want 19", doc.FrontMatterLines)
+	}
+	for path, line := range map[string]int{"title": 2, "params": 13, "params.author.name": 14, "menu.1": 17, "menu.1.name": 18} {
+		if got := doc.FrontMatterKeyLines[path]; got != line {
+			t.Errorf("FrontMatterKeyLines[%q] = %d, want %d", path, got, line)
+		}
+	}
+	// The front matter is blanked out for rules.
+	if v := lintString(t, rules.MD041{}, src); len(v) != 0 {
+		t.Errorf("expected no MD041 violations, got %v", v)
+	}
+}
+
+func TestFrontMatter_Errors(t *testing.T) {
+	// Each level of aliases multiplies the values of the one before it.
+	laughs := "a0: &a0 [" + strings.TrimSuffix(strings.Repeat("x, ", 10), ", ") + "]"
+	for i := 1; i <= 7; i++ {
+		laughs += fmt.Sprintf(", a%d: &a%d [%s]", i, i, strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*a%d, ", i-1), 10), ", "))
+	}
+	tests := []struct {
+		name string
+		src  string
+		line int
+	}{
+		{"yaml syntax", "---\ntitle: ok\nauthor: a: b\n---\n\n# H\n", 3},
+		{"yaml indentation", "---\ntitle: a\n  author: b\n---\n\n# H\n", 3},
+		{"yaml list", "---\n- a\n- b\n---\n\n# H\n", 2},
+		{"toml value", "+++\ntitle = \"ok\"\ndraft = yes\n+++\n\n# H\n", 3},
+		{"toml duplicate key", "+++\na = 1\n\na = 2\n+++\n\n# H\n", 4},
+		{"toml duplicate table", "+++\n[a]\nx = 1\n[a]\n+++\n\n# H\n", 4},
+		{"toml unterminated string", "+++\na = \"b\n+++\n\n# H\n", 2},
+		{"yaml recursive anchor", "---\ntitle: ok\na: &x [*x]\n---\n\n# H\n", 3},
+		{"yaml alias expansion", "---\nlaughs: {" + laughs + "}\n---\n\n# H\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseDoc(t, tt.src)
+			if doc.FrontMatterError == nil {
+				t.Fatalf("expected a front matter error, got %#v", doc.FrontMatter)
+			}
+			v := lintString(t, rules.GL002{}, tt.src)
+			if len(v) != 1 || v[0].Line != tt.line {
+				t.Errorf("expected one GL002 violation on line %d, got %v", tt.line, v)
+			}
+		})
+	}
+	if v := lintString(t, rules.GL002{}, "---\ntitle: ok\n---\n\n# H\n"); len(v) != 0 {
+		t.Errorf("expected no GL002 violations for valid front matter, got %v", v)
+	}
+}
+
+func TestFrontMatter_CustomPatternNotReported(t *testing.T) {
+	// Blocks matched by a custom pattern may not be YAML at all.
+	l := lint.NewLinter(rules.GL002{})
+	l.FrontMatterRegexp = regexp.MustCompile(`(?s)^<!--.*?-->\n`)
+	if v := l.Lint([]byte("<!--\nnot: [yaml\n-->\n\n# H\n")); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+}
+
+func TestFrontMatterTitle_Nested(t *testing.T) {
+	src := "+++\n[params]\ntitle = \"My Page\"\n+++\n\nText\n"
+	if v := lintString(t, rules.MD041{FrontMatterTitle: "params.title"}, src); len(v) != 0 {
+		t.Errorf("expected a nested title to satisfy MD041, got %v", v)
+	}
+	if v := lintString(t, rules.MD041{FrontMatterTitle: `^\s*params\.title\s*[:=]`}, src); len(v) != 0 {
+		t.Errorf("expected a regex to match the nested title, got %v", v)
+	}
+	if v := lintString(t, rules.MD041{FrontMatterTitle: "title"}, "---\nparams:\n  author: x\n---\n\nText\n"); len(v) != 1 {
+		t.Errorf("expected one MD041 violation without a title, got %v", v)
+	}
+}
+
+// gl003Schema builds GL003 from a schema written as JSON.
+func gl003Schema(t *testing.T, schema string) lint.Rule {
+	t.Helper()
+	var options map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &options); err != nil {
+		t.Fatal(err)
+	}
+	r, err := rules.Build("front-matter-schema", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestGL003(t *testing.T) {
+	rule := gl003Schema(t, `{
+		"required": ["title", "date"],
+		"additional_properties": false,
+		"properties": {
+			"title": {"type": "string"},
+			"date": {"type": "date", "format": "2006-01-02"},
+			"draft": {"type": "boolean"},
+			"status": {"enum": ["draft", "published"]},
+			"weight": {"type": "integer"},
+			"slug": {"type": "string", "pattern": "^[a-z0-9-]+$"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"author": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {"name": {"type": "string"}}
+			}
+		}
+	}`)
+	tests := []struct {
+		name string
+		src  string
+		want []string // "line: message"
+	}{
+		{
+			"valid",
+			"---\ntitle: Post\ndate: 2024-01-02\ndraft: false\nstatus: draft\nweight: 3\n" +
+				"slug: my-post\ntags: [a, b]\nauthor:\n  name: Jane\n---\n\n# Post\n",
+			nil,
+		},
+		{
+			"valid toml",
+			"+++\ntitle = \"Post\"\ndate = 2024-01-02\n[author]\nname = \"Jane\"\n+++\n\n# Post\n",
+			nil,
+		},
+		{
+			"no front matter",
+			"# Post\n",
+			[]string{
+				`1: Missing required front matter key "title"`,
+				`1: Missing required front matter key "date"`,
+			},
+		},
+		{
+			"misspelled key",
+			"---\ntitel: Post\ndate: 2024-01-02\n---\n\n# Post\n",
+			[]string{
+				`1: Missing required front matter key "title"`,
+				`2: Unexpected front matter key "titel"`,
+			},
+		},
+		{
+			"types",
+			"---\ntitle: Post\ndate: 2024-01-02\ndraft: \"no\"\nweight: 1.5\n---\n\n# Post\n",
+			[]string{
+				`4: Front matter key "draft": expected boolean, got string`,
+				`5: Front matter key "weight": expected integer, got number`,
+			},
+		},
+		{
+			"enum and pattern",
+			"---\ntitle: Post\ndate: 2024-01-02\nstatus: done\nslug: My Post\n---\n\n# Post\n",
+			[]string{
+				`4: Front matter key "status": "done" is not one of ["draft", "published"]`,
+				`5: Front matter key "slug": "My Post" does not match pattern "^[a-z0-9-]+$"`,
+			},
+		},
+		{
+			"date format",
+			"---\ntitle: Post\ndate: 02/01/2024\n---\n\n# Post\n",
+			[]string{`3: Front matter key "date": "02/01/2024" does not match date format "2006-01-02"`},
+		},
+		{
+			"nested",
+			"---\ntitle: Post\ndate: 2024-01-02\ntags:\n  - a\n  - 2\nauthor:\n  email: x\n---\n\n# Post\n",
+			[]string{
+				`6: Front matter key "tags.1": expected string, got integer`,
+				`7: Front matter key "author": missing required key "name"`,
+			},
+		},
+		{
+			"invalid front matter is left to GL002",
+			"---\ntitle: a: b\n---\n\n# Post\n",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range lintString(t, rule, tt.src) {
+				got = append(got, fmt.Sprintf("%d: %s", v.Line, v.Message))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGL003_DefaultDateFormats(t *testing.T) {
+	rule := gl003Schema(t, `{"properties": {"date": {"type": "date"}}}`)
+	for _, date := range []string{"2024-01-02", "2024-01-02T15:04:05", "2024-01-02T15:04:05+02:00", "2024-01-02 15:04:05"} {
+		if v := lintString(t, rule, "---\ndate: "+date+"\n---\n\n# Post\n"); len(v) != 0 {
+			t.Errorf("expected %s to be a date, got %v", date, v)
+		}
+	}
+	if v := lintString(t, rule, "---\ndate: yesterday\n---\n\n# Post\n"); len(v) != 1 {
+		t.Errorf("expected one violation for a non-date, got %v", v)
+	}
+}
+
 // --- New option tests ---
 
 func TestMD001_FrontMatterTitle(t *testing.T) {
@@ -2693,6 +3374,232 @@ func TestMD051_ReferenceDefinition(t *testing.T) {
 	}
 }
 
+func TestMD051_UnicodeHeading(t *testing.T) {
+	// Non-Latin headings keep their letters, and encoded fragments match too.
+	src := "# 日本語の見出し\n\n## Über Größe\n\n[a](#日本語の見出し) [b](#über-größe) [c](#%C3%BCber-gr%C3%B6%C3%9Fe)\n"
+	v := lintString(t, rules.MD051{}, src)
+	if len(v) != 0 {
+		t.Errorf("expected no violations for Unicode anchors, got %v", v)
+	}
+}
+
+func TestMD051_DuplicateHeadings(t *testing.T) {
+	// Duplicate headings get -1, -2 suffixes, like on GitHub.
+	src := "# Usage\n\n## Usage\n\n## Usage\n\n[a](#usage) [b](#usage-1) [c](#usage-2) [d](#usage-3)\n"
+	v := lintString(t, rules.MD051{}, src)
+	if len(v) != 1 || !strings.Contains(v[0].Message, "#usage-3") {
+		t.Errorf("expected 1 violation for #usage-3, got %v", v)
+	}
+}
+
+func TestMD051_CustomHeadingID(t *testing.T) {
+	// Explicit {#id} attributes replace the generated anchor.
+	src := "# Introduction {#intro}\n\n## Setup {: #setup-guide .class}\n\n[a](#intro) [b](#setup-guide) [c](#introduction)\n"
+	v := lintString(t, rules.MD051{}, src)
+	if len(v) != 1 || !strings.Contains(v[0].Message, "#introduction") {
+		t.Errorf("expected 1 violation for #introduction, got %v", v)
+	}
+}
+
+func TestMD051_Slugger(t *testing.T) {
+	src := "# Getting Started\n\n## Getting Started\n\n[a](#getting-started_1) [b](#getting-started-1)\n"
+	if v := lintString(t, rules.MD051{Slugger: "mkdocs"}, src); len(v) != 1 || !strings.Contains(v[0].Message, "#getting-started-1") {
+		t.Errorf("mkdocs: expected 1 violation for #getting-started-1, got %v", v)
+	}
+	src = "# 1. Getting Started\n\n## Getting Started\n\n[a](#getting-started) [b](#getting-started-1)\n"
+	if v := lintString(t, rules.MD051{Slugger: "pandoc"}, src); len(v) != 0 {
+		t.Errorf("pandoc: expected no violations, got %v", v)
+	}
+}
+
+func TestParseFlavor(t *testing.T) {
+	got, err := lint.ParseFlavor("gfm+footnotes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{lint.ExtTable, lint.ExtLinkify, lint.ExtFootnotes} {
+		if !got[ext] {
+			t.Errorf("expected %s in gfm+footnotes, got %v", ext, got)
+		}
+	}
+	if got, _ := lint.ParseFlavor("commonmark"); len(got) != 0 {
+		t.Errorf("expected no extensions for commonmark, got %v", got)
+	}
+	if got, _ := lint.ParseFlavor(""); !got[lint.ExtTable] || got[lint.ExtFootnotes] {
+		t.Errorf("expected the default flavor to be gfm, got %v", got)
+	}
+	for _, flavor := range []string{"markdown", "gfm+emoji"} {
+		if _, err := lint.ParseFlavor(flavor); err == nil {
+			t.Errorf("expected an error for flavor %q", flavor)
+		}
+	}
+}
+
+// deflistRule reports every definition list, to check that rules see the
+// nodes of the extensions the flavor enables.
+type deflistRule struct{}
+
+func (deflistRule) ID() string          { return "DEFLIST" }
+func (deflistRule) Description() string { return "Definition list" }
+func (deflistRule) Check(doc *lint.Document) []lint.Violation {
+	var violations []lint.Violation
+	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if _, ok := n.(*east.DefinitionList); ok && entering {
+			violations = append(violations, lint.Violation{Rule: "DEFLIST", Line: 1, Message: "Definition list"})
+		}
+		return ast.WalkContinue, nil
+	})
+	return violations
+}
+
+func TestLinter_Flavor(t *testing.T) {
+	src := []byte("Term\n: Definition\n")
+	l := lint.NewLinter(deflistRule{})
+	if v := l.Lint(src); len(v) != 0 {
+		t.Errorf("expected no definition list with the default flavor, got %v", v)
+	}
+	l.Flavor = "php-extra"
+	if v := l.Lint(src); len(v) != 1 {
+		t.Errorf("expected a definition list with php-extra, got %v", v)
+	}
+	l.Flavor = "commonmark+definition-lists"
+	if v := l.Lint(src); len(v) != 1 {
+		t.Errorf("expected a definition list with commonmark+definition-lists, got %v", v)
+	}
+}
+
+func TestMD034_Flavors(t *testing.T) {
+	// Bare URLs are reported whether or not linkify turns them into autolinks.
+	src := "# Links\n\nSee https://example.com and <https://example.org>.\n\nNote[^1].\n\n[^1]: https://example.net\n"
+	for _, flavor := range []string{"commonmark", "gfm", "gfm+footnotes"} {
+		l := lint.NewLinter(rules.MD034{})
+		l.Flavor = flavor
+		v := l.Lint([]byte(src))
+		if len(v) != 2 || v[0].Line != 3 || v[0].Column != 5 || v[1].Line != 7 {
+			t.Errorf("%s: expected bare URLs at 3:5 and on line 7, got %v", flavor, v)
+		}
+	}
+}
+
+func TestMD052_FootnotesFlavor(t *testing.T) {
+	src := "# Notes\n\nText[^1] and [^missing].\n\n[^1]: A footnote.\n"
+	l := lint.NewLinter(rules.MD052{ShortcutSyntax: true})
+	l.Flavor = "gfm+footnotes"
+	v := l.Lint([]byte(src))
+	if len(v) != 1 || !strings.Contains(v[0].Message, "^missing") {
+		t.Errorf("expected only the undefined footnote to be reported, got %v", v)
+	}
+}
+
+func TestMD051_AttributesFlavor(t *testing.T) {
+	// With the attributes extension the heading ID comes from the AST.
+	src := "# Introduction {#intro}\n\n[a](#intro) [b](#introduction)\n"
+	l := lint.NewLinter(rules.MD051{})
+	l.Flavor = "gfm+attributes"
+	v := l.Lint([]byte(src))
+	if len(v) != 1 || !strings.Contains(v[0].Message, "#introduction") {
+		t.Errorf("expected 1 violation for #introduction, got %v", v)
+	}
+}
+
+func TestGL001_CrossFileLinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	readme := filepath.Join(dir, "README.md")
+	guide := filepath.Join(dir, "docs", "guide.md")
+	files := map[string][]byte{
+		readme: []byte("# Readme\n\n" +
+			"[ok](docs/guide.md#setup) [ok](docs/guide.md) [ok](image.png)\n" +
+			"[bad fragment](docs/guide.md#install)\n" +
+			"[missing](docs/missing.md)\n" +
+			"[external](https://example.com/x.md) [local](#readme)\n"),
+		guide: []byte("# Guide\n\n## Setup\n\n[back](../README.md#readme) [escaped](../README%2Emd)\n"),
+	}
+	l := lint.NewLinter(rules.GL001{})
+	got := l.LintProject(files)
+	if len(got[guide]) != 0 {
+		t.Errorf("expected no violations in guide, got %v", got[guide])
+	}
+	v := got[readme]
+	if len(v) != 2 {
+		t.Fatalf("expected 2 violations in README, got %d: %v", len(v), v)
+	}
+	if v[0].Line != 4 || !strings.Contains(v[0].Message, "Fragment not found: docs/guide.md#install") {
+		t.Errorf("unexpected first violation: %+v", v[0])
+	}
+	if v[0].Column != 1 || v[0].EndColumn != 38 {
+		t.Errorf("expected the range to cover the link, got columns %d-%d", v[0].Column, v[0].EndColumn)
+	}
+	if v[1].Line != 5 || !strings.Contains(v[1].Message, "File not found: docs/missing.md") {
+		t.Errorf("unexpected second violation: %+v", v[1])
+	}
+
+	// Project rules report nothing when linting a single document.
+	if v := l.Lint(files[readme]); len(v) != 0 {
+		t.Errorf("expected Lint to skip project checks, got %v", v)
+	}
+}
+
+func TestGL001_InlineDisable(t *testing.T) {
+	files := map[string][]byte{
+		"a.md": []byte("# A\n\n<!-- markdownlint-disable-next-line cross-file-links -->\n[x](b.md#nope)\n[y](b.md#nope)\n"),
+		"b.md": []byte("# B\n"),
+	}
+	v := lint.NewLinter(rules.GL001{}).LintProject(files)["a.md"]
+	if len(v) != 1 || v[0].Line != 5 {
+		t.Errorf("expected only the line 5 violation, got %v", v)
+	}
+}
+
+// countingProjectRule reports line 1 of every document it sees, with its
+// name and the number of documents, and counts its runs in runs.
+type countingProjectRule struct {
+	Name string `json:"name"`
+	runs *int
+}
+
+func (r countingProjectRule) ID() string                            { return "COUNT" }
+func (r countingProjectRule) Aliases() []string                     { return nil }
+func (r countingProjectRule) Tags() []string                        { return nil }
+func (r countingProjectRule) Description() string                   { return "Counting project rule" }
+func (r countingProjectRule) Check(*lint.Document) []lint.Violation { return nil }
+
+func (r countingProjectRule) CheckProject(docs []*lint.Document) map[string][]lint.Violation {
+	*r.runs++
+	result := make(map[string][]lint.Violation)
+	for _, doc := range docs {
+		result[doc.Path] = []lint.Violation{{Rule: "COUNT", Line: 1, Column: 1, Message: fmt.Sprintf("%s %d", r.Name, len(docs))}}
+	}
+	return result
+}
+
+func TestLintProjectFiles(t *testing.T) {
+	runs := 0
+	linters := map[string]*lint.Linter{
+		"a.md": lint.NewLinter(countingProjectRule{Name: "x", runs: &runs}),
+		"b.md": lint.NewLinter(countingProjectRule{Name: "x", runs: &runs}),
+		"c.md": lint.NewLinter(countingProjectRule{Name: "y", runs: &runs}),
+		"d.md": lint.NewLinter(),
+	}
+	files := map[string][]byte{"a.md": nil, "b.md": nil, "c.md": nil, "d.md": nil, "e.md": nil}
+	got := lint.LintProjectFiles(files, func(path string) *lint.Linter { return linters[path] })
+	// Equal rules of different linters run once; e.md has no linter.
+	if runs != 2 {
+		t.Errorf("expected 2 runs, got %d", runs)
+	}
+	want := map[string]string{"a.md": "x 4", "b.md": "x 4", "c.md": "y 4"}
+	if len(got) != len(want) {
+		t.Errorf("expected violations for %v, got %v", want, got)
+	}
+	for path, msg := range want {
+		if v := got[path]; len(v) != 1 || v[0].Message != msg {
+			t.Errorf("%s: expected one violation %q, got %v", path, msg, v)
+		}
+	}
+}
+
 func TestMD027_ListDepth1_FirstLineFlagged(t *testing.T) {
 	// Ordered list items directly inside a blockquote with extra spaces before
 	// the number should be flagged on the first line.
@@ -2796,3 +3703,49 @@ func TestMD013_AutoLinkInEmphasis_NoPanic(t *testing.T) {
 		t.Errorf("expected no violations for autolink in emphasis, got %d: %v", len(v), v)
 	}
 }
+
+// violationRange returns the position fields of v for comparison.
+func violationRange(v lint.Violation) [4]int {
+	return [4]int{v.Line, v.Column, v.EndLine, v.EndColumn}
+}
+
+func TestViolationRange_DefaultsToEndOfLine(t *testing.T) {
+	// MD041 does not set an end position; the linter extends it to the end
+	// of the line.
+	v := lintString(t, rules.MD041{}, "Some text\n")
+	if len(v) != 1 {
+		t.Fatalf("expected 1 violation, got %d", len(v))
+	}
+	if got, want := violationRange(v[0]), [4]int{1, 1, 1, 10}; got != want {
+		t.Errorf("range = %v, want %v", got, want)
+	}
+}
+
+func TestViolationRange_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule lint.Rule
+		src  string
+		want [4]int
+	}{
+		{"MD010 tab run", rules.MD010{}, "a\t\tb\n", [4]int{1, 2, 1, 4}},
+		{"MD034 bare URL", rules.MD034{}, "# T\n\nSee https://example.com now\n", [4]int{3, 5, 3, 24}},
+		{"MD042 empty link", rules.MD042{}, "# T\n\nA [link](#) here\n", [4]int{3, 3, 3, 12}},
+		{"MD044 proper name", rules.MD044{Names: []string{"JavaScript"}}, "# T\n\nUse javascript.\n", [4]int{3, 5, 3, 15}},
+		{"MD051 fragment", rules.MD051{}, "# T\n\nSee [x](#missing).\n", [4]int{3, 5, 3, 18}},
+		{"MD040 info string", rules.MD040{AllowedLanguages: []string{"go"}}, "```python\nx\n```\n", [4]int{1, 4, 1, 10}},
+		{"MD049 opening marker", rules.MD049{Style: "asterisk"}, "a _b_ c\n", [4]int{1, 3, 1, 4}},
+		{"MD033 inline HTML", rules.MD033{}, "# T\n\nText <b>bold</b>\n", [4]int{3, 6, 3, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := lintString(t, tt.rule, tt.src)
+			if len(v) == 0 {
+				t.Fatal("expected a violation")
+			}
+			if got := violationRange(v[0]); got != tt.want {
+				t.Errorf("range = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
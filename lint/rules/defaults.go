@@ -2,14 +2,9 @@ package rules
 
 import "github.com/mrueg/goldmark-lint/lint"
 
-// DefaultRules returns a slice of all lint rules with their default settings.
-// This is the standard set of rules used by the goldmark-lint CLI tool.
-// It is intended for use by library consumers who want to create a [lint.Linter]
-// with all rules enabled:
-//
-//	linter := lint.NewLinter(rules.DefaultRules()...)
-func DefaultRules() []lint.Rule {
-	return []lint.Rule{
+// init registers the built-in rules, in the order in which they run.
+func init() {
+	for _, rule := range []lint.Rule{
 		MD001{},
 		MD003{},
 		MD004{},
@@ -63,13 +58,43 @@ func DefaultRules() []lint.Rule {
 		MD058{},
 		MD059{},
 		MD060{},
+		GL002{},
+	} {
+		Register(rule)
+	}
+	RegisterOptIn(GL001{})
+	RegisterOptIn(GL003{})
+	RegisterOptIn(GL004{})
+	RegisterOptIn(GL005{})
+	RegisterOptIn(GL006{})
+}
+
+// DefaultRules returns a slice of all registered rules with their default
+// settings, leaving out opt-in rules. This is the standard set of rules used
+// by the goldmark-lint CLI tool, plus any rules registered with Register.
+// It is intended for use by library consumers who want to create a [lint.Linter]
+// with all rules enabled:
+//
+//	linter := lint.NewLinter(rules.DefaultRules()...)
+func DefaultRules() []lint.Rule {
+	var result []lint.Rule
+	for _, e := range All() {
+		if e.OptIn {
+			continue
+		}
+		rule, _ := e.Build(nil)
+		result = append(result, rule)
 	}
+	return result
 }
 
 // NewDefaultLinter creates a [lint.Linter] with all rules enabled at their
 // default settings. It is equivalent to:
 //
-//	lint.NewLinter(rules.DefaultRules()...)
+//	l := lint.NewLinter(rules.DefaultRules()...)
+//	l.BuildRule = rules.Build
 func NewDefaultLinter() *lint.Linter {
-	return lint.NewLinter(DefaultRules()...)
+	l := lint.NewLinter(DefaultRules()...)
+	l.BuildRule = Build
+	return l
 }
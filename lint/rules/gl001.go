@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/yuin/goldmark/ast"
+)
+
+// GL001 checks that relative links to other files point to existing files
+// and, for links to documents of the same lint run, to existing headings.
+// It is a lint.ProjectRule and reports nothing from Check.
+type GL001 struct {
+	// Slugger names the algorithm that generates heading anchors, as for
+	// MD051.
+	Slugger string `json:"slugger"`
+}
+
+func (r GL001) ID() string          { return "GL001" }
+func (r GL001) Aliases() []string   { return []string{"cross-file-links"} }
+func (r GL001) Tags() []string      { return []string{"links"} }
+func (r GL001) Description() string { return "Links to other files should be valid" }
+
+// gl001SchemeRE matches the scheme of an absolute URL such as https: or mailto:.
+var gl001SchemeRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+
+func (r GL001) Check(doc *lint.Document) []lint.Violation { return nil }
+
+func (r GL001) CheckProject(docs []*lint.Document) map[string][]lint.Violation {
+	byPath := make(map[string]*lint.Document, len(docs))
+	for _, doc := range docs {
+		byPath[filepath.Clean(doc.Path)] = doc
+	}
+	// Anchors are computed lazily, only for documents that links point into.
+	anchors := make(map[*lint.Document]map[string]bool)
+
+	result := make(map[string][]lint.Violation)
+	for _, doc := range docs {
+		dir := filepath.Dir(doc.Path)
+		_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			link, ok := n.(*ast.Link)
+			if !ok {
+				return ast.WalkContinue, nil
+			}
+			dest := string(link.Destination)
+			target, fragment, ok := gl001SplitDestination(dest)
+			if !ok {
+				return ast.WalkContinue, nil
+			}
+			path := filepath.Clean(filepath.Join(dir, filepath.FromSlash(target)))
+			targetDoc := byPath[path]
+			if targetDoc == nil {
+				if _, err := os.Stat(path); err != nil {
+					result[doc.Path] = append(result[doc.Path], linkViolation(doc, r.ID(), link,
+						r.Description()+" [File not found: "+target+"]"))
+				}
+				// Fragments are only checked in documents of the lint run.
+				return ast.WalkContinue, nil
+			}
+			if fragment == "" || fragment == "top" || md051LineRefRE.MatchString(fragment) {
+				return ast.WalkContinue, nil
+			}
+			if anchors[targetDoc] == nil {
+				anchors[targetDoc] = documentAnchors(targetDoc, r.Slugger, false)
+			}
+			if !anchorExists(anchors[targetDoc], fragment, false) {
+				result[doc.Path] = append(result[doc.Path], linkViolation(doc, r.ID(), link,
+					r.Description()+" [Fragment not found: "+target+"#"+fragment+"]"))
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+	return result
+}
+
+// gl001SplitDestination splits a link destination into the unescaped path of
+// the file it points to and its fragment. ok is false for destinations that do
+// not refer to another file by relative path: absolute URLs, site-absolute
+// paths, and fragment-only links (which MD051 checks).
+func gl001SplitDestination(dest string) (path, fragment string, ok bool) {
+	if dest == "" || gl001SchemeRE.MatchString(dest) || strings.HasPrefix(dest, "/") {
+		return "", "", false
+	}
+	path, fragment, _ = strings.Cut(dest, "#")
+	path, _, _ = strings.Cut(path, "?")
+	if path == "" {
+		return "", "", false
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	return path, fragment, true
+}
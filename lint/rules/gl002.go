@@ -0,0 +1,29 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// GL002 reports syntax errors in YAML and TOML front matter, which would
+// otherwise make a site generator fail or silently drop the metadata.
+type GL002 struct{}
+
+func (r GL002) ID() string          { return "GL002" }
+func (r GL002) Aliases() []string   { return []string{"front-matter-syntax"} }
+func (r GL002) Tags() []string      { return []string{"front_matter"} }
+func (r GL002) Description() string { return "Front matter should be valid" }
+
+func (r GL002) Check(doc *lint.Document) []lint.Violation {
+	fmErr := doc.FrontMatterError
+	if fmErr == nil {
+		return nil
+	}
+	return []lint.Violation{{
+		Rule:    r.ID(),
+		Line:    fmErr.Line,
+		Column:  1,
+		Message: "Invalid " + strings.ToUpper(doc.FrontMatterFormat) + " front matter: " + fmErr.Message,
+	}}
+}
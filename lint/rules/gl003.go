@@ -0,0 +1,278 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// GL003 validates a document's front matter against a schema: the keys it
+// must have, the keys it may have, and the values they take. It is opt-in,
+// as it needs a schema from the config; per-glob schemas come from
+// overrides. Documents whose front matter does not parse are left to GL002.
+type GL003 struct {
+	FrontMatterSchema
+}
+
+// FrontMatterSchema describes a front matter value. All constraints are
+// optional; a value must meet every constraint that is set.
+type FrontMatterSchema struct {
+	// Type is the type of the value: "string", "number", "integer",
+	// "boolean", "date", "array", or "object".
+	Type string `json:"type,omitempty" enum:"string,number,integer,boolean,date,array,object"`
+	// Enum lists the values allowed.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Pattern is a regular expression that string values must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Format is the Go time layout, such as "2006-01-02", that values of
+	// type "date" must follow. If empty, dates such as 2024-01-02,
+	// 2024-01-02T15:04:05, and RFC 3339 timestamps are accepted.
+	Format string `json:"format,omitempty"`
+	// Items is the schema of the elements of an array.
+	Items *FrontMatterSchema `json:"items,omitempty"`
+	// Required lists the keys an object must have.
+	Required []string `json:"required,omitempty"`
+	// Properties maps the keys of an object to their schemas.
+	Properties map[string]*FrontMatterSchema `json:"properties,omitempty"`
+	// AdditionalProperties controls whether an object may have keys not
+	// listed in Properties (default true).
+	AdditionalProperties *bool `json:"additional_properties,omitempty"`
+}
+
+func (r GL003) ID() string          { return "GL003" }
+func (r GL003) Aliases() []string   { return []string{"front-matter-schema"} }
+func (r GL003) Tags() []string      { return []string{"front_matter"} }
+func (r GL003) Description() string { return "Front matter should match the schema" }
+
+// gl003DateLayouts are the date formats accepted for type "date" without a
+// Format.
+var gl003DateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05Z07:00",
+}
+
+func (r GL003) Check(doc *lint.Document) []lint.Violation {
+	if doc.FrontMatterError != nil {
+		return nil
+	}
+	c := gl003Checker{rule: r.ID(), doc: doc}
+	fm := doc.FrontMatter
+	if fm == nil {
+		fm = map[string]interface{}{}
+	}
+	c.check("", fm, &r.FrontMatterSchema)
+	return c.violations
+}
+
+// gl003Checker collects the violations for one document.
+type gl003Checker struct {
+	rule       string
+	doc        *lint.Document
+	violations []lint.Violation
+}
+
+// report adds a violation for the value at path, on the line of its key or,
+// failing that, of the nearest enclosing key.
+func (c *gl003Checker) report(path, format string, args ...interface{}) {
+	line := 1
+	for p := path; p != ""; {
+		if l, ok := c.doc.FrontMatterKeyLines[p]; ok {
+			line = l
+			break
+		}
+		i := strings.LastIndexByte(p, '.')
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	c.violations = append(c.violations, lint.Violation{
+		Rule:    c.rule,
+		Line:    line,
+		Column:  1,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check validates v, the value at path, against s.
+func (c *gl003Checker) check(path string, v interface{}, s *FrontMatterSchema) {
+	if s == nil {
+		return
+	}
+	name := path
+	if name == "" {
+		name = "front matter"
+	} else {
+		name = "Front matter key " + strconv.Quote(name)
+	}
+	if s.Type != "" && !gl003HasType(v, s.Type) {
+		c.report(path, "%s: expected %s, got %s", name, s.Type, gl003TypeName(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			found = found || gl003Equal(v, e)
+		}
+		if !found {
+			c.report(path, "%s: %s is not one of %s", name, gl003Format(v), gl003FormatList(s.Enum))
+		}
+	}
+	if str, ok := v.(string); ok {
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				c.report(path, "%s: invalid pattern %q in schema: %v", name, s.Pattern, err)
+			} else if !re.MatchString(str) {
+				c.report(path, "%s: %q does not match pattern %q", name, str, s.Pattern)
+			}
+		}
+		if s.Type == "date" && !gl003IsDate(str, s.Format) {
+			if s.Format != "" {
+				c.report(path, "%s: %q does not match date format %q", name, str, s.Format)
+			} else {
+				c.report(path, "%s: %q is not a date", name, str)
+			}
+		}
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		for i, item := range v {
+			c.check(gl003JoinPath(path, strconv.Itoa(i)), item, s.Items)
+		}
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				if path == "" {
+					c.report(path, "Missing required front matter key %q", key)
+				} else {
+					c.report(path, "%s: missing required key %q", name, key)
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := gl003JoinPath(path, key)
+			if prop, ok := s.Properties[key]; ok {
+				c.check(keyPath, v[key], prop)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				c.report(keyPath, "Unexpected front matter key %q", keyPath)
+			}
+		}
+	}
+}
+
+// gl003JoinPath appends key to the dotted key path path.
+func gl003JoinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// gl003HasType reports whether v has the schema type typ.
+func gl003HasType(v interface{}, typ string) bool {
+	switch typ {
+	case "number":
+		_, isFloat := v.(float64)
+		return isFloat || gl003HasType(v, "integer")
+	case "date":
+		_, ok := v.(string)
+		return ok
+	}
+	return gl003TypeName(v) == typ
+}
+
+// gl003TypeName returns the schema type of v.
+func gl003TypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// gl003Equal reports whether the front matter value v equals e, a value from
+// the schema. Numbers compare by value, whatever their Go type.
+func gl003Equal(v, e interface{}) bool {
+	if fv, ok := gl003Float(v); ok {
+		fe, ok := gl003Float(e)
+		return ok && fv == fe
+	}
+	switch v.(type) {
+	case string, bool, nil:
+		return v == e
+	}
+	return false
+}
+
+// gl003Float converts a numeric value to float64.
+func gl003Float(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// gl003IsDate reports whether s is a date in layout, or in one of
+// gl003DateLayouts if layout is empty.
+func gl003IsDate(s, layout string) bool {
+	layouts := gl003DateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if _, err := time.Parse(l, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// gl003Format formats a value for a message.
+func gl003Format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// gl003FormatList formats the allowed values of an enum for a message.
+func gl003FormatList(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = gl003Format(v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,392 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/yuin/goldmark/ast"
+)
+
+// GL004 checks that the http and https destinations of links, images,
+// autolinks, and link reference definitions are reachable. It is a
+// lint.ProjectRule, so that every URL of a lint run is requested only once,
+// and reports nothing from Check.
+type GL004 struct {
+	// Timeout is the time limit of a single request in seconds (default 10).
+	Timeout float64 `json:"timeout"`
+	// Concurrency is the maximum number of requests in flight (default 8).
+	Concurrency int `json:"concurrency"`
+	// RateLimit is the maximum number of requests per second sent to a
+	// single host (default 2).
+	RateLimit float64 `json:"rate_limit"`
+	// Retries is the number of times a request is repeated after a network
+	// error, a 429 status, or a 5xx status (default 2). A negative value
+	// disables retries.
+	Retries int `json:"retries"`
+	// Allow lists regular expressions for the URLs to check. If empty, all
+	// URLs are checked.
+	Allow []string `json:"allow"`
+	// Deny lists regular expressions for URLs that are never checked, even
+	// if they match Allow.
+	Deny []string `json:"deny"`
+	// CacheFile is the path of a file in which results are kept between
+	// runs. If empty, results are not kept.
+	CacheFile string `json:"cache_file"`
+	// CacheTTL is how long a kept result stays valid, as a Go duration such
+	// as "12h" (default "24h").
+	CacheTTL string `json:"cache_ttl"`
+
+	// Client sends the requests. If nil, a client with Timeout is used.
+	Client *http.Client `json:"-"`
+}
+
+func (r GL004) ID() string          { return "GL004" }
+func (r GL004) Aliases() []string   { return []string{"external-links"} }
+func (r GL004) Tags() []string      { return []string{"links", "url"} }
+func (r GL004) Description() string { return "External links should be reachable" }
+
+func (r GL004) Check(doc *lint.Document) []lint.Violation { return nil }
+
+// gl004Link is an occurrence of a URL in a document.
+type gl004Link struct {
+	path      string
+	url       string // with the fragment removed
+	violation lint.Violation
+}
+
+// gl004Result is the outcome of checking a URL.
+type gl004Result struct {
+	Status  int       `json:"status,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Checked time.Time `json:"checked"`
+}
+
+// dead reports whether the result means the URL is broken. Servers that keep
+// rate limiting after all retries are given the benefit of the doubt.
+func (res gl004Result) dead() bool {
+	return res.Error != "" || res.Status >= 400 && res.Status != http.StatusTooManyRequests
+}
+
+func (res gl004Result) String() string {
+	if res.Error != "" {
+		return res.Error
+	}
+	return strconv.Itoa(res.Status) + " " + http.StatusText(res.Status)
+}
+
+func (r GL004) CheckProject(docs []*lint.Document) map[string][]lint.Violation {
+	allow, err := gl004Compile(r.Allow)
+	if err != nil {
+		return gl004ConfigError(docs, r.ID(), err)
+	}
+	deny, err := gl004Compile(r.Deny)
+	if err != nil {
+		return gl004ConfigError(docs, r.ID(), err)
+	}
+
+	var links []gl004Link
+	for _, doc := range docs {
+		for _, link := range gl004Links(doc, r.ID()) {
+			if gl004Matches(deny, link.url) || len(allow) > 0 && !gl004Matches(allow, link.url) {
+				continue
+			}
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(r.CacheTTL)
+	if err != nil || ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	cache := gl004LoadCache(r.CacheFile)
+	now := time.Now()
+	var pending []string
+	queued := make(map[string]bool)
+	for _, link := range links {
+		if res, ok := cache[link.url]; ok && now.Sub(res.Checked) < ttl || queued[link.url] {
+			continue
+		}
+		queued[link.url] = true
+		pending = append(pending, link.url)
+	}
+	for u, res := range r.checkAll(pending) {
+		cache[u] = res
+	}
+	if r.CacheFile != "" {
+		gl004SaveCache(r.CacheFile, cache, now.Add(-ttl))
+	}
+
+	result := make(map[string][]lint.Violation)
+	for _, link := range links {
+		if res := cache[link.url]; res.dead() {
+			v := link.violation
+			v.Message = r.Description() + " [" + link.url + ": " + res.String() + "]"
+			result[link.path] = append(result[link.path], v)
+		}
+	}
+	return result
+}
+
+// gl004Links returns the http and https URLs in doc, each with a violation
+// of rule positioned on it.
+func gl004Links(doc *lint.Document, rule string) []gl004Link {
+	var links []gl004Link
+	seen := make(map[string]bool)
+	add := func(dest string, v lint.Violation) {
+		u, ok := gl004URL(dest)
+		if !ok {
+			return
+		}
+		seen[dest] = true
+		links = append(links, gl004Link{path: doc.Path, url: u, violation: v})
+	}
+	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			add(string(n.Destination), linkViolation(doc, rule, n, ""))
+		case *ast.Image:
+			add(string(n.Destination), linkViolation(doc, rule, n, ""))
+		case *ast.AutoLink:
+			if n.AutoLinkType != ast.AutoLinkURL {
+				break
+			}
+			v := lint.Violation{Rule: rule, Line: inlineNodeLine(n, doc.Source), Column: 1}
+			if start, end, ok := md034AutoLinkRange(doc.Source, n); ok {
+				v = rangeViolation(doc, rule, start, end, "")
+			}
+			add(string(n.URL(doc.Source)), v)
+		}
+		return ast.WalkContinue, nil
+	})
+	// Definitions that no link uses are reported on their own line.
+	labels := make([]string, 0, len(doc.LinkRefs))
+	for label := range doc.LinkRefs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		dest := string(doc.LinkRefs[label])
+		if seen[dest] {
+			continue
+		}
+		line := 1
+		for i, l := range doc.Lines {
+			if strings.Contains(strings.ToLower(l), "["+label+"]:") {
+				line = i + 1
+				break
+			}
+		}
+		add(dest, lint.Violation{Rule: rule, Line: line, Column: 1})
+	}
+	return links
+}
+
+// gl004URL returns dest without its fragment if it is an http or https URL.
+func gl004URL(dest string) (string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	u.Fragment, u.RawFragment = "", ""
+	return u.String(), true
+}
+
+// checkAll checks urls concurrently and returns their results.
+func (r GL004) checkAll(urls []string) map[string]gl004Result {
+	client := r.Client
+	if client == nil {
+		timeout := r.Timeout
+		if timeout <= 0 {
+			timeout = 10
+		}
+		client = &http.Client{Timeout: time.Duration(timeout * float64(time.Second))}
+	}
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	rate := r.RateLimit
+	if rate <= 0 {
+		rate = 2
+	}
+	retries := r.Retries
+	if retries == 0 {
+		retries = 2
+	}
+	limiter := &gl004Limiter{interval: time.Duration(float64(time.Second) / rate), next: make(map[string]time.Time)}
+
+	results := make(map[string]gl004Result, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan string)
+	for i := 0; i < concurrency && i < len(urls); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range work {
+				res := gl004Check(client, limiter, u, retries)
+				mu.Lock()
+				results[u] = res
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, u := range urls {
+		work <- u
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+// gl004Check requests u, retrying network errors, 429, and 5xx statuses.
+// Servers often reject HEAD requests, so a failed HEAD is repeated as GET.
+func gl004Check(client *http.Client, limiter *gl004Limiter, u string, retries int) gl004Result {
+	host := u
+	if parsed, err := url.Parse(u); err == nil {
+		host = parsed.Host
+	}
+	var res gl004Result
+	for attempt := 0; ; attempt++ {
+		var retryAfter time.Duration
+		for _, method := range []string{http.MethodHead, http.MethodGet} {
+			limiter.wait(host)
+			res, retryAfter = gl004Request(client, method, u)
+			if res.Error == "" && res.Status < 400 {
+				break
+			}
+		}
+		if attempt >= retries || !(res.Error != "" || res.Status == http.StatusTooManyRequests || res.Status >= 500) {
+			break
+		}
+		if retryAfter < 0 {
+			retryAfter = time.Duration(attempt+1) * time.Second
+		}
+		time.Sleep(retryAfter)
+	}
+	res.Checked = time.Now()
+	return res
+}
+
+// gl004Request sends a single request. retryAfter is the delay the server
+// asked for in a Retry-After header, or negative if it gave none.
+func gl004Request(client *http.Client, method, u string) (res gl004Result, retryAfter time.Duration) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return gl004Result{Error: err.Error()}, -1
+	}
+	req.Header.Set("User-Agent", "goldmark-lint")
+	resp, err := client.Do(req)
+	if err != nil {
+		return gl004Result{Error: err.Error()}, -1
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	retryAfter = -1
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		retryAfter = min(time.Duration(secs)*time.Second, time.Minute)
+	}
+	return gl004Result{Status: resp.StatusCode}, retryAfter
+}
+
+// gl004Limiter spaces out the requests to each host by interval.
+type gl004Limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time // earliest time of the next request per host
+}
+
+func (l *gl004Limiter) wait(host string) {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// gl004LoadCache reads the results kept in path. A missing or unreadable
+// file gives an empty cache.
+func gl004LoadCache(path string) map[string]gl004Result {
+	cache := make(map[string]gl004Result)
+	if path == "" {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// gl004SaveCache writes the results in cache checked after expired to path.
+// The cache only speeds up later runs, so errors are ignored.
+func gl004SaveCache(path string, cache map[string]gl004Result, expired time.Time) {
+	kept := make(map[string]gl004Result, len(cache))
+	for u, res := range cache {
+		if res.Checked.After(expired) {
+			kept[u] = res
+		}
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		_ = os.MkdirAll(dir, 0755)
+	}
+	_ = os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// gl004Compile compiles the regular expressions in patterns.
+func gl004Compile(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL pattern %q: %v", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// gl004Matches reports whether u matches one of res.
+func gl004Matches(res []*regexp.Regexp, u string) bool {
+	for _, re := range res {
+		if re.MatchString(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// gl004ConfigError reports an invalid configuration on the first line of
+// every document, so that it cannot go unnoticed.
+func gl004ConfigError(docs []*lint.Document, rule string, err error) map[string][]lint.Violation {
+	result := make(map[string][]lint.Violation, len(docs))
+	for _, doc := range docs {
+		result[doc.Path] = []lint.Violation{{Rule: rule, Line: 1, Column: 1, Message: err.Error()}}
+	}
+	return result
+}
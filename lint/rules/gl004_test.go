@@ -0,0 +1,155 @@
+package rules_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+// linkServer serves the paths the tests link to and counts the requests for
+// each path.
+type linkServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func newLinkServer(t *testing.T) *linkServer {
+	s := &linkServer{hits: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		hits := s.hits[r.URL.Path]
+		s.mu.Unlock()
+		switch r.URL.Path {
+		case "/ok":
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/flaky":
+			// Fails the first HEAD and GET, then recovers.
+			if hits <= 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *linkServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+func lintLinks(t *testing.T, rule rules.GL004, files map[string]string) map[string][]lint.Violation {
+	t.Helper()
+	sources := make(map[string][]byte, len(files))
+	for path, content := range files {
+		sources[path] = []byte(content)
+	}
+	return lint.NewLinter(rule).LintProject(sources)
+}
+
+func TestGL004(t *testing.T) {
+	srv := newLinkServer(t)
+	u := srv.URL
+	files := map[string]string{
+		"a.md": "# A\n\n[ok](" + u + "/ok#section) ![img](" + u + "/missing.png)\n\n<" + u + "/gone>\n",
+		"b.md": "# B\n\n[ok again](" + u + "/ok) [flaky](" + u + "/flaky) [get][]\n\n" +
+			"[get]: " + u + "/get-only\n[unused]: " + u + "/unused\n\n[local](a.md) [mail](mailto:a@example.com)\n",
+	}
+	got := lintLinks(t, rules.GL004{Client: srv.Client(), RateLimit: 1000}, files)
+
+	if v := got["a.md"]; len(v) != 2 ||
+		v[0].Line != 3 || !strings.Contains(v[0].Message, "/missing.png: 404 Not Found") ||
+		v[1].Line != 5 || !strings.Contains(v[1].Message, "/gone: 404 Not Found") {
+		t.Errorf("a.md: unexpected violations %+v", v)
+	}
+	if v := got["b.md"]; len(v) != 1 || v[0].Line != 6 || !strings.Contains(v[0].Message, "/unused: 404") {
+		t.Errorf("b.md: unexpected violations %+v", v)
+	}
+	// Each URL is checked once per run, whatever its fragment.
+	if n := srv.count("/ok"); n != 1 {
+		t.Errorf("expected /ok to be requested once, got %d", n)
+	}
+	// The flaky URL was retried.
+	if n := srv.count("/flaky"); n != 3 {
+		t.Errorf("expected /flaky to be requested 3 times, got %d", n)
+	}
+}
+
+func TestGL004_AllowDeny(t *testing.T) {
+	srv := newLinkServer(t)
+	files := map[string]string{
+		"a.md": "# A\n\n[a](" + srv.URL + "/private/a) [b](" + srv.URL + "/public/b) [c](" + srv.URL + "/public/skip)\n",
+	}
+	rule := rules.GL004{Client: srv.Client(), RateLimit: 1000, Allow: []string{"/public/"}, Deny: []string{"/skip$"}}
+	got := lintLinks(t, rule, files)
+	if v := got["a.md"]; len(v) != 1 || !strings.Contains(v[0].Message, "/public/b") {
+		t.Errorf("expected only /public/b to be reported, got %+v", v)
+	}
+	if srv.count("/private/a") != 0 || srv.count("/public/skip") != 0 {
+		t.Error("expected URLs outside allow, or in deny, not to be requested")
+	}
+
+	rule.Deny = []string{"("}
+	got = lintLinks(t, rule, files)
+	if v := got["a.md"]; len(v) != 1 || !strings.Contains(v[0].Message, "invalid URL pattern") {
+		t.Errorf("expected an invalid pattern to be reported, got %+v", v)
+	}
+}
+
+func TestGL004_Cache(t *testing.T) {
+	srv := newLinkServer(t)
+	cacheFile := filepath.Join(t.TempDir(), "links.json")
+	files := map[string]string{"a.md": "# A\n\n[ok](" + srv.URL + "/ok) [bad](" + srv.URL + "/bad)\n"}
+	rule := rules.GL004{Client: srv.Client(), RateLimit: 1000, CacheFile: cacheFile}
+
+	for i := 0; i < 2; i++ {
+		got := lintLinks(t, rule, files)
+		if v := got["a.md"]; len(v) != 1 || !strings.Contains(v[0].Message, "/bad: 404") {
+			t.Errorf("run %d: unexpected violations %+v", i, v)
+		}
+	}
+	if n := srv.count("/ok"); n != 1 {
+		t.Errorf("expected the cached result to be reused, got %d requests", n)
+	}
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatalf("expected a cache file: %v", err)
+	}
+
+	// Expired results are checked again.
+	rule.CacheTTL = "1ns"
+	time.Sleep(time.Millisecond)
+	lintLinks(t, rule, files)
+	if n := srv.count("/ok"); n != 2 {
+		t.Errorf("expected an expired result to be checked again, got %d requests", n)
+	}
+}
+
+func TestGL004_RateLimit(t *testing.T) {
+	srv := newLinkServer(t)
+	files := map[string]string{
+		"a.md": "# A\n\n[a](" + srv.URL + "/ok?a) [b](" + srv.URL + "/ok?b) [c](" + srv.URL + "/ok?c)\n",
+	}
+	start := time.Now()
+	lintLinks(t, rules.GL004{Client: srv.Client(), RateLimit: 20}, files)
+	// Three requests to one host at 20 per second take at least 100ms.
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, took %v", elapsed)
+	}
+}
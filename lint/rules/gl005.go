@@ -0,0 +1,252 @@
+package rules
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/yuin/goldmark/ast"
+)
+
+// GL005 checks the local files that images point to: Markdown images and
+// the src of HTML <img> tags with relative paths. It reports missing files,
+// paths whose case differs from the file on disk (which only work on
+// case-insensitive filesystems), files over a size or pixel limit, and
+// files in formats that are not allowed. It is a lint.ProjectRule, as it
+// needs the path of each document, and reports nothing from Check.
+type GL005 struct {
+	// MaxBytes is the largest file size allowed, in bytes (default 0: no
+	// limit).
+	MaxBytes int64 `json:"max_bytes"`
+	// MaxWidth and MaxHeight are the largest dimensions allowed, in pixels
+	// (default 0: no limit). SVG images are not checked.
+	MaxWidth  int `json:"max_width"`
+	MaxHeight int `json:"max_height"`
+	// Formats lists the formats allowed (default "png", "jpeg", "gif", and
+	// "svg").
+	Formats []string `json:"formats"`
+}
+
+func (r GL005) ID() string          { return "GL005" }
+func (r GL005) Aliases() []string   { return []string{"local-images"} }
+func (r GL005) Tags() []string      { return []string{"images"} }
+func (r GL005) Description() string { return "Local images should exist and be valid" }
+
+// gl005DefaultFormats are the formats allowed when GL005.Formats is empty.
+var gl005DefaultFormats = []string{"png", "jpeg", "gif", "svg"}
+
+// gl005ImgSrcRE matches the src attribute of an HTML <img> tag.
+var gl005ImgSrcRE = regexp.MustCompile(`(?is)<img\b[^>]*?\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))[^>]*>`)
+
+// gl005SVGRE matches the root element of an SVG document.
+var gl005SVGRE = regexp.MustCompile(`(?i)<svg[\s>]`)
+
+func (r GL005) Check(doc *lint.Document) []lint.Violation { return nil }
+
+func (r GL005) CheckProject(docs []*lint.Document) map[string][]lint.Violation {
+	formats := r.Formats
+	if len(formats) == 0 {
+		formats = gl005DefaultFormats
+	}
+	c := &gl005Checker{
+		rule:    r,
+		formats: formats,
+		dirs:    make(map[string][]os.DirEntry),
+		images:  make(map[string]gl005Image),
+	}
+	result := make(map[string][]lint.Violation)
+	for _, doc := range docs {
+		for _, img := range gl005Images(doc, r.ID()) {
+			if msg := c.check(filepath.Dir(doc.Path), img.dest); msg != "" {
+				v := img.violation
+				v.Message = r.Description() + " [" + msg + "]"
+				result[doc.Path] = append(result[doc.Path], v)
+			}
+		}
+	}
+	return result
+}
+
+// gl005Ref is an image source in a document.
+type gl005Ref struct {
+	dest      string
+	violation lint.Violation
+}
+
+// gl005Images returns the sources of the Markdown images and HTML <img> tags
+// in doc, each with a violation of rule positioned on the image.
+func gl005Images(doc *lint.Document, rule string) []gl005Ref {
+	var refs []gl005Ref
+	htmlImages := func(start int, text []byte) {
+		for _, m := range gl005ImgSrcRE.FindAllSubmatchIndex(text, -1) {
+			src := ""
+			for i := 2; i < len(m); i += 2 {
+				if m[i] >= 0 {
+					src = string(text[m[i]:m[i+1]])
+					break
+				}
+			}
+			refs = append(refs, gl005Ref{dest: src, violation: rangeViolation(doc, rule, start+m[0], start+m[1], "")})
+		}
+	}
+	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Image:
+			refs = append(refs, gl005Ref{dest: string(node.Destination), violation: linkViolation(doc, rule, node, "")})
+		case *ast.RawHTML:
+			if node.Segments != nil && node.Segments.Len() > 0 {
+				start := node.Segments.At(0).Start
+				end := node.Segments.At(node.Segments.Len() - 1).Stop
+				htmlImages(start, doc.Source[start:end])
+			}
+		case *ast.HTMLBlock:
+			if node.Lines() != nil && node.Lines().Len() > 0 {
+				start := node.Lines().At(0).Start
+				end := node.Lines().At(node.Lines().Len() - 1).Stop
+				htmlImages(start, doc.Source[start:end])
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return refs
+}
+
+// gl005Image holds what GL005 learned about an image file.
+type gl005Image struct {
+	size          int64
+	format        string // "" if unknown
+	width, height int
+}
+
+// gl005Checker checks image files, reading each directory and file once.
+type gl005Checker struct {
+	rule    GL005
+	formats []string
+	dirs    map[string][]os.DirEntry
+	images  map[string]gl005Image
+}
+
+// check returns a description of the problem with the image dest, relative
+// to dir, or "" if there is none.
+func (c *gl005Checker) check(dir, dest string) string {
+	target, _, ok := gl001SplitDestination(dest)
+	if !ok || strings.HasPrefix(dest, "#") {
+		return ""
+	}
+	path := filepath.Join(dir, filepath.FromSlash(target))
+	actual, found := c.resolve(dir, filepath.FromSlash(target))
+	if !found {
+		return "Image not found: " + target
+	}
+	if actual != path {
+		rel, err := filepath.Rel(dir, actual)
+		if err != nil {
+			rel = actual
+		}
+		return "Image path case does not match the file: " + target + " (file is " + filepath.ToSlash(rel) + ")"
+	}
+	img, ok := c.images[path]
+	if !ok {
+		img = gl005Inspect(path)
+		c.images[path] = img
+	}
+	if c.rule.MaxBytes > 0 && img.size > c.rule.MaxBytes {
+		return fmt.Sprintf("Image too large: %s (%d bytes, max %d)", target, img.size, c.rule.MaxBytes)
+	}
+	allowed := false
+	for _, f := range c.formats {
+		allowed = allowed || img.format != "" && strings.EqualFold(f, img.format)
+	}
+	if !allowed {
+		format := img.format
+		if format == "" {
+			format = "unknown"
+		}
+		return "Unsupported image format: " + target + " (" + format + ")"
+	}
+	if c.rule.MaxWidth > 0 && img.width > c.rule.MaxWidth || c.rule.MaxHeight > 0 && img.height > c.rule.MaxHeight {
+		return fmt.Sprintf("Image too large: %s (%dx%d pixels, max %s)", target, img.width, img.height, gl005Dimensions(c.rule.MaxWidth, c.rule.MaxHeight))
+	}
+	return ""
+}
+
+// resolve finds the file rel, relative to dir, comparing names the way a
+// case-insensitive filesystem does. It returns the path of the file with
+// the case of the names on disk, which differs from filepath.Join(dir, rel)
+// when rel only matches case-insensitively.
+func (c *gl005Checker) resolve(dir, rel string) (string, bool) {
+	path := filepath.Clean(dir)
+	for _, name := range strings.Split(filepath.Clean(rel), string(filepath.Separator)) {
+		if name == "." || name == ".." {
+			path = filepath.Join(path, name)
+			continue
+		}
+		entries, ok := c.dirs[path]
+		if !ok {
+			entries, _ = os.ReadDir(path)
+			c.dirs[path] = entries
+		}
+		match := ""
+		for _, e := range entries {
+			if e.Name() == name {
+				match = name
+				break
+			}
+			if match == "" && strings.EqualFold(e.Name(), name) {
+				match = e.Name()
+			}
+		}
+		if match == "" {
+			return "", false
+		}
+		path = filepath.Join(path, match)
+	}
+	return path, true
+}
+
+// gl005Inspect reads the size, format, and dimensions of the image at path.
+// Only the header of the file is read.
+func gl005Inspect(path string) gl005Image {
+	var img gl005Image
+	f, err := os.Open(path)
+	if err != nil {
+		return img
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil {
+		img.size = info.Size()
+	}
+	if cfg, format, err := image.DecodeConfig(f); err == nil {
+		img.format, img.width, img.height = format, cfg.Width, cfg.Height
+		return img
+	}
+	// SVG files are text; look for the root element near the start.
+	head := make([]byte, 64<<10)
+	n, _ := f.ReadAt(head, 0)
+	if gl005SVGRE.Match(head[:n]) {
+		img.format = "svg"
+	}
+	return img
+}
+
+// gl005Dimensions formats the pixel limits for a message.
+func gl005Dimensions(width, height int) string {
+	w, h := "any", "any"
+	if width > 0 {
+		w = fmt.Sprint(width)
+	}
+	if height > 0 {
+		h = fmt.Sprint(height)
+	}
+	return w + "x" + h
+}
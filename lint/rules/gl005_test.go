@@ -0,0 +1,92 @@
+package rules_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+// writePNG writes a blank PNG image of the given size to path.
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGL005(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "img", "small.png"), 10, 10)
+	writePNG(t, filepath.Join(dir, "img", "wide.png"), 300, 10)
+	writePNG(t, filepath.Join(dir, "img", "Shot.png"), 10, 10)
+	if err := os.WriteFile(filepath.Join(dir, "img", "logo.svg"), []byte(`<?xml version="1.0"?>`+"\n"+`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "img", "photo.bmp"), []byte("BM not really"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := filepath.Join(dir, "docs", "guide.md")
+	content := "# Guide\n\n" +
+		"![ok](../img/small.png) ![svg](../img/logo.svg \"Logo\") ![remote](https://example.com/x.png)\n\n" +
+		"![missing](../img/missing.png)\n\n" +
+		"![case](../img/shot.png)\n\n" +
+		"![wide](../img/wide.png)\n\n" +
+		"![bmp](../img/photo.bmp)\n\n" +
+		"<img src=\"../img/gone.png\" alt=\"gone\">\n\n" +
+		"Inline <img alt='x' src='../img/small.png'> image.\n"
+	files := map[string][]byte{doc: []byte(content)}
+	got := lint.NewLinter(rules.GL005{MaxWidth: 200}).LintProject(files)[doc]
+
+	want := []struct {
+		line int
+		msg  string
+	}{
+		{5, "Image not found: ../img/missing.png"},
+		{7, "Image path case does not match the file: ../img/shot.png (file is ../img/Shot.png)"},
+		{9, "Image too large: ../img/wide.png (300x10 pixels, max 200xany)"},
+		{11, "Unsupported image format: ../img/photo.bmp (unknown)"},
+		{13, "Image not found: ../img/gone.png"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d violations, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Line != w.line || !strings.Contains(got[i].Message, "["+w.msg+"]") {
+			t.Errorf("violation %d: got line %d %q, want line %d %q", i, got[i].Line, got[i].Message, w.line, w.msg)
+		}
+	}
+}
+
+func TestGL005_SizeAndFormats(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "a.png"), 64, 64)
+	doc := filepath.Join(dir, "a.md")
+	files := map[string][]byte{doc: []byte("# A\n\n![a](a.png)\n")}
+
+	got := lint.NewLinter(rules.GL005{MaxBytes: 10}).LintProject(files)[doc]
+	if len(got) != 1 || !strings.Contains(got[0].Message, "Image too large: a.png (") || !strings.Contains(got[0].Message, "bytes, max 10)") {
+		t.Errorf("expected a size violation, got %+v", got)
+	}
+	got = lint.NewLinter(rules.GL005{Formats: []string{"svg"}}).LintProject(files)[doc]
+	if len(got) != 1 || !strings.Contains(got[0].Message, "Unsupported image format: a.png (png)") {
+		t.Errorf("expected a format violation, got %+v", got)
+	}
+	if got := lint.NewLinter(rules.GL005{}).LintProject(files)[doc]; len(got) != 0 {
+		t.Errorf("expected no violations with the defaults, got %+v", got)
+	}
+}
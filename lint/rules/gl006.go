@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// GL006 reports markdownlint inline disable comments that no longer do
+// anything, so that old suppressions do not pile up and hide new problems:
+// comments that suppressed no violation, comments that name rules that do
+// not exist, and markdownlint-disable comments with no matching
+// markdownlint-enable. It can also require each comment to say why, after
+// "--", as in <!-- markdownlint-disable MD033 -- embedded video -->.
+type GL006 struct {
+	// RequireReason reports disable comments that give no reason.
+	RequireReason bool `json:"require_reason"`
+	// RequireSpecific reports disable comments that name no rules, and so
+	// disable all of them.
+	RequireSpecific bool `json:"require_specific"`
+	// AllowUnused turns off the report of comments that suppress nothing.
+	AllowUnused bool `json:"allow_unused"`
+}
+
+func (r GL006) ID() string          { return "GL006" }
+func (r GL006) Aliases() []string   { return []string{"inline-suppressions"} }
+func (r GL006) Tags() []string      { return []string{"suppressions"} }
+func (r GL006) Description() string { return "Inline disable comments should be necessary" }
+
+func (r GL006) Check(doc *lint.Document) []lint.Violation { return nil }
+
+func (r GL006) CheckSuppressions(doc *lint.Document, suppressions []lint.Suppression) []lint.Violation {
+	var violations []lint.Violation
+	for _, s := range suppressions {
+		comment := "markdownlint-" + s.Command
+		report := func(msg string) {
+			violations = append(violations, lint.Violation{
+				Rule:    r.ID(),
+				Line:    s.Line,
+				Column:  s.Column,
+				Message: r.Description() + " [" + msg + "]",
+			})
+		}
+		var unknown, unused []string
+		for _, rule := range s.Rules {
+			if !rule.Known {
+				if _, ok := Lookup(rule.Name); !ok {
+					unknown = append(unknown, rule.Name)
+					continue
+				}
+			}
+			if rule.Unused {
+				unused = append(unused, rule.Name)
+			}
+		}
+		if len(unknown) > 0 {
+			report("Unknown rule in " + comment + ": " + strings.Join(unknown, ", "))
+		}
+		if !r.AllowUnused {
+			if s.Unused {
+				report(comment + " suppresses no violations")
+			} else if len(unused) > 0 {
+				report(comment + " suppresses no violations of " + strings.Join(unused, ", "))
+			}
+		}
+		if s.Unclosed {
+			report(comment + " has no matching markdownlint-enable")
+		}
+		if r.RequireSpecific && len(s.Rules) == 0 {
+			report(comment + " should name the rules it disables")
+		}
+		if r.RequireReason && strings.TrimSpace(s.Reason) == "" {
+			report(comment + " should give a reason after \"--\"")
+		}
+	}
+	return violations
+}
@@ -1,12 +1,16 @@
 package rules
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 // IntOrArray is a JSON-compatible type that can be either a single integer or
@@ -57,6 +61,149 @@ func countLine(source []byte, pos int) int {
 	return line
 }
 
+// rangeViolation returns a violation of rule that spans the byte range
+// [start, end) of doc.Source. A trailing line break is not part of the range.
+func rangeViolation(doc *lint.Document, rule string, start, end int, message string) lint.Violation {
+	for end > start && (doc.Source[end-1] == '\n' || doc.Source[end-1] == '\r') {
+		end--
+	}
+	line, col := sourcePosition(doc.Source, start)
+	endLine, endCol := sourcePosition(doc.Source, end)
+	return lint.Violation{
+		Rule:      rule,
+		Line:      line,
+		Column:    col,
+		EndLine:   endLine,
+		EndColumn: endCol,
+		Message:   message,
+	}
+}
+
+// sourcePosition converts a byte offset into source to a 1-based line and
+// column.
+func sourcePosition(source []byte, pos int) (line, col int) {
+	pos = min(max(pos, 0), len(source))
+	lineStart := bytes.LastIndexByte(source[:pos], '\n') + 1
+	return countLine(source, pos), pos - lineStart + 1
+}
+
+// nodeRange returns the byte range of the source spanned by the segments of
+// n and its descendants. ok is false if n has no segments, e.g. for a list
+// item without content.
+func nodeRange(n ast.Node) (start, end int, ok bool) {
+	start, end = -1, -1
+	add := func(seg text.Segment) {
+		if start < 0 || seg.Start < start {
+			start = seg.Start
+		}
+		if seg.Stop > end {
+			end = seg.Stop
+		}
+	}
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			add(c.Segment)
+		case *ast.RawHTML:
+			for i := 0; i < c.Segments.Len(); i++ {
+				add(c.Segments.At(i))
+			}
+		default:
+			if c.Type() == ast.TypeBlock && c.Lines() != nil {
+				for i := 0; i < c.Lines().Len(); i++ {
+					add(c.Lines().At(i))
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return start, end, start >= 0
+}
+
+// linkRange returns the byte range of a Link or Image node including its
+// brackets and, for inline links, the parenthesised destination. ok is false
+// if the link text has no segments to anchor the range, e.g. for "[](url)".
+func linkRange(doc *lint.Document, n ast.Node) (start, end int, ok bool) {
+	start, end, ok = nodeRange(n)
+	if !ok {
+		return 0, 0, false
+	}
+	src := doc.Source
+	if start > 0 && src[start-1] == '[' {
+		start--
+		if _, isImage := n.(*ast.Image); isImage && start > 0 && src[start-1] == '!' {
+			start--
+		}
+	}
+	if end >= len(src) || src[end] != ']' {
+		return start, end, true
+	}
+	end++
+	if end < len(src) && (src[end] == '(' || src[end] == '[') {
+		closer := byte(')')
+		if src[end] == '[' {
+			closer = ']'
+		}
+		depth := 0
+		for i := end; i < len(src) && src[i] != '\n'; i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case src[end]:
+				depth++
+			case closer:
+				depth--
+				if depth == 0 {
+					return start, i + 1, true
+				}
+			}
+		}
+	}
+	return start, end, true
+}
+
+// linkViolation returns a violation of rule spanning the Link or Image node n.
+// When the range of n cannot be determined the violation covers the line on
+// which n appears.
+func linkViolation(doc *lint.Document, rule string, n ast.Node, message string) lint.Violation {
+	if start, end, ok := linkRange(doc, n); ok {
+		return rangeViolation(doc, rule, start, end, message)
+	}
+	return lint.Violation{
+		Rule:    rule,
+		Line:    inlineNodeLine(n, doc.Source),
+		Column:  1,
+		Message: message,
+	}
+}
+
+// submatches returns the text of the submatches located by loc, as returned
+// by the Index variants of the regexp methods. Unmatched groups are empty.
+func submatches(s string, loc []int) []string {
+	m := make([]string, len(loc)/2)
+	for i := range m {
+		if loc[2*i] >= 0 {
+			m[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return m
+}
+
+// lineStarts returns the byte offset at which each line of source begins,
+// indexed the same way as lint.Document.Lines.
+func lineStarts(source []byte) []int {
+	starts := []int{0}
+	for i, b := range source {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
 // headingText returns the text content of a heading node by recursively
 // extracting text from all inline descendants. This includes text inside
 // code spans, emphasis, strong, links, etc., matching GitHub's anchor
@@ -239,19 +386,67 @@ func lastTextStopInInline(n ast.Node) int {
 	return best
 }
 
+// documentAnchors returns the set of fragment targets defined in doc: the
+// anchors that the named slugger generates for its headings, explicit
+// {#id} heading attributes, and the values of HTML id and name attributes.
+// With ignoreCase, the lowercased heading anchors are included as well.
+func documentAnchors(doc *lint.Document, slugger string, ignoreCase bool) map[string]bool {
+	anchors := make(map[string]bool)
+	s := newSluggerOrDefault(slugger)
+	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		text := headingText(h, doc.Source)
+		anchor, ok := explicitHeadingID(text)
+		// With the attributes extension, goldmark removes the attribute
+		// block from the text and records the ID on the node instead.
+		if id, found := h.AttributeString("id"); found {
+			if b, isBytes := id.([]byte); isBytes {
+				anchor, ok = string(b), true
+			}
+		}
+		if !ok {
+			anchor = s.Slug(text)
+		}
+		anchors[anchor] = true
+		if ignoreCase {
+			anchors[strings.ToLower(anchor)] = true
+		}
+		return ast.WalkContinue, nil
+	})
 
-// headingAnchor converts heading text to a GitHub-style anchor.
-func headingAnchor(text string) string {
-	result := strings.ToLower(text)
-	var b strings.Builder
-	for _, r := range result {
-		if r == ' ' || r == '-' {
-			b.WriteRune('-')
-		} else if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
-			b.WriteRune(r)
+	// Collect HTML anchors.
+	for _, line := range doc.Lines {
+		for _, m := range md051HTMLAnchorRE.FindAllStringSubmatch(line, -1) {
+			anchors[m[1]] = true
 		}
 	}
-	return b.String()
+	return anchors
+}
+
+// anchorExists reports whether the link fragment names one of anchors, as
+// written or percent-decoded. With ignoreCase, the fragment is lowercased
+// first; anchors must then come from documentAnchors with ignoreCase set.
+func anchorExists(anchors map[string]bool, fragment string, ignoreCase bool) bool {
+	if ignoreCase {
+		fragment = strings.ToLower(fragment)
+	}
+	if anchors[fragment] {
+		return true
+	}
+	decoded, err := url.PathUnescape(fragment)
+	if err != nil || decoded == fragment {
+		return false
+	}
+	if ignoreCase {
+		decoded = strings.ToLower(decoded)
+	}
+	return anchors[decoded]
 }
 
 // countTableCells counts the cells in a table row.
@@ -263,17 +458,19 @@ func countTableCells(line string) int {
 
 // frontMatterHasTitle reports whether the document's front matter contains a
 // title field. pattern is a field name or regex; if empty, the feature is
-// disabled and false is always returned.
+// disabled and false is always returned. Nested fields are named by their
+// dotted key path, such as "params.title".
 func frontMatterHasTitle(doc *lint.Document, pattern string) bool {
 	if pattern == "" {
 		return false
 	}
-	if len(doc.FrontMatterFields) == 0 {
+	values := frontMatterValues(doc.FrontMatter)
+	if len(values) == 0 {
 		return false
 	}
 	// First try exact key match (case-insensitive).
-	for k, v := range doc.FrontMatterFields {
-		if strings.EqualFold(k, pattern) && v != "" {
+	for _, v := range values {
+		if strings.EqualFold(v.path, pattern) && v.value != "" {
 			return true
 		}
 	}
@@ -282,14 +479,48 @@ func frontMatterHasTitle(doc *lint.Document, pattern string) bool {
 	if err != nil {
 		return false
 	}
-	for k, v := range doc.FrontMatterFields {
-		if re.MatchString(k + ": " + v) {
+	for _, v := range values {
+		if re.MatchString(v.path + ": " + v.value) {
 			return true
 		}
 	}
 	return false
 }
 
+// frontMatterValue is a scalar value in a front matter tree.
+type frontMatterValue struct {
+	path  string // dotted key path, such as "params.title"
+	value string
+}
+
+// frontMatterValues returns the scalar values in fm by their dotted key path.
+// The elements of a list share the path of the list.
+func frontMatterValues(fm map[string]interface{}) []frontMatterValue {
+	var values []frontMatterValue
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, sub := range v {
+				if path != "" {
+					k = path + "." + k
+				}
+				walk(k, sub)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(path, item)
+			}
+		case nil:
+			values = append(values, frontMatterValue{path: path})
+		default:
+			values = append(values, frontMatterValue{path: path, value: fmt.Sprint(v)})
+		}
+	}
+	walk("", fm)
+	return values
+}
+
 // fencedCodeBlockLanguages returns a map from line index (0-based) to the
 // language of the fenced code block that line belongs to (empty string if
 // the block has no language specified). Lines outside code blocks are absent.
@@ -392,6 +623,7 @@ func indentedCodeBlockMask(doc *lint.Document) []bool {
 	})
 	return mask
 }
+
 // It uses the goldmark AST to accurately detect HTML blocks.
 func htmlBlockLineMask(doc *lint.Document) []bool {
 	mask := make([]bool, len(doc.Lines))
@@ -12,11 +12,12 @@ import (
 type MD003 struct {
 	// Style is the required heading style: "consistent" (default), "atx", "setext",
 	// "atx_closed", "setext_with_atx", or "setext_with_atx_closed".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,atx,atx_closed,setext,setext_with_atx,setext_with_atx_closed"`
 }
 
 func (r MD003) ID() string          { return "MD003" }
 func (r MD003) Aliases() []string   { return []string{"heading-style"} }
+func (r MD003) Tags() []string      { return []string{"headings"} }
 func (r MD003) Description() string { return "Heading style" }
 
 // headingStyleOf returns "atx", "atx_closed", or "setext" for the given heading node by
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 	"github.com/yuin/goldmark/ast"
@@ -11,11 +12,12 @@ import (
 type MD004 struct {
 	// Style is the required marker style: "consistent" (default), "asterisk",
 	// "plus", "dash", or "sublist" (different symbol per nesting level).
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,asterisk,plus,dash,sublist"`
 }
 
 func (r MD004) ID() string          { return "MD004" }
 func (r MD004) Aliases() []string   { return []string{"ul-style"} }
+func (r MD004) Tags() []string      { return []string{"bullet", "ul"} }
 func (r MD004) Description() string { return "Unordered list style" }
 
 func (r MD004) Check(doc *lint.Document) []lint.Violation {
@@ -61,11 +63,14 @@ func (r MD004) Check(doc *lint.Document) []lint.Violation {
 								lineNum = countLine(doc.Source, seg.Start)
 							}
 						}
+						col := markerColumn(doc, lineNum, list.Marker)
 						violations = append(violations, lint.Violation{
-							Rule:    r.ID(),
-							Line:    lineNum,
-							Column:  1,
-							Message: fmt.Sprintf("Unordered list style [Expected: %c; Actual: %c]", expectedMarker, list.Marker),
+							Rule:      r.ID(),
+							Line:      lineNum,
+							Column:    col,
+							EndLine:   lineNum,
+							EndColumn: col + 1,
+							Message:   fmt.Sprintf("Unordered list style [Expected: %c; Actual: %c]", expectedMarker, list.Marker),
 						})
 					}
 				}
@@ -113,11 +118,14 @@ func (r MD004) Check(doc *lint.Document) []lint.Violation {
 						lineNum = countLine(doc.Source, seg.Start)
 					}
 				}
+				col := markerColumn(doc, lineNum, marker)
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    lineNum,
-					Column:  1,
-					Message: fmt.Sprintf("Unordered list style [Expected: %c; Actual: %c]", expected, marker),
+					Rule:      r.ID(),
+					Line:      lineNum,
+					Column:    col,
+					EndLine:   lineNum,
+					EndColumn: col + 1,
+					Message:   fmt.Sprintf("Unordered list style [Expected: %c; Actual: %c]", expected, marker),
 				})
 			}
 		}
@@ -126,3 +134,15 @@ func (r MD004) Check(doc *lint.Document) []lint.Violation {
 
 	return violations
 }
+
+// markerColumn returns the 1-based column of the list marker on line lineNum,
+// or 1 if it cannot be found.
+func markerColumn(doc *lint.Document, lineNum int, marker byte) int {
+	if lineNum < 1 || lineNum > len(doc.Lines) {
+		return 1
+	}
+	if i := strings.IndexByte(doc.Lines[lineNum-1], marker); i >= 0 {
+		return i + 1
+	}
+	return 1
+}
@@ -16,6 +16,7 @@ func (r MD005) ID() string { return "MD005" }
 func (r MD005) Aliases() []string {
 	return []string{"list-indent"}
 }
+func (r MD005) Tags() []string { return []string{"bullet", "ul", "indentation"} }
 func (r MD005) Description() string {
 	return "Inconsistent indentation for list items at the same level"
 }
@@ -52,11 +53,14 @@ func (r MD005) Check(doc *lint.Document) []lint.Violation {
 			if expectedIndent < 0 {
 				expectedIndent = spaces
 			} else if spaces != expectedIndent {
+				// The range covers the list marker.
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    lineIdx + 1,
-					Column:  spaces + 1,
-					Message: fmt.Sprintf("Inconsistent indentation for list items at the same level [Expected: %d; Actual: %d]", expectedIndent, spaces),
+					Rule:      r.ID(),
+					Line:      lineIdx + 1,
+					Column:    spaces + 1,
+					EndLine:   lineIdx + 1,
+					EndColumn: spaces + 2,
+					Message:   fmt.Sprintf("Inconsistent indentation for list items at the same level [Expected: %d; Actual: %d]", expectedIndent, spaces),
 				})
 			}
 		}
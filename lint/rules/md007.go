@@ -22,6 +22,7 @@ type MD007 struct {
 
 func (r MD007) ID() string          { return "MD007" }
 func (r MD007) Aliases() []string   { return []string{"ul-indent"} }
+func (r MD007) Tags() []string      { return []string{"bullet", "ul", "indentation"} }
 func (r MD007) Description() string { return "Unordered list indentation" }
 
 // unorderedListMarkers holds the valid unordered list marker bytes.
@@ -123,11 +124,14 @@ func (r MD007) Check(doc *lint.Document) []lint.Violation {
 		}
 
 		if spaces != expectedIndent {
+			// The range covers the list marker.
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    lineNum,
-				Column:  spaces + 1,
-				Message: fmt.Sprintf("Unordered list indentation [Expected: %d; Actual: %d]", expectedIndent, spaces),
+				Rule:      r.ID(),
+				Line:      lineNum,
+				Column:    len(rawLine) - len(trimmed) + 1,
+				EndLine:   lineNum,
+				EndColumn: len(rawLine) - len(trimmed) + 2,
+				Message:   fmt.Sprintf("Unordered list indentation [Expected: %d; Actual: %d]", expectedIndent, spaces),
 			})
 		}
 		return ast.WalkContinue, nil
@@ -23,31 +23,12 @@ type MD009 struct {
 
 func (r MD009) ID() string          { return "MD009" }
 func (r MD009) Aliases() []string   { return []string{"no-trailing-spaces"} }
+func (r MD009) Tags() []string      { return []string{"whitespace"} }
 func (r MD009) Description() string { return "Trailing spaces" }
 
-func (r MD009) Fix(source []byte) []byte {
-	brSpaces := r.BrSpaces
-	if brSpaces == 0 {
-		brSpaces = 2
-	}
-	lines := strings.Split(string(source), "\n")
-	codeMask := fencedCodeBlockMask(lines)
-	checkCodeBlocks := r.CodeBlocks != nil && *r.CodeBlocks
-	for i, line := range lines {
-		if !checkCodeBlocks && codeMask[i] {
-			continue
-		}
-		trimmed := strings.TrimRight(line, " \t")
-		trailingLen := len(line) - len(trimmed)
-		if trailingLen > 0 {
-			if !r.Strict && trailingLen == brSpaces && strings.HasSuffix(line, strings.Repeat(" ", brSpaces)) {
-				continue
-			}
-			lines[i] = trimmed
-		}
-	}
-	return []byte(strings.Join(lines, "\n"))
-}
+func (r MD009) FixesWithEdits() bool { return true }
+
+func (r MD009) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD009) Check(doc *lint.Document) []lint.Violation {
 	brSpaces := r.BrSpaces
@@ -96,6 +77,7 @@ func (r MD009) Check(doc *lint.Document) []lint.Violation {
 		}
 	}
 
+	starts := lineStarts(doc.Source)
 	var violations []lint.Violation
 	for i, line := range doc.Lines {
 		if !checkCodeBlocks && codeMask[i] {
@@ -115,6 +97,7 @@ func (r MD009) Check(doc *lint.Document) []lint.Violation {
 				Line:    i + 1,
 				Column:  len(trimmed) + 1,
 				Message: fmt.Sprintf("Trailing spaces [Expected: 0 or %d; Actual: %d]", brSpaces, trailingLen),
+				Fix:     []lint.Edit{{Start: starts[i] + len(trimmed), End: starts[i] + len(line)}},
 			})
 		}
 	}
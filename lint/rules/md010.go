@@ -18,15 +18,12 @@ type MD010 struct {
 
 func (r MD010) ID() string          { return "MD010" }
 func (r MD010) Aliases() []string   { return []string{"no-hard-tabs"} }
+func (r MD010) Tags() []string      { return []string{"whitespace", "hard_tab"} }
 func (r MD010) Description() string { return "Hard tabs" }
 
-func (r MD010) Fix(source []byte) []byte {
-	spaces := r.SpacesPerTab
-	if spaces <= 0 {
-		spaces = 4
-	}
-	return []byte(strings.ReplaceAll(string(source), "\t", strings.Repeat(" ", spaces)))
-}
+func (r MD010) FixesWithEdits() bool { return true }
+
+func (r MD010) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD010) isIgnoredLang(lang string) bool {
 	for _, l := range r.IgnoreCodeLanguages {
@@ -45,6 +42,11 @@ func (r MD010) Check(doc *lint.Document) []lint.Violation {
 		langMap = fencedCodeBlockLanguages(doc.Lines)
 	}
 
+	spaces := r.SpacesPerTab
+	if spaces <= 0 {
+		spaces = 4
+	}
+	starts := lineStarts(doc.Source)
 	var violations []lint.Violation
 	for i, line := range doc.Lines {
 		if codeMask[i] {
@@ -60,13 +62,25 @@ func (r MD010) Check(doc *lint.Document) []lint.Violation {
 		// Report the first tab in each consecutive run of tabs.
 		// Markdownlint reports the first tab of a run but not subsequent
 		// consecutive tabs (e.g. "\t\t\tcode" → one violation at column 1).
+		// The fix replaces the whole run.
 		for j := 0; j < len(line); j++ {
 			if line[j] == '\t' && (j == 0 || line[j-1] != '\t') {
+				end := j
+				for end < len(line) && line[end] == '\t' {
+					end++
+				}
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  j + 1,
-					Message: "Hard tabs",
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    j + 1,
+					EndLine:   i + 1,
+					EndColumn: end + 1,
+					Message:   "Hard tabs",
+					Fix: []lint.Edit{{
+						Start:   starts[i] + j,
+						End:     starts[i] + end,
+						NewText: strings.Repeat(" ", spaces*(end-j)),
+					}},
 				})
 			}
 		}
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"regexp"
-	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 )
@@ -12,37 +11,38 @@ type MD011 struct{}
 
 func (r MD011) ID() string          { return "MD011" }
 func (r MD011) Aliases() []string   { return []string{"no-reversed-links"} }
+func (r MD011) Tags() []string      { return []string{"links"} }
 func (r MD011) Description() string { return "Reversed link syntax" }
 
 // reversedLinkRE matches the pattern (text)[url] which is a reversed link.
 var reversedLinkRE = regexp.MustCompile(`\(([^)\n]+)\)\[([^\]\n]+)\]`)
 
-func (r MD011) Fix(source []byte) []byte {
-	lines := strings.Split(string(source), "\n")
-	mask := fencedCodeBlockMask(lines)
-	for i, line := range lines {
-		if !mask[i] {
-			lines[i] = reversedLinkRE.ReplaceAllString(line, "[$1]($2)")
-		}
-	}
-	return []byte(strings.Join(lines, "\n"))
-}
+func (r MD011) FixesWithEdits() bool { return true }
+
+func (r MD011) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD011) Check(doc *lint.Document) []lint.Violation {
 	var violations []lint.Violation
 	mask := fencedCodeBlockMask(doc.Lines)
+	starts := lineStarts(doc.Source)
 	for i, line := range doc.Lines {
 		if mask[i] {
 			continue
 		}
-		// Count each occurrence, not just whether the line has a match.
-		count := len(reversedLinkRE.FindAllString(line, -1))
-		for range count {
+		// Report each occurrence, not just whether the line has a match.
+		for _, m := range reversedLinkRE.FindAllStringSubmatchIndex(line, -1) {
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    i + 1,
-				Column:  1,
-				Message: "Reversed link syntax",
+				Rule:      r.ID(),
+				Line:      i + 1,
+				Column:    m[0] + 1,
+				EndLine:   i + 1,
+				EndColumn: m[1] + 1,
+				Message:   "Reversed link syntax",
+				Fix: []lint.Edit{{
+					Start:   starts[i] + m[0],
+					End:     starts[i] + m[1],
+					NewText: "[" + line[m[2]:m[3]] + "](" + line[m[4]:m[5]] + ")",
+				}},
 			})
 		}
 	}
@@ -15,29 +15,12 @@ type MD012 struct {
 
 func (r MD012) ID() string          { return "MD012" }
 func (r MD012) Aliases() []string   { return []string{"no-multiple-blanks"} }
+func (r MD012) Tags() []string      { return []string{"whitespace", "blank_lines"} }
 func (r MD012) Description() string { return "Multiple consecutive blank lines" }
 
-func (r MD012) Fix(source []byte) []byte {
-	maximum := r.Maximum
-	if maximum == 0 {
-		maximum = 1
-	}
-	lines := strings.Split(string(source), "\n")
-	var result []string
-	consecutive := 0
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			consecutive++
-			if consecutive <= maximum {
-				result = append(result, line)
-			}
-		} else {
-			consecutive = 0
-			result = append(result, line)
-		}
-	}
-	return []byte(strings.Join(result, "\n"))
-}
+func (r MD012) FixesWithEdits() bool { return true }
+
+func (r MD012) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD012) Check(doc *lint.Document) []lint.Violation {
 	maximum := r.Maximum
@@ -51,6 +34,7 @@ func (r MD012) Check(doc *lint.Document) []lint.Violation {
 	// Build a mask for indented code block lines using the goldmark AST.
 	// Blank lines inside indented code blocks should not trigger MD012.
 	indentMask := indentedCodeBlockMask(doc)
+	starts := lineStarts(doc.Source)
 
 	for i, line := range doc.Lines {
 		// Skip front-matter lines (they were stripped to blank lines by stripFrontMatterAt).
@@ -66,11 +50,26 @@ func (r MD012) Check(doc *lint.Document) []lint.Violation {
 		if strings.TrimSpace(line) == "" {
 			consecutive++
 			if consecutive > maximum {
+				// Remove the whole line with its line break, "\r\n" or
+				// "\n". The last line has no line break of its own, so it is
+				// removed with the line break before the first line of the
+				// run that is removed; the lines in between remove the rest.
+				var fix []lint.Edit
+				if i+1 < len(starts) {
+					fix = []lint.Edit{{Start: starts[i], End: starts[i+1]}}
+				} else {
+					first := starts[i-(consecutive-maximum)+1]
+					fix = []lint.Edit{{Start: lineBreakStart(doc.Source, first), End: first}}
+					if starts[i] < len(doc.Source) {
+						fix = append(fix, lint.Edit{Start: starts[i], End: len(doc.Source)})
+					}
+				}
 				violations = append(violations, lint.Violation{
 					Rule:    r.ID(),
 					Line:    i + 1,
 					Column:  1,
 					Message: fmt.Sprintf("Multiple consecutive blank lines [Expected: %d; Actual: %d]", maximum, consecutive),
+					Fix:     fix,
 				})
 			}
 		} else {
@@ -79,3 +78,12 @@ func (r MD012) Check(doc *lint.Document) []lint.Violation {
 	}
 	return violations
 }
+
+// lineBreakStart returns the offset of the line break, "\r\n" or "\n", that
+// ends just before the line starting at offset start, which must not be 0.
+func lineBreakStart(source []byte, start int) int {
+	if start >= 2 && source[start-2] == '\r' {
+		return start - 2
+	}
+	return start - 1
+}
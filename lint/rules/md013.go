@@ -35,6 +35,7 @@ type MD013 struct {
 
 func (r MD013) ID() string          { return "MD013" }
 func (r MD013) Aliases() []string   { return []string{"line-length"} }
+func (r MD013) Tags() []string      { return []string{"line_length"} }
 func (r MD013) Description() string { return "Line length" }
 
 func (r MD013) Check(doc *lint.Document) []lint.Violation {
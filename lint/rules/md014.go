@@ -13,6 +13,7 @@ func (r MD014) ID() string { return "MD014" }
 func (r MD014) Aliases() []string {
 	return []string{"commands-show-output"}
 }
+func (r MD014) Tags() []string { return []string{"code"} }
 func (r MD014) Description() string {
 	return "Dollar signs used before commands without showing output"
 }
@@ -84,11 +85,15 @@ func (r MD014) Check(doc *lint.Document) []lint.Violation {
 		}
 		for k := start; k < end; k++ {
 			if strings.TrimSpace(doc.Lines[k]) != "" {
+				// The range covers the dollar sign.
+				indent := len(doc.Lines[k]) - len(strings.TrimLeft(doc.Lines[k], " \t"))
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    k + 1,
-					Column:  1,
-					Message: "Dollar signs used before commands without showing output",
+					Rule:      r.ID(),
+					Line:      k + 1,
+					Column:    indent + 1,
+					EndLine:   k + 1,
+					EndColumn: indent + 2,
+					Message:   "Dollar signs used before commands without showing output",
 				})
 			}
 		}
@@ -2,7 +2,6 @@ package rules
 
 import (
 	"regexp"
-	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 )
@@ -12,6 +11,7 @@ type MD018 struct{}
 
 func (r MD018) ID() string          { return "MD018" }
 func (r MD018) Aliases() []string   { return []string{"no-missing-space-atx"} }
+func (r MD018) Tags() []string      { return []string{"headings", "atx", "spaces"} }
 func (r MD018) Description() string { return "No space after hash on ATX style heading" }
 
 // md018RE matches an ATX-like heading line where the hashes are not followed by a space.
@@ -20,31 +20,26 @@ func (r MD018) Description() string { return "No space after hash on ATX style h
 // Group 1: hashes, Group 2: rest starting with non-space, non-hash char.
 var md018RE = regexp.MustCompile(`^(#{1,6})([^ \t#\n].*)$`)
 
-func (r MD018) Fix(source []byte) []byte {
-	lines := strings.Split(string(source), "\n")
-	mask := fencedCodeBlockMask(lines)
-	for i, line := range lines {
-		if !mask[i] {
-			lines[i] = md018RE.ReplaceAllString(line, "$1 $2")
-		}
-	}
-	return []byte(strings.Join(lines, "\n"))
-}
+func (r MD018) FixesWithEdits() bool { return true }
+
+func (r MD018) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD018) Check(doc *lint.Document) []lint.Violation {
 	var violations []lint.Violation
 	fenceMask := fencedCodeBlockMask(doc.Lines)
 	htmlMask := htmlBlockLineMask(doc)
+	starts := lineStarts(doc.Source)
 	for i, line := range doc.Lines {
 		if fenceMask[i] || htmlMask[i] {
 			continue
 		}
-		if md018RE.MatchString(line) {
+		if m := md018RE.FindStringSubmatchIndex(line); m != nil {
 			violations = append(violations, lint.Violation{
 				Rule:    r.ID(),
 				Line:    i + 1,
 				Column:  1,
 				Message: "No space after hash on ATX style heading",
+				Fix:     []lint.Edit{{Start: starts[i] + m[3], End: starts[i] + m[3], NewText: " "}},
 			})
 		}
 	}
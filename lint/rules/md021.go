@@ -13,56 +13,47 @@ func (r MD021) ID() string { return "MD021" }
 func (r MD021) Aliases() []string {
 	return []string{"no-multiple-space-closed-atx"}
 }
+func (r MD021) Tags() []string { return []string{"headings", "atx_closed", "spaces"} }
 func (r MD021) Description() string {
 	return "Multiple spaces inside hashes on closed ATX style heading"
 }
 
-func (r MD021) Fix(source []byte) []byte {
-	lines := strings.Split(string(source), "\n")
-	mask := fencedCodeBlockMask(lines)
-	for i, line := range lines {
-		if mask[i] {
-			continue
-		}
-		m := closedATXRE.FindStringSubmatch(line)
-		if m == nil {
-			continue
-		}
-		indent, opening, middle, closing := m[1], m[2], m[3], m[4]
-		fixed := false
-		if strings.HasPrefix(middle, "  ") {
-			middle = " " + strings.TrimLeft(middle, " ")
-			fixed = true
-		}
-		if strings.HasSuffix(middle, "  ") {
-			middle = strings.TrimRight(middle, " ") + " "
-			fixed = true
-		}
-		if fixed {
-			lines[i] = indent + opening + middle + closing
-		}
-	}
-	return []byte(strings.Join(lines, "\n"))
-}
+func (r MD021) FixesWithEdits() bool { return true }
+
+func (r MD021) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD021) Check(doc *lint.Document) []lint.Violation {
 	var violations []lint.Violation
 	mask := fencedCodeBlockMask(doc.Lines)
+	starts := lineStarts(doc.Source)
 	for i, line := range doc.Lines {
 		if mask[i] {
 			continue
 		}
-		m := closedATXRE.FindStringSubmatch(line)
+		m := closedATXRE.FindStringSubmatchIndex(line)
 		if m == nil {
 			continue
 		}
-		middle := m[3]
-		if strings.HasPrefix(middle, "  ") || strings.HasSuffix(middle, "  ") {
+		// Collapse the runs of spaces on either side of the heading text to
+		// a single space.
+		middleStart, middleEnd := m[6], m[7]
+		middle := line[middleStart:middleEnd]
+		var edits []lint.Edit
+		if strings.HasPrefix(middle, "  ") {
+			lead := len(middle) - len(strings.TrimLeft(middle, " "))
+			edits = append(edits, lint.Edit{Start: starts[i] + middleStart, End: starts[i] + middleStart + lead, NewText: " "})
+		}
+		if strings.HasSuffix(middle, "  ") && strings.TrimSpace(middle) != "" {
+			trail := len(middle) - len(strings.TrimRight(middle, " "))
+			edits = append(edits, lint.Edit{Start: starts[i] + middleEnd - trail, End: starts[i] + middleEnd, NewText: " "})
+		}
+		if len(edits) > 0 {
 			violations = append(violations, lint.Violation{
 				Rule:    r.ID(),
 				Line:    i + 1,
 				Column:  1,
 				Message: "Multiple spaces inside hashes on closed ATX style heading",
+				Fix:     edits,
 			})
 		}
 	}
@@ -13,6 +13,7 @@ type MD023 struct{}
 
 func (r MD023) ID() string          { return "MD023" }
 func (r MD023) Aliases() []string   { return []string{"heading-start-left"} }
+func (r MD023) Tags() []string      { return []string{"headings", "spaces"} }
 func (r MD023) Description() string { return "Headings must start at the beginning of the line" }
 
 // md023atxRE matches an ATX heading with 1–3 leading spaces.
@@ -36,10 +37,12 @@ func (r MD023) Check(doc *lint.Document) []lint.Violation {
 		line := doc.Lines[lineNum-1]
 		if strings.HasPrefix(line, " ") {
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    lineNum,
-				Column:  1,
-				Message: "Headings must start at the beginning of the line",
+				Rule:      r.ID(),
+				Line:      lineNum,
+				Column:    1,
+				EndLine:   lineNum,
+				EndColumn: len(line) - len(strings.TrimLeft(line, " ")) + 1,
+				Message:   "Headings must start at the beginning of the line",
 			})
 		}
 		return ast.WalkContinue, nil
@@ -16,6 +16,7 @@ type MD024 struct {
 
 func (r MD024) ID() string          { return "MD024" }
 func (r MD024) Aliases() []string   { return []string{"no-duplicate-heading"} }
+func (r MD024) Tags() []string      { return []string{"headings"} }
 func (r MD024) Description() string { return "Multiple headings with the same content" }
 
 // headingRawContent returns the raw source content of a heading (after stripping
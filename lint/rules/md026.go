@@ -3,6 +3,7 @@ package rules
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mrueg/goldmark-lint/lint"
 	"github.com/yuin/goldmark/ast"
@@ -16,6 +17,7 @@ type MD026 struct {
 
 func (r MD026) ID() string          { return "MD026" }
 func (r MD026) Aliases() []string   { return []string{"no-trailing-punctuation"} }
+func (r MD026) Tags() []string      { return []string{"headings"} }
 func (r MD026) Description() string { return "Trailing punctuation in heading" }
 
 const defaultMD026Punctuation = ".,;:!。，；：！"
@@ -72,10 +74,12 @@ func (r MD026) Check(doc *lint.Document) []lint.Violation {
 		}
 
 		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    line,
-			Column:  1,
-			Message: fmt.Sprintf("Trailing punctuation in heading [Punctuation: '%c']", lastRune),
+			Rule:      r.ID(),
+			Line:      line,
+			Column:    len(rawLine) - utf8.RuneLen(lastRune) + 1,
+			EndLine:   line,
+			EndColumn: len(rawLine) + 1,
+			Message:   fmt.Sprintf("Trailing punctuation in heading [Punctuation: '%c']", lastRune),
 		})
 		return ast.WalkContinue, nil
 	})
@@ -17,6 +17,7 @@ type MD027 struct {
 
 func (r MD027) ID() string          { return "MD027" }
 func (r MD027) Aliases() []string   { return []string{"no-multiple-space-blockquote"} }
+func (r MD027) Tags() []string      { return []string{"blockquote", "whitespace", "indentation"} }
 func (r MD027) Description() string { return "Multiple spaces after blockquote symbol" }
 
 // md027FencedCodeMask returns a bool mask marking lines that are inside fenced
@@ -216,12 +217,14 @@ func (r MD027) Check(doc *lint.Document) []lint.Violation {
 		if listInBQ[i] {
 			continue
 		}
-		if violated, _, _ := md027ViolationLine(line); violated {
+		if violated, before, sp := md027ViolationLine(line); violated {
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    i + 1,
-				Column:  1,
-				Message: "Multiple spaces after blockquote symbol",
+				Rule:      r.ID(),
+				Line:      i + 1,
+				Column:    len(before) + 1,
+				EndLine:   i + 1,
+				EndColumn: len(before) + len(sp) + 1,
+				Message:   "Multiple spaces after blockquote symbol",
 			})
 		}
 	}
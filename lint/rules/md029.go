@@ -13,11 +13,12 @@ import (
 // MD029 checks that ordered list items use a consistent numbering style.
 type MD029 struct {
 	// Style is the required style: "one_or_ordered" (default), "one", "ordered", or "zero".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"one_or_ordered,one,ordered,zero"`
 }
 
 func (r MD029) ID() string          { return "MD029" }
 func (r MD029) Aliases() []string   { return []string{"ol-prefix"} }
+func (r MD029) Tags() []string      { return []string{"ol"} }
 func (r MD029) Description() string { return "Ordered list item prefix" }
 
 // orderedItemRE matches an ordered list item prefix, capturing leading spaces,
@@ -20,6 +20,7 @@ type MD030 struct {
 
 func (r MD030) ID() string          { return "MD030" }
 func (r MD030) Aliases() []string   { return []string{"list-marker-space"} }
+func (r MD030) Tags() []string      { return []string{"ol", "ul", "whitespace"} }
 func (r MD030) Description() string { return "Spaces after list markers" }
 
 // md030FullRE captures marker type and the spaces following it.
@@ -102,10 +103,12 @@ func (r MD030) Check(doc *lint.Document) []lint.Violation {
 		}
 		if len(spaces) != expected {
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    i + 1,
-				Column:  len(m[1]) + len(marker) + 1,
-				Message: fmt.Sprintf("Spaces after list markers [Expected: %d; Actual: %d]", expected, len(spaces)),
+				Rule:      r.ID(),
+				Line:      i + 1,
+				Column:    len(m[1]) + len(marker) + 1,
+				EndLine:   i + 1,
+				EndColumn: len(m[1]) + len(marker) + len(spaces) + 1,
+				Message:   fmt.Sprintf("Spaces after list markers [Expected: %d; Actual: %d]", expected, len(spaces)),
 			})
 		}
 	}
@@ -16,6 +16,7 @@ type MD031 struct {
 
 func (r MD031) ID() string          { return "MD031" }
 func (r MD031) Aliases() []string   { return []string{"blanks-around-fences"} }
+func (r MD031) Tags() []string      { return []string{"code", "blank_lines"} }
 func (r MD031) Description() string { return "Fenced code blocks should be surrounded by blank lines" }
 
 // detectFence returns (isFence, fenceChar, fenceLen) for a line.
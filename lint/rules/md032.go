@@ -13,6 +13,7 @@ type MD032 struct{}
 
 func (r MD032) ID() string          { return "MD032" }
 func (r MD032) Aliases() []string   { return []string{"blanks-around-lists"} }
+func (r MD032) Tags() []string      { return []string{"bullet", "ul", "ol", "blank_lines"} }
 func (r MD032) Description() string { return "Lists should be surrounded by blank lines" }
 
 // listItemRE matches unordered or ordered list item lines.
@@ -275,7 +276,7 @@ func (r MD032) Check(doc *lint.Document) []lint.Violation {
 			}
 			if md032LeadingSpaces(line) >= offset {
 				lastContentLine = i + 1 // 1-based
-				continue // continuation/indented content of the last list item
+				continue                // continuation/indented content of the last list item
 			}
 			if !isBlockLevelBreaker(line) {
 				// Lazy continuation of the last list item's paragraph: keep scanning
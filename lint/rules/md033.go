@@ -20,6 +20,7 @@ type MD033 struct {
 
 func (r MD033) ID() string          { return "MD033" }
 func (r MD033) Aliases() []string   { return []string{"no-inline-html"} }
+func (r MD033) Tags() []string      { return []string{"html"} }
 func (r MD033) Description() string { return "Inline HTML" }
 
 // htmlOpenTagRE matches opening HTML tags (not closing tags like </div>).
@@ -89,8 +90,8 @@ func (r MD033) Check(doc *lint.Document) []lint.Violation {
 					if strings.HasPrefix(strings.TrimSpace(lineContent), "<!--") {
 						continue
 					}
-					for _, m := range htmlOpenTagRE.FindAllStringSubmatch(lineContent, -1) {
-						tag := strings.ToLower(m[1])
+					for _, m := range htmlOpenTagRE.FindAllStringSubmatchIndex(lineContent, -1) {
+						tag := strings.ToLower(lineContent[m[2]:m[3]])
 						if r.isAllowed(tag) {
 							continue
 						}
@@ -100,12 +101,8 @@ func (r MD033) Check(doc *lint.Document) []lint.Violation {
 								continue
 							}
 						}
-						violations = append(violations, lint.Violation{
-							Rule:    r.ID(),
-							Line:    lineNum,
-							Column:  1,
-							Message: fmt.Sprintf("Inline HTML [Element: %s]", tag),
-						})
+						violations = append(violations, rangeViolation(doc, r.ID(), seg.Start+m[0], seg.Start+m[1],
+							fmt.Sprintf("Inline HTML [Element: %s]", tag)))
 					}
 					// Also detect opening tags whose attributes span multiple lines
 					// (i.e., the closing ">" appears on a later line).  The per-line
@@ -113,8 +110,8 @@ func (r MD033) Check(doc *lint.Document) []lint.Violation {
 					// htmlOpenTagStartRE uses [^>]*$ which only matches when no ">"
 					// appears between the tag name and end-of-line, so it naturally
 					// skips complete tags and avoids double-reporting.
-					for _, m := range htmlOpenTagStartRE.FindAllStringSubmatch(lineContent, -1) {
-						tag := strings.ToLower(m[1])
+					for _, m := range htmlOpenTagStartRE.FindAllStringSubmatchIndex(lineContent, -1) {
+						tag := strings.ToLower(lineContent[m[2]:m[3]])
 						if r.isAllowed(tag) {
 							continue
 						}
@@ -124,12 +121,8 @@ func (r MD033) Check(doc *lint.Document) []lint.Violation {
 								continue
 							}
 						}
-						violations = append(violations, lint.Violation{
-							Rule:    r.ID(),
-							Line:    lineNum,
-							Column:  1,
-							Message: fmt.Sprintf("Inline HTML [Element: %s]", tag),
-						})
+						violations = append(violations, rangeViolation(doc, r.ID(), seg.Start+m[0], seg.Start+m[1],
+							fmt.Sprintf("Inline HTML [Element: %s]", tag)))
 					}
 				}
 			}
@@ -157,12 +150,9 @@ func (r MD033) Check(doc *lint.Document) []lint.Violation {
 					return ast.WalkContinue, nil
 				}
 			}
-			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    lineNum,
-				Column:  1,
-				Message: fmt.Sprintf("Inline HTML [Element: %s]", tag),
-			})
+			start, end, _ := nodeRange(node)
+			violations = append(violations, rangeViolation(doc, r.ID(), start, end,
+				fmt.Sprintf("Inline HTML [Element: %s]", tag)))
 		}
 
 		return ast.WalkContinue, nil
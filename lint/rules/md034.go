@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"bytes"
 	"regexp"
 	"strings"
 
@@ -13,6 +14,7 @@ type MD034 struct{}
 
 func (r MD034) ID() string          { return "MD034" }
 func (r MD034) Aliases() []string   { return []string{"no-bare-urls"} }
+func (r MD034) Tags() []string      { return []string{"links", "url"} }
 func (r MD034) Description() string { return "Bare URL used" }
 
 // bareURLRE matches an http or https URL within a string, stopping at whitespace
@@ -31,18 +33,14 @@ func (r MD034) Check(doc *lint.Document) []lint.Violation {
 	}
 	seen := make(map[reported]bool)
 
-	addViolation := func(lineNum int, url string) {
-		key := reported{lineNum, url}
+	// addViolation reports the URL at doc.Source[start:end].
+	addViolation := func(start, end int) {
+		key := reported{countLine(doc.Source, start), string(doc.Source[start:end])}
 		if seen[key] {
 			return
 		}
 		seen[key] = true
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    lineNum,
-			Column:  1,
-			Message: "Bare URL used",
-		})
+		violations = append(violations, rangeViolation(doc, r.ID(), start, end, "Bare URL used"))
 	}
 
 	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -65,12 +63,9 @@ func (r MD034) Check(doc *lint.Document) []lint.Violation {
 
 		seg := t.Segment
 		text := string(doc.Source[seg.Start:seg.Stop])
-		lineBase := countLine(doc.Source, seg.Start)
 
-		// Report each bare URL on its own line.
-		// Use FindAllStringIndex to get precise positions for multi-line text nodes.
+		// Report each bare URL at its own position.
 		for _, loc := range bareURLRE.FindAllStringIndex(text, -1) {
-			lineNum := lineBase + strings.Count(text[:loc[0]], "\n")
 			// Skip URLs that appear to be link destinations in broken link syntax.
 			// When the source has ['label'(url) or similar (a '[' that was consumed
 			// as a link opener by the parser, leaving the label as a text node), and
@@ -78,38 +73,45 @@ func (r MD034) Check(doc *lint.Document) []lint.Violation {
 			// it as an attempted link destination rather than a bare URL.
 			// We detect this by scanning the raw source from the start of the current
 			// line up to the '(' character and checking for an unclosed '['.
-			if loc[0] > 0 && text[loc[0]-1] == '(' {
-				// Position of '(' in the original source.
-				srcParenPos := seg.Start + loc[0] - 1
-				// Find the start of the current line in the source.
-				lineStartInSrc := srcParenPos
-				for lineStartInSrc > 0 && doc.Source[lineStartInSrc-1] != '\n' {
-					lineStartInSrc--
-				}
-				// Count unclosed '[' in source from line start up to '('.
-				depth := 0
-				for _, b := range doc.Source[lineStartInSrc:srcParenPos] {
-					if b == '[' {
-						depth++
-					} else if b == ']' && depth > 0 {
-						depth--
-					}
-				}
-				if depth > 0 {
-					// Unclosed '[' before this '(url)': looks like an attempted link.
-					continue
-				}
+			if loc[0] > 0 && text[loc[0]-1] == '(' && md034InBrokenLink(doc.Source, seg.Start+loc[0]-1) {
+				continue
 			}
-			addViolation(lineNum, text[loc[0]:loc[1]])
+			addViolation(seg.Start+loc[0], seg.Start+loc[1])
 		}
 		return ast.WalkContinue, nil
 	})
 
+	// With the linkify extension, goldmark turns bare URLs into AutoLink nodes
+	// instead of leaving them in Text nodes.
+	if doc.Extensions[lint.ExtLinkify] {
+		_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			al, ok := n.(*ast.AutoLink)
+			if !entering || !ok || al.AutoLinkType != ast.AutoLinkURL {
+				return ast.WalkContinue, nil
+			}
+			// Only http(s) URLs are reported, as without the extension.
+			if !bareURLRE.Match(al.Label(doc.Source)) {
+				return ast.WalkContinue, nil
+			}
+			start, end, ok := md034AutoLinkRange(doc.Source, al)
+			if !ok || start > 0 && doc.Source[start-1] == '<' {
+				return ast.WalkContinue, nil
+			}
+			if start > 0 && doc.Source[start-1] == '(' && md034InBrokenLink(doc.Source, start-1) {
+				return ast.WalkContinue, nil
+			}
+			addViolation(start, end)
+			return ast.WalkContinue, nil
+		})
+	}
+
 	// Also scan raw lines for footnote definitions containing bare URLs.
 	// Goldmark treats [^n]: url as a link reference definition and does not expose
-	// the URL as a Text node, so we scan the raw source lines directly.
+	// the URL as a Text node, so we scan the raw source lines directly. With the
+	// footnotes extension, it drops definitions that are never referenced.
 	// We strip inline links ([text](url)) from the content first to avoid
 	// flagging URLs that are already properly wrapped in a link.
+	starts := lineStarts(doc.Source)
 	for i, line := range doc.Lines {
 		trimmed := strings.TrimLeft(line, " \t")
 		if !strings.HasPrefix(trimmed, "[^") {
@@ -124,9 +126,58 @@ func (r MD034) Check(doc *lint.Document) []lint.Violation {
 		rest := strings.TrimSpace(trimmed[labelEnd+2:])
 		rest = inlineLinkRE.ReplaceAllString(rest, "")
 		for _, m := range bareURLRE.FindAllString(rest, -1) {
-			addViolation(i+1, m)
+			if j := strings.Index(line, m); j >= 0 {
+				addViolation(starts[i]+j, starts[i]+j+len(m))
+			}
 		}
 	}
 
 	return violations
 }
+
+// md034InBrokenLink reports whether the '(' at parenPos in source follows an
+// unclosed '[' on the same line, so that the URL after it looks like an
+// attempted link destination rather than a bare URL.
+func md034InBrokenLink(source []byte, parenPos int) bool {
+	// Find the start of the current line in the source.
+	lineStart := parenPos
+	for lineStart > 0 && source[lineStart-1] != '\n' {
+		lineStart--
+	}
+	// Count unclosed '[' in source from line start up to '('.
+	depth := 0
+	for _, b := range source[lineStart:parenPos] {
+		if b == '[' {
+			depth++
+		} else if b == ']' && depth > 0 {
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// md034AutoLinkRange returns the byte range of the text of al in source.
+// AutoLink nodes do not expose their position, so the text is searched for
+// after the preceding Text node, or from the start of the enclosing block.
+func md034AutoLinkRange(source []byte, al *ast.AutoLink) (start, end int, ok bool) {
+	label := al.Label(source)
+	from := -1
+	if prev, isText := al.PreviousSibling().(*ast.Text); isText {
+		from = prev.Segment.Stop
+	} else {
+		for p := al.Parent(); p != nil; p = p.Parent() {
+			if p.Type() == ast.TypeBlock && p.Lines() != nil && p.Lines().Len() > 0 {
+				from = p.Lines().At(0).Start
+				break
+			}
+		}
+	}
+	if from < 0 || from > len(source) {
+		return 0, 0, false
+	}
+	i := bytes.Index(source[from:], label)
+	if i < 0 {
+		return 0, 0, false
+	}
+	return from + i, from + i + len(label), true
+}
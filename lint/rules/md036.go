@@ -16,6 +16,7 @@ type MD036 struct {
 
 func (r MD036) ID() string          { return "MD036" }
 func (r MD036) Aliases() []string   { return []string{"no-emphasis-as-heading"} }
+func (r MD036) Tags() []string      { return []string{"headings", "emphasis"} }
 func (r MD036) Description() string { return "Emphasis used instead of a heading" }
 
 const defaultMD036Punctuation = ".,;:!?。，；：！？"
@@ -85,20 +86,13 @@ func (r MD036) Check(doc *lint.Document) []lint.Violation {
 			return ast.WalkContinue, nil
 		}
 
-		line := 1
-		if para.Lines() != nil && para.Lines().Len() > 0 {
-			seg := para.Lines().At(0)
-			line = countLine(doc.Source, seg.Start)
-		}
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    line,
-			Column:  1,
-			Message: "Emphasis used instead of a heading",
-		})
+		// The range covers the emphasis including its delimiters.
+		start, end, _ := nodeRange(emph)
+		start = max(start-emph.Level, 0)
+		end = min(end+emph.Level, len(doc.Source))
+		violations = append(violations, rangeViolation(doc, r.ID(), start, end, "Emphasis used instead of a heading"))
 		return ast.WalkContinue, nil
 	})
 
 	return violations
 }
-
@@ -10,6 +10,7 @@ type MD037 struct{}
 
 func (r MD037) ID() string          { return "MD037" }
 func (r MD037) Aliases() []string   { return []string{"no-space-in-emphasis"} }
+func (r MD037) Tags() []string      { return []string{"whitespace", "emphasis"} }
 func (r MD037) Description() string { return "Spaces inside emphasis markers" }
 
 func (r MD037) Fix(source []byte) []byte {
@@ -30,14 +31,14 @@ func (r MD037) Check(doc *lint.Document) []lint.Violation {
 		if pos < 0 || pos+emph.Level >= len(doc.Source) {
 			return ast.WalkContinue, nil
 		}
+		// The range covers the emphasis including its closing marker.
+		end := pos + emph.Level
+		if _, stop, ok := nodeRange(emph); ok {
+			end = min(stop+emph.Level, len(doc.Source))
+		}
 		// Check for space immediately after opening marker.
 		if doc.Source[pos+emph.Level] == ' ' {
-			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    countLine(doc.Source, pos),
-				Column:  1,
-				Message: "Spaces inside emphasis markers",
-			})
+			violations = append(violations, rangeViolation(doc, r.ID(), pos, end, "Spaces inside emphasis markers"))
 			return ast.WalkContinue, nil
 		}
 		// Check for space immediately before closing marker by examining
@@ -55,12 +56,7 @@ func (r MD037) Check(doc *lint.Document) []lint.Violation {
 		}
 		if _, ok := lastChild.(*ast.Text); ok {
 			if lastStop > 0 && lastStop <= len(doc.Source) && doc.Source[lastStop-1] == ' ' {
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    countLine(doc.Source, pos),
-					Column:  1,
-					Message: "Spaces inside emphasis markers",
-				})
+				violations = append(violations, rangeViolation(doc, r.ID(), pos, end, "Spaces inside emphasis markers"))
 			}
 		}
 		return ast.WalkContinue, nil
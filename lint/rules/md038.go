@@ -12,6 +12,7 @@ type MD038 struct{}
 
 func (r MD038) ID() string          { return "MD038" }
 func (r MD038) Aliases() []string   { return []string{"no-space-in-code"} }
+func (r MD038) Tags() []string      { return []string{"whitespace", "code"} }
 func (r MD038) Description() string { return "Spaces inside code span elements" }
 
 // fixCodeSpanSpaces removes leading/trailing spaces from code span content.
@@ -139,12 +140,7 @@ func (r MD038) Check(doc *lint.Document) []lint.Violation {
 			return ast.WalkContinue, nil
 		}
 		reportedLines[line] = true
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    line,
-			Column:  1,
-			Message: "Spaces inside code span elements",
-		})
+		violations = append(violations, rangeViolation(doc, r.ID(), firstText.Segment.Start, lastText.Segment.Stop, "Spaces inside code span elements"))
 		return ast.WalkContinue, nil
 	})
 
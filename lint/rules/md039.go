@@ -13,6 +13,7 @@ type MD039 struct{}
 
 func (r MD039) ID() string          { return "MD039" }
 func (r MD039) Aliases() []string   { return []string{"no-space-in-links"} }
+func (r MD039) Tags() []string      { return []string{"whitespace", "links"} }
 func (r MD039) Description() string { return "Spaces inside link text" }
 
 // md039RE matches a link with leading or trailing space in its text.
@@ -95,12 +96,7 @@ func (r MD039) Check(doc *lint.Document) []lint.Violation {
 			return ast.WalkContinue, nil
 		}
 
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    inlineNodeLine(link, doc.Source),
-			Column:  1,
-			Message: "Spaces inside link text",
-		})
+		violations = append(violations, linkViolation(doc, r.ID(), link, "Spaces inside link text"))
 		return ast.WalkContinue, nil
 	})
 
@@ -17,6 +17,7 @@ type MD040 struct {
 
 func (r MD040) ID() string          { return "MD040" }
 func (r MD040) Aliases() []string   { return []string{"fenced-code-language"} }
+func (r MD040) Tags() []string      { return []string{"code", "language"} }
 func (r MD040) Description() string { return "Fenced code blocks should have a language specified" }
 
 func (r MD040) Check(doc *lint.Document) []lint.Violation {
@@ -35,15 +36,24 @@ func (r MD040) Check(doc *lint.Document) []lint.Violation {
 		lang := fcb.Language(doc.Source)
 
 		if len(lang) == 0 {
+			// The range covers the opening fence.
+			col := 1
+			if line >= 1 && line <= len(doc.Lines) {
+				col = len(doc.Lines[line-1]) - len(strings.TrimLeft(doc.Lines[line-1], " \t>")) + 1
+			}
 			violations = append(violations, lint.Violation{
 				Rule:    r.ID(),
 				Line:    line,
-				Column:  1,
+				Column:  col,
 				Message: "Fenced code blocks should have a language specified",
 			})
 			return ast.WalkContinue, nil
 		}
 
+		// Violations about the info string cover the info string.
+		info := fcb.Info.Segment
+		infoEnd := info.Start + len(strings.TrimRight(string(info.Value(doc.Source)), " \t\r\n"))
+
 		// Check allowed_languages.
 		if len(r.AllowedLanguages) > 0 {
 			allowed := false
@@ -54,26 +64,13 @@ func (r MD040) Check(doc *lint.Document) []lint.Violation {
 				}
 			}
 			if !allowed {
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    line,
-					Column:  1,
-					Message: "Fenced code blocks should use an allowed language",
-				})
+				violations = append(violations, rangeViolation(doc, r.ID(), info.Start, infoEnd, "Fenced code blocks should use an allowed language"))
 			}
 		}
 
 		// Check language_only: info string must not contain whitespace after the language.
-		if r.LanguageOnly && fcb.Info != nil {
-			info := strings.TrimRight(string(fcb.Info.Segment.Value(doc.Source)), " \t\r\n")
-			if info != string(lang) {
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    line,
-					Column:  1,
-					Message: "Fenced code blocks should only contain a language identifier",
-				})
-			}
+		if r.LanguageOnly && string(doc.Source[info.Start:infoEnd]) != string(lang) {
+			violations = append(violations, rangeViolation(doc, r.ID(), info.Start, infoEnd, "Fenced code blocks should only contain a language identifier"))
 		}
 
 		return ast.WalkContinue, nil
@@ -11,7 +11,7 @@ type MD041 struct {
 	// Level is the required top-level heading level (default 1).
 	Level int `json:"level"`
 	// FrontMatterTitle is a field name or regex pattern used to identify a
-	// title in YAML front matter that satisfies the top-level heading requirement.
+	// title in YAML or TOML front matter that satisfies the top-level heading requirement.
 	// If empty, "title" is used. Set to "^$" to disable.
 	FrontMatterTitle string `json:"front_matter_title"`
 	// AllowPreamble controls whether some non-heading content is allowed before
@@ -23,6 +23,7 @@ type MD041 struct {
 
 func (r MD041) ID() string          { return "MD041" }
 func (r MD041) Aliases() []string   { return []string{"first-line-h1", "first-line-heading"} }
+func (r MD041) Tags() []string      { return []string{"headings"} }
 func (r MD041) Description() string { return "First line in a file should be a top-level heading" }
 
 func (r MD041) Check(doc *lint.Document) []lint.Violation {
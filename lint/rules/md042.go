@@ -10,6 +10,7 @@ type MD042 struct{}
 
 func (r MD042) ID() string          { return "MD042" }
 func (r MD042) Aliases() []string   { return []string{"no-empty-links"} }
+func (r MD042) Tags() []string      { return []string{"links"} }
 func (r MD042) Description() string { return "No empty links" }
 
 // inlineNodeLine returns the 1-based line number of an inline node.
@@ -49,12 +50,7 @@ func (r MD042) Check(doc *lint.Document) []lint.Violation {
 		dest := string(link.Destination)
 		// Check for empty destination
 		if dest == "" || dest == "#" {
-			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    inlineNodeLine(link, doc.Source),
-				Column:  1,
-				Message: "No empty links",
-			})
+			violations = append(violations, linkViolation(doc, r.ID(), link, "No empty links"))
 			return ast.WalkContinue, nil
 		}
 
@@ -76,12 +72,7 @@ func (r MD042) Check(doc *lint.Document) []lint.Violation {
 			}
 		}
 		if !hasText {
-			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    inlineNodeLine(link, doc.Source),
-				Column:  1,
-				Message: "No empty links",
-			})
+			violations = append(violations, linkViolation(doc, r.ID(), link, "No empty links"))
 		}
 		return ast.WalkContinue, nil
 	})
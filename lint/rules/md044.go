@@ -19,6 +19,7 @@ type MD044 struct {
 
 func (r MD044) ID() string          { return "MD044" }
 func (r MD044) Aliases() []string   { return []string{"proper-names"} }
+func (r MD044) Tags() []string      { return []string{"spelling"} }
 func (r MD044) Description() string { return "Proper names should have the correct capitalization" }
 
 func (r MD044) Check(doc *lint.Document) []lint.Violation {
@@ -57,10 +58,12 @@ func (r MD044) Check(doc *lint.Document) []lint.Violation {
 					continue
 				}
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  m[0] + 1,
-					Message: "Proper names should have the correct capitalization [Expected: " + name + "; Actual: " + found + "]",
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    m[0] + 1,
+					EndLine:   i + 1,
+					EndColumn: m[1] + 1,
+					Message:   "Proper names should have the correct capitalization [Expected: " + name + "; Actual: " + found + "]",
 				})
 			}
 		}
@@ -13,6 +13,7 @@ type MD045 struct{}
 
 func (r MD045) ID() string          { return "MD045" }
 func (r MD045) Aliases() []string   { return []string{"no-alt-text"} }
+func (r MD045) Tags() []string      { return []string{"accessibility", "images"} }
 func (r MD045) Description() string { return "Images should have alternate text (alt text)" }
 
 // md045ImgTagRE matches the opening of an HTML <img> tag (case-insensitive).
@@ -40,12 +41,7 @@ func (r MD045) Check(doc *lint.Document) []lint.Violation {
 		case *ast.Image:
 			// Check if the image has non-empty alt text (any non-nil child node).
 			if node.FirstChild() == nil {
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    inlineNodeLine(node, doc.Source),
-					Column:  1,
-					Message: "Images should have alternate text (alt text)",
-				})
+				violations = append(violations, linkViolation(doc, r.ID(), node, "Images should have alternate text (alt text)"))
 			}
 
 		case *ast.RawHTML:
@@ -63,14 +59,8 @@ func (r MD045) Check(doc *lint.Document) []lint.Violation {
 				return ast.WalkContinue, nil
 			}
 			if !md045AltAttrRE.MatchString(tagText) && !md045AriaHiddenTrueRE.MatchString(tagText) {
-				seg := node.Segments.At(0)
-				lineNum := countLine(doc.Source, seg.Start)
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    lineNum,
-					Column:  1,
-					Message: "Images should have alternate text (alt text)",
-				})
+				start, end, _ := nodeRange(node)
+				violations = append(violations, rangeViolation(doc, r.ID(), start, end, "Images should have alternate text (alt text)"))
 			}
 
 		case *ast.HTMLBlock:
@@ -86,14 +76,8 @@ func (r MD045) Check(doc *lint.Document) []lint.Violation {
 			for _, match := range md045BlockImgTagRE.FindAllStringIndex(blockText, -1) {
 				tag := blockText[match[0]:match[1]]
 				if !md045AltAttrRE.MatchString(tag) && !md045AriaHiddenTrueRE.MatchString(tag) {
-					// Report the line where the <img> starts.
-					lineNum := countLine(doc.Source, firstSeg.Start+match[0])
-					violations = append(violations, lint.Violation{
-						Rule:    r.ID(),
-						Line:    lineNum,
-						Column:  1,
-						Message: "Images should have alternate text (alt text)",
-					})
+					violations = append(violations, rangeViolation(doc, r.ID(), firstSeg.Start+match[0], firstSeg.Start+match[1],
+						"Images should have alternate text (alt text)"))
 				}
 			}
 		}
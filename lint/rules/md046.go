@@ -10,11 +10,12 @@ import (
 // MD046 checks code block style consistency.
 type MD046 struct {
 	// Style is "consistent" (default), "fenced", or "indented".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,fenced,indented"`
 }
 
 func (r MD046) ID() string          { return "MD046" }
 func (r MD046) Aliases() []string   { return []string{"code-block-style"} }
+func (r MD046) Tags() []string      { return []string{"code"} }
 func (r MD046) Description() string { return "Code block style" }
 
 func (r MD046) Check(doc *lint.Document) []lint.Violation {
@@ -60,12 +61,18 @@ func (r MD046) Check(doc *lint.Document) []lint.Violation {
 		}
 
 		if blockStyle != expected {
-			violations = append(violations, lint.Violation{
+			v := lint.Violation{
 				Rule:    r.ID(),
 				Line:    lineNum,
 				Column:  1,
 				Message: fmt.Sprintf("Code block style [Expected: %s; Actual: %s]", expected, blockStyle),
-			})
+			}
+			// An indented block is reported as a whole; a fenced one at its
+			// opening fence.
+			if start, end, ok := nodeRange(n); ok && blockStyle == "indented" {
+				v = rangeViolation(doc, r.ID(), start, end, v.Message)
+			}
+			violations = append(violations, v)
 		}
 
 		return ast.WalkContinue, nil
@@ -9,14 +9,12 @@ type MD047 struct{}
 
 func (r MD047) ID() string          { return "MD047" }
 func (r MD047) Aliases() []string   { return []string{"single-trailing-newline"} }
+func (r MD047) Tags() []string      { return []string{"blank_lines"} }
 func (r MD047) Description() string { return "Files should end with a single newline character" }
 
-func (r MD047) Fix(source []byte) []byte {
-	if len(source) == 0 || source[len(source)-1] == '\n' {
-		return source
-	}
-	return append(source, '\n')
-}
+func (r MD047) FixesWithEdits() bool { return true }
+
+func (r MD047) Fix(source []byte) []byte { return lint.FixWithEdits(r, source) }
 
 func (r MD047) Check(doc *lint.Document) []lint.Violation {
 	if len(doc.Source) == 0 {
@@ -29,6 +27,7 @@ func (r MD047) Check(doc *lint.Document) []lint.Violation {
 			Line:    len(doc.Lines),
 			Column:  len(doc.Lines[len(doc.Lines)-1]) + 1,
 			Message: "Files should end with a single newline character",
+			Fix:     []lint.Edit{{Start: len(doc.Source), End: len(doc.Source), NewText: "\n"}},
 		}}
 	}
 	return nil
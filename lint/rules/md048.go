@@ -10,11 +10,12 @@ import (
 // MD048 checks that code fences use a consistent style (backtick or tilde).
 type MD048 struct {
 	// Style is "consistent" (default), "backtick", or "tilde".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,backtick,tilde"`
 }
 
 func (r MD048) ID() string          { return "MD048" }
 func (r MD048) Aliases() []string   { return []string{"code-fence-style"} }
+func (r MD048) Tags() []string      { return []string{"code"} }
 func (r MD048) Description() string { return "Code fence style" }
 
 func (r MD048) Check(doc *lint.Document) []lint.Violation {
@@ -52,11 +53,15 @@ func (r MD048) Check(doc *lint.Document) []lint.Violation {
 				expected = firstChar
 			}
 			if expected != 0 && fc != expected {
+				// The range covers the fence characters.
+				indent := len(line) - len(strings.TrimLeft(line, " "))
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  1,
-					Message: fmt.Sprintf("Code fence style [Expected: %s; Actual: %s]", fenceCharName(expected), fenceCharName(fc)),
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    indent + 1,
+					EndLine:   i + 1,
+					EndColumn: indent + fl + 1,
+					Message:   fmt.Sprintf("Code fence style [Expected: %s; Actual: %s]", fenceCharName(expected), fenceCharName(fc)),
 				})
 			}
 		} else {
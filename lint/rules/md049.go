@@ -12,11 +12,12 @@ import (
 // MD049 checks that emphasis markers use a consistent style (asterisk or underscore).
 type MD049 struct {
 	// Style is "consistent" (default), "asterisk", or "underscore".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,asterisk,underscore"`
 }
 
 func (r MD049) ID() string          { return "MD049" }
 func (r MD049) Aliases() []string   { return []string{"emphasis-style"} }
+func (r MD049) Tags() []string      { return []string{"emphasis"} }
 func (r MD049) Description() string { return "Emphasis style should be consistent" }
 
 // md049StarRE matches single-asterisk emphasis *text* (not **).
@@ -74,12 +75,8 @@ func (r MD049) Check(doc *lint.Document) []lint.Violation {
 		}
 
 		// Report opening marker violation.
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    inlineNodeLine(emph, doc.Source),
-			Column:  1,
-			Message: fmt.Sprintf("Emphasis style [Expected: %s; Actual: %s]", expected, actual),
-		})
+		violations = append(violations, rangeViolation(doc, r.ID(), pos, pos+emph.Level,
+			fmt.Sprintf("Emphasis style [Expected: %s; Actual: %s]", expected, actual)))
 		// Report closing marker violation (markdownlint reports both opening and closing).
 		var lastTextStop int
 		for c := emph.FirstChild(); c != nil; c = c.NextSibling() {
@@ -95,10 +92,12 @@ func (r MD049) Check(doc *lint.Document) []lint.Violation {
 				lineStart--
 			}
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    closingLine,
-				Column:  lastTextStop - lineStart + 1,
-				Message: fmt.Sprintf("Emphasis style [Expected: %s; Actual: %s]", expected, actual),
+				Rule:      r.ID(),
+				Line:      closingLine,
+				Column:    lastTextStop - lineStart + 1,
+				EndLine:   closingLine,
+				EndColumn: lastTextStop - lineStart + emph.Level + 1,
+				Message:   fmt.Sprintf("Emphasis style [Expected: %s; Actual: %s]", expected, actual),
 			})
 		}
 		return ast.WalkContinue, nil
@@ -12,11 +12,12 @@ import (
 // MD050 checks that strong markers use a consistent style (asterisk or underscore).
 type MD050 struct {
 	// Style is "consistent" (default), "asterisk", or "underscore".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,asterisk,underscore"`
 }
 
 func (r MD050) ID() string          { return "MD050" }
 func (r MD050) Aliases() []string   { return []string{"strong-style"} }
+func (r MD050) Tags() []string      { return []string{"emphasis"} }
 func (r MD050) Description() string { return "Strong style should be consistent" }
 
 // md050StarRE matches double-asterisk strong **text**.
@@ -71,12 +72,8 @@ func (r MD050) Check(doc *lint.Document) []lint.Violation {
 		}
 
 		// Report opening marker violation.
-		violations = append(violations, lint.Violation{
-			Rule:    r.ID(),
-			Line:    inlineNodeLine(emph, doc.Source),
-			Column:  1,
-			Message: fmt.Sprintf("Strong style [Expected: %s; Actual: %s]", expected, actual),
-		})
+		violations = append(violations, rangeViolation(doc, r.ID(), pos, pos+emph.Level,
+			fmt.Sprintf("Strong style [Expected: %s; Actual: %s]", expected, actual)))
 		// Report closing marker violation (markdownlint reports both opening and closing).
 		// Find the last text stop position recursively in case of complex inline children.
 		lastTextStop := lastTextStopInInline(emph)
@@ -88,10 +85,12 @@ func (r MD050) Check(doc *lint.Document) []lint.Violation {
 				lineStart--
 			}
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    closingLine,
-				Column:  lastTextStop - lineStart + 1,
-				Message: fmt.Sprintf("Strong style [Expected: %s; Actual: %s]", expected, actual),
+				Rule:      r.ID(),
+				Line:      closingLine,
+				Column:    lastTextStop - lineStart + 1,
+				EndLine:   closingLine,
+				EndColumn: lastTextStop - lineStart + emph.Level + 1,
+				Message:   fmt.Sprintf("Strong style [Expected: %s; Actual: %s]", expected, actual),
 			})
 		}
 		return ast.WalkContinue, nil
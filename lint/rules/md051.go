@@ -2,7 +2,6 @@ package rules
 
 import (
 	"regexp"
-	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
 	"github.com/yuin/goldmark/ast"
@@ -14,10 +13,15 @@ type MD051 struct {
 	IgnoreCase bool `json:"ignore_case"`
 	// IgnoredPattern is a regex pattern for fragments to ignore.
 	IgnoredPattern string `json:"ignored_pattern"`
+	// Slugger names the algorithm that generates heading anchors: "github"
+	// (default), "gitlab", "pandoc", "kramdown", "mkdocs", "hugo", or one
+	// added with RegisterSlugger.
+	Slugger string `json:"slugger"`
 }
 
 func (r MD051) ID() string          { return "MD051" }
 func (r MD051) Aliases() []string   { return []string{"link-fragments"} }
+func (r MD051) Tags() []string      { return []string{"links"} }
 func (r MD051) Description() string { return "Link fragments should be valid" }
 
 // md051FragRE matches internal links with fragments: [text](#fragment).
@@ -33,31 +37,7 @@ var md051LineRefRE = regexp.MustCompile(`^L\d+(?:C\d+-L\d+C\d+)?$`)
 var md051HTMLAnchorRE = regexp.MustCompile(`(?i)(?:id|name)="([^"]+)"`)
 
 func (r MD051) Check(doc *lint.Document) []lint.Violation {
-	// Collect heading anchors.
-	anchors := make(map[string]bool)
-	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering {
-			return ast.WalkContinue, nil
-		}
-		h, ok := n.(*ast.Heading)
-		if !ok {
-			return ast.WalkContinue, nil
-		}
-		text := headingText(h, doc.Source)
-		anchor := headingAnchor(text)
-		anchors[anchor] = true
-		if r.IgnoreCase {
-			anchors[strings.ToLower(anchor)] = true
-		}
-		return ast.WalkContinue, nil
-	})
-
-	// Collect HTML anchors.
-	for _, line := range doc.Lines {
-		for _, m := range md051HTMLAnchorRE.FindAllStringSubmatch(line, -1) {
-			anchors[m[1]] = true
-		}
-	}
+	anchors := documentAnchors(doc, r.Slugger, r.IgnoreCase)
 
 	var ignoredRE *regexp.Regexp
 	if r.IgnoredPattern != "" {
@@ -99,8 +79,8 @@ func (r MD051) Check(doc *lint.Document) []lint.Violation {
 		// Blank out inline code spans before scanning to avoid false positives
 		// from patterns like `[text](#frag)` inside code spans.
 		checkLine := blankCodeSpans(line)
-		for _, m := range md051FragRE.FindAllStringSubmatch(checkLine, -1) {
-			fragment := m[2]
+		for _, loc := range md051FragRE.FindAllStringSubmatchIndex(checkLine, -1) {
+			fragment := checkLine[loc[4]:loc[5]]
 			// Always allow #top.
 			if fragment == "top" {
 				continue
@@ -114,16 +94,14 @@ func (r MD051) Check(doc *lint.Document) []lint.Violation {
 				continue
 			}
 			// Check if anchor exists.
-			checkFrag := fragment
-			if r.IgnoreCase {
-				checkFrag = strings.ToLower(fragment)
-			}
-			if !anchors[checkFrag] {
+			if !anchorExists(anchors, fragment, r.IgnoreCase) {
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  1,
-					Message: "Link fragments should be valid [Fragment: #" + fragment + "]",
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    loc[0] + 1,
+					EndLine:   i + 1,
+					EndColumn: loc[1] + 1,
+					Message:   "Link fragments should be valid [Fragment: #" + fragment + "]",
 				})
 			}
 		}
@@ -134,27 +112,26 @@ func (r MD051) Check(doc *lint.Document) []lint.Violation {
 		if extMask[i] {
 			continue
 		}
-		m := md051DefFragRE.FindStringSubmatch(line)
-		if m == nil {
+		loc := md051DefFragRE.FindStringSubmatchIndex(line)
+		if loc == nil {
 			continue
 		}
-		fragment := m[1]
+		fragment := line[loc[2]:loc[3]]
 		if fragment == "top" || md051LineRefRE.MatchString(fragment) {
 			continue
 		}
 		if ignoredRE != nil && ignoredRE.MatchString(fragment) {
 			continue
 		}
-		checkFrag := fragment
-		if r.IgnoreCase {
-			checkFrag = strings.ToLower(fragment)
-		}
-		if !anchors[checkFrag] {
+		if !anchorExists(anchors, fragment, r.IgnoreCase) {
+			// The range covers the fragment destination.
 			violations = append(violations, lint.Violation{
-				Rule:    r.ID(),
-				Line:    i + 1,
-				Column:  1,
-				Message: "Link fragments should be valid [Fragment: #" + fragment + "]",
+				Rule:      r.ID(),
+				Line:      i + 1,
+				Column:    loc[2],
+				EndLine:   i + 1,
+				EndColumn: loc[3] + 1,
+				Message:   "Link fragments should be valid [Fragment: #" + fragment + "]",
 			})
 		}
 	}
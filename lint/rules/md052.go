@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
 )
 
 // MD052 checks that reference links and images use defined labels.
@@ -19,6 +21,7 @@ func (r MD052) ID() string { return "MD052" }
 func (r MD052) Aliases() []string {
 	return []string{"reference-links-images"}
 }
+func (r MD052) Tags() []string { return []string{"links", "images"} }
 func (r MD052) Description() string {
 	return "Reference links and images should use a label that is defined"
 }
@@ -126,6 +129,16 @@ func (r MD052) Check(doc *lint.Document) []lint.Violation {
 		}
 	}
 
+	// With the footnotes extension, footnote references such as [^1] are
+	// parsed as footnotes rather than shortcut references; skip the labels of
+	// the footnotes the document defines.
+	_ = ast.Walk(doc.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if fn, ok := n.(*east.Footnote); ok && entering {
+			ignored["^"+strings.ToLower(string(fn.Ref))] = true
+		}
+		return ast.WalkContinue, nil
+	})
+
 	var violations []lint.Violation
 	for i, line := range doc.Lines {
 		if skipLine(i) {
@@ -133,7 +146,8 @@ func (r MD052) Check(doc *lint.Document) []lint.Violation {
 		}
 		checkLine := blankCodeSpans(line)
 		// Full references: [text][label] - label is group 1, may be empty (collapsed).
-		for _, m := range md052FullRE.FindAllStringSubmatch(checkLine, -1) {
+		for _, loc := range md052FullRE.FindAllStringSubmatchIndex(checkLine, -1) {
+			m := submatches(checkLine, loc)
 			label := strings.ToLower(m[1])
 			if label == "" {
 				continue // collapsed handled below
@@ -143,41 +157,49 @@ func (r MD052) Check(doc *lint.Document) []lint.Violation {
 			}
 			if !defined[label] {
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  1,
-					Message: "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    loc[0] + 1,
+					EndLine:   i + 1,
+					EndColumn: loc[1] + 1,
+					Message:   "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
 				})
 			}
 		}
 		// Collapsed references: [label][].
-		for _, m := range md052CollapsedRE.FindAllStringSubmatch(checkLine, -1) {
+		for _, loc := range md052CollapsedRE.FindAllStringSubmatchIndex(checkLine, -1) {
+			m := submatches(checkLine, loc)
 			label := strings.ToLower(m[1])
 			if ignored[label] {
 				continue
 			}
 			if !defined[label] {
 				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    i + 1,
-					Column:  1,
-					Message: "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
+					Rule:      r.ID(),
+					Line:      i + 1,
+					Column:    loc[0] + 1,
+					EndLine:   i + 1,
+					EndColumn: loc[1] + 1,
+					Message:   "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
 				})
 			}
 		}
 		// Shortcut references: [label].
 		if r.ShortcutSyntax {
-			for _, m := range md052ShortcutRE.FindAllStringSubmatch(checkLine, -1) {
+			for _, loc := range md052ShortcutRE.FindAllStringSubmatchIndex(checkLine, -1) {
+				m := submatches(checkLine, loc)
 				label := strings.ToLower(m[1])
 				if ignored[label] {
 					continue
 				}
 				if !defined[label] {
 					violations = append(violations, lint.Violation{
-						Rule:    r.ID(),
-						Line:    i + 1,
-						Column:  1,
-						Message: "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
+						Rule:      r.ID(),
+						Line:      i + 1,
+						Column:    loc[0] + 1,
+						EndLine:   i + 1,
+						EndColumn: loc[1] + 1,
+						Message:   "Reference links and images should use a label that is defined [Label: " + m[1] + "]",
 					})
 				}
 			}
@@ -18,6 +18,7 @@ type MD054 struct {
 
 func (r MD054) ID() string          { return "MD054" }
 func (r MD054) Aliases() []string   { return []string{"link-image-style"} }
+func (r MD054) Tags() []string      { return []string{"links", "images"} }
 func (r MD054) Description() string { return "Link and image style" }
 
 func (r MD054) defaults() MD054 {
@@ -66,19 +67,21 @@ func (r MD054) Check(doc *lint.Document) []lint.Violation {
 		}
 		// Check autolinks.
 		if !cfg.Autolink {
-			for _, m := range md054AutolinkRE.FindAllStringSubmatch(line, -1) {
+			for _, loc := range md054AutolinkRE.FindAllStringSubmatchIndex(line, -1) {
+				m := submatches(line, loc)
 				violations = append(violations, lint.Violation{
-					Rule: r.ID(), Line: i + 1, Column: 1,
+					Rule: r.ID(), Line: i + 1, Column: loc[0] + 1, EndLine: i + 1, EndColumn: loc[1] + 1,
 					Message: "Link and image style [Autolink not allowed: " + m[0] + "]",
 				})
 			}
 		}
 		// Check url_inline (inline link where text == url).
 		if !cfg.URLInline {
-			for _, m := range md054URLInlineRE.FindAllStringSubmatch(line, -1) {
+			for _, loc := range md054URLInlineRE.FindAllStringSubmatchIndex(line, -1) {
+				m := submatches(line, loc)
 				if m[1] == m[2] {
 					violations = append(violations, lint.Violation{
-						Rule: r.ID(), Line: i + 1, Column: 1,
+						Rule: r.ID(), Line: i + 1, Column: loc[0] + 1, EndLine: i + 1, EndColumn: loc[1] + 1,
 						Message: "Link and image style [URL inline not allowed: " + m[0] + "]",
 					})
 				}
@@ -86,31 +89,34 @@ func (r MD054) Check(doc *lint.Document) []lint.Violation {
 		}
 		// Check inline links.
 		if !cfg.Inline {
-			for _, m := range md054InlineRE.FindAllStringSubmatch(line, -1) {
+			for _, loc := range md054InlineRE.FindAllStringSubmatchIndex(line, -1) {
+				m := submatches(line, loc)
 				// Skip autolinks.
 				if md054AutolinkRE.MatchString(m[0]) {
 					continue
 				}
 				violations = append(violations, lint.Violation{
-					Rule: r.ID(), Line: i + 1, Column: 1,
+					Rule: r.ID(), Line: i + 1, Column: loc[0] + 1, EndLine: i + 1, EndColumn: loc[1] + 1,
 					Message: "Link and image style [Inline link not allowed: " + m[0] + "]",
 				})
 			}
 		}
 		// Check full references.
 		if !cfg.Full {
-			for _, m := range md054FullRefRE.FindAllStringSubmatch(line, -1) {
+			for _, loc := range md054FullRefRE.FindAllStringSubmatchIndex(line, -1) {
+				m := submatches(line, loc)
 				violations = append(violations, lint.Violation{
-					Rule: r.ID(), Line: i + 1, Column: 1,
+					Rule: r.ID(), Line: i + 1, Column: loc[0] + 1, EndLine: i + 1, EndColumn: loc[1] + 1,
 					Message: "Link and image style [Full reference not allowed: " + m[0] + "]",
 				})
 			}
 		}
 		// Check collapsed references.
 		if !cfg.Collapsed {
-			for _, m := range md054CollapsedRefRE.FindAllStringSubmatch(line, -1) {
+			for _, loc := range md054CollapsedRefRE.FindAllStringSubmatchIndex(line, -1) {
+				m := submatches(line, loc)
 				violations = append(violations, lint.Violation{
-					Rule: r.ID(), Line: i + 1, Column: 1,
+					Rule: r.ID(), Line: i + 1, Column: loc[0] + 1, EndLine: i + 1, EndColumn: loc[1] + 1,
 					Message: "Link and image style [Collapsed reference not allowed: " + m[0] + "]",
 				})
 			}
@@ -11,11 +11,12 @@ import (
 type MD055 struct {
 	// Style is "consistent" (default), "leading_and_trailing", "leading_only",
 	// "no_leading_or_trailing", or "trailing_only".
-	Style string `json:"style"`
+	Style string `json:"style" enum:"consistent,leading_and_trailing,leading_only,no_leading_or_trailing,trailing_only"`
 }
 
 func (r MD055) ID() string          { return "MD055" }
 func (r MD055) Aliases() []string   { return []string{"table-pipe-style"} }
+func (r MD055) Tags() []string      { return []string{"table"} }
 func (r MD055) Description() string { return "Table pipe style" }
 
 func rowPipeStyle(line string) string {
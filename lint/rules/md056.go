@@ -11,6 +11,7 @@ type MD056 struct{}
 
 func (r MD056) ID() string          { return "MD056" }
 func (r MD056) Aliases() []string   { return []string{"table-column-count"} }
+func (r MD056) Tags() []string      { return []string{"table"} }
 func (r MD056) Description() string { return "Table column count" }
 
 func (r MD056) Check(doc *lint.Document) []lint.Violation {
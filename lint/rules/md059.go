@@ -15,6 +15,7 @@ type MD059 struct {
 
 func (r MD059) ID() string          { return "MD059" }
 func (r MD059) Aliases() []string   { return []string{"descriptive-link-text"} }
+func (r MD059) Tags() []string      { return []string{"links", "accessibility"} }
 func (r MD059) Description() string { return "Link text should be descriptive" }
 
 func (r MD059) prohibited() []string {
@@ -44,12 +45,7 @@ func (r MD059) Check(doc *lint.Document) []lint.Violation {
 		text = strings.TrimRight(text, ".,;:!?")
 		for _, p := range prohibited {
 			if strings.EqualFold(text, p) {
-				violations = append(violations, lint.Violation{
-					Rule:    r.ID(),
-					Line:    inlineNodeLine(link, doc.Source),
-					Column:  1,
-					Message: "Link text should be descriptive [Text: " + text + "]",
-				})
+				violations = append(violations, linkViolation(doc, r.ID(), link, "Link text should be descriptive [Text: "+text+"]"))
 				break
 			}
 		}
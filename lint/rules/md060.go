@@ -10,12 +10,13 @@ import (
 // MD060 checks table column style consistency.
 type MD060 struct {
 	// Style is "any" (default), "compact", "tight", "aligned", or "consistent".
-	Style            string `json:"style"`
+	Style            string `json:"style" enum:"any,compact,tight,aligned,consistent"`
 	AlignedDelimiter bool   `json:"aligned_delimiter"`
 }
 
 func (r MD060) ID() string          { return "MD060" }
 func (r MD060) Aliases() []string   { return []string{"table-column-style"} }
+func (r MD060) Tags() []string      { return []string{"table"} }
 func (r MD060) Description() string { return "Table column style" }
 
 func tableColumnStyle(line string) string {
@@ -93,10 +94,12 @@ func tableAlignedViolations(lines []string, t [2]int, ruleID string) []lint.Viol
 		for _, p := range rowPipes {
 			if len(remaining) > 0 && !remaining[p] {
 				violations = append(violations, lint.Violation{
-					Rule:    ruleID,
-					Line:    row + 1,
-					Column:  p + 1,
-					Message: `Table column style [Expected: aligned; Actual: not aligned]`,
+					Rule:      ruleID,
+					Line:      row + 1,
+					Column:    p + 1,
+					EndLine:   row + 1,
+					EndColumn: p + 2,
+					Message:   `Table column style [Expected: aligned; Actual: not aligned]`,
 				})
 			}
 			delete(remaining, p)
@@ -151,15 +154,15 @@ func rowCompactTightViolations(line, ruleID string, lineNum int) (compact, tight
 			}
 			switch leftSpaces {
 			case 0:
-				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: compact; Actual: missing space to left of pipe]`})
 			case 1:
-				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: tight; Actual: space to left of pipe]`})
 			default:
-				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: compact; Actual: extra space to left of pipe]`})
-				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: tight; Actual: space to left of pipe]`})
 			}
 		}
@@ -177,15 +180,15 @@ func rowCompactTightViolations(line, ruleID string, lineNum int) (compact, tight
 			rightSpaces := j - (p + 1)
 			switch rightSpaces {
 			case 0:
-				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: compact; Actual: missing space to right of pipe]`})
 			case 1:
-				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: tight; Actual: space to right of pipe]`})
 			default:
-				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				compact = append(compact, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: compact; Actual: extra space to right of pipe]`})
-				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1,
+				tight = append(tight, lint.Violation{Rule: ruleID, Line: lineNum, Column: p + 1, EndLine: lineNum, EndColumn: p + 2,
 					Message: `Table column style [Expected: tight; Actual: space to right of pipe]`})
 			}
 		}
@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mrueg/goldmark-lint/lint"
+)
+
+// Entry describes a rule in the registry.
+type Entry struct {
+	ID      string
+	Aliases []string
+	Tags    []string
+	// OptIn marks rules that are not enabled by default: DefaultRules leaves
+	// them out, and the CLI only runs them when configured explicitly.
+	OptIn bool
+
+	typ reflect.Type // type of the registered rule value
+}
+
+// Build returns a new instance of the rule with options applied. options is
+// the rule's object from a markdownlint config map; its keys are the JSON tags
+// of the rule's fields. With nil options the rule has its default settings.
+// When the options do not match the rule's fields, the error is returned
+// together with the rule, which then has the options that could be applied.
+func (e Entry) Build(options map[string]interface{}) (lint.Rule, error) {
+	typ, ptr := e.typ, false
+	if typ.Kind() == reflect.Pointer {
+		typ, ptr = typ.Elem(), true
+	}
+	v := reflect.New(typ)
+	var err error
+	if len(options) > 0 {
+		var data []byte
+		if data, err = json.Marshal(options); err == nil {
+			err = json.Unmarshal(data, v.Interface())
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", e.ID, err)
+		}
+	}
+	if ptr {
+		return v.Interface().(lint.Rule), err
+	}
+	return v.Elem().Interface().(lint.Rule), err
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Entry
+	// registryNames maps upper-cased IDs and aliases to indexes in registry.
+	registryNames = make(map[string]int)
+)
+
+// Register adds rule to the registry, making it part of DefaultRules and
+// available to config parsing, --list-rules, and inline disable comments in
+// the CLI. rule is used as a prototype: Entry.Build creates new values of its
+// type with options applied from JSON. Register panics if the rule's ID or
+// one of its aliases is already registered. It is typically called from an
+// init function.
+func Register(rule lint.Rule) {
+	register(rule, false)
+}
+
+// RegisterOptIn is like Register, but the rule is only run when enabled
+// explicitly; see Entry.OptIn.
+func RegisterOptIn(rule lint.Rule) {
+	register(rule, true)
+}
+
+func register(rule lint.Rule, optIn bool) {
+	e := Entry{ID: rule.ID(), OptIn: optIn, typ: reflect.TypeOf(rule)}
+	if ar, ok := rule.(lint.AliasedRule); ok {
+		e.Aliases = ar.Aliases()
+	}
+	if tr, ok := rule.(lint.TaggedRule); ok {
+		e.Tags = tr.Tags()
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := append([]string{e.ID}, e.Aliases...)
+	for _, name := range names {
+		if _, dup := registryNames[strings.ToUpper(name)]; dup {
+			panic("rules: Register called twice for rule name " + name)
+		}
+	}
+	for _, name := range names {
+		registryNames[strings.ToUpper(name)] = len(registry)
+	}
+	registry = append(registry, e)
+}
+
+// Lookup returns the registered rule whose ID or alias matches name,
+// ignoring case.
+func Lookup(name string) (Entry, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	i, ok := registryNames[strings.ToUpper(name)]
+	if !ok {
+		return Entry{}, false
+	}
+	return registry[i], true
+}
+
+// Build returns a new instance of the registered rule whose ID or alias
+// matches name, with options applied as by Entry.Build. Its signature matches
+// lint.Linter.BuildRule.
+func Build(name string, options map[string]interface{}) (lint.Rule, error) {
+	e, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown rule %s", name)
+	}
+	return e.Build(options)
+}
+
+// All returns all registered rules in registration order, the built-in rules
+// first.
+func All() []Entry {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Entry(nil), registry...)
+}
@@ -0,0 +1,161 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/mrueg/goldmark-lint/lint"
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"MD013", "md013", "line-length", "LINE-LENGTH"} {
+		e, ok := rules.Lookup(name)
+		if !ok || e.ID != "MD013" {
+			t.Errorf("Lookup(%q) = %+v, %v; want MD013", name, e, ok)
+		}
+	}
+	if _, ok := rules.Lookup("MD999"); ok {
+		t.Error("expected Lookup of an unknown rule to fail")
+	}
+	if e, ok := rules.Lookup("GL001"); !ok || !e.OptIn {
+		t.Errorf("expected GL001 to be registered as opt-in, got %+v, %v", e, ok)
+	}
+}
+
+func TestEntry_Tags(t *testing.T) {
+	for _, e := range rules.All() {
+		if len(e.Tags) == 0 {
+			t.Errorf("%s has no tags", e.ID)
+		}
+	}
+	e, _ := rules.Lookup("MD022")
+	if len(e.Tags) != 2 || e.Tags[0] != "headings" || e.Tags[1] != "blank_lines" {
+		t.Errorf("MD022 tags = %v, want [headings blank_lines]", e.Tags)
+	}
+}
+
+func TestEntry_Build(t *testing.T) {
+	e, _ := rules.Lookup("MD013")
+	r, err := e.Build(map[string]interface{}{"line_length": 100, "strict": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md013, ok := r.(rules.MD013)
+	if !ok || md013.LineLength != 100 || !md013.Strict {
+		t.Errorf("Build() = %#v, want MD013 with line_length 100 and strict", r)
+	}
+
+	// Invalid options are reported, but the rule is still usable.
+	r, err = e.Build(map[string]interface{}{"line_length": "long"})
+	if err == nil {
+		t.Error("expected an error for a string line_length")
+	}
+	if r == nil || r.ID() != "MD013" {
+		t.Errorf("expected an MD013 rule despite the error, got %#v", r)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r, err := rules.Build("line-length", map[string]interface{}{"line_length": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md013, ok := r.(rules.MD013); !ok || md013.LineLength != 100 {
+		t.Errorf("Build() = %#v, want MD013 with line_length 100", r)
+	}
+	if _, err := rules.Build("MD999", nil); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+func TestAll_MatchesDefaultRules(t *testing.T) {
+	var ids []string
+	for _, e := range rules.All() {
+		if !e.OptIn {
+			ids = append(ids, e.ID)
+		}
+	}
+	defaults := rules.DefaultRules()
+	if len(defaults) != len(ids) {
+		t.Fatalf("DefaultRules has %d rules, registry has %d non-opt-in rules", len(defaults), len(ids))
+	}
+	for i, r := range defaults {
+		if r.ID() != ids[i] {
+			t.Errorf("DefaultRules[%d] = %s, want %s", i, r.ID(), ids[i])
+		}
+	}
+}
+
+// registryTestRule is registered by TestRegister.
+type registryTestRule struct {
+	Word string `json:"word"`
+}
+
+func (registryTestRule) ID() string                            { return "TEST001" }
+func (registryTestRule) Aliases() []string                     { return []string{"test-rule"} }
+func (registryTestRule) Description() string                   { return "Test rule" }
+func (registryTestRule) Check(*lint.Document) []lint.Violation { return nil }
+
+func TestRegister(t *testing.T) {
+	rules.Register(registryTestRule{})
+	e, ok := rules.Lookup("test-rule")
+	if !ok || e.ID != "TEST001" {
+		t.Fatalf("Lookup(test-rule) = %+v, %v", e, ok)
+	}
+	r, err := e.Build(map[string]interface{}{"word": "x"})
+	if err != nil || r.(registryTestRule).Word != "x" {
+		t.Errorf("Build() = %#v, %v", r, err)
+	}
+	found := false
+	for _, r := range rules.DefaultRules() {
+		found = found || r.ID() == "TEST001"
+	}
+	if !found {
+		t.Error("expected a registered rule in DefaultRules")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Register to panic on a duplicate alias")
+		}
+	}()
+	rules.Register(aliasClash{})
+}
+
+// aliasClash reuses the alias of MD013.
+type aliasClash struct{ registryTestRule }
+
+func (aliasClash) ID() string        { return "TEST002" }
+func (aliasClash) Aliases() []string { return []string{"line-length"} }
+
+func TestEntry_OptionsSchema(t *testing.T) {
+	e, _ := rules.Lookup("MD003")
+	schema := e.OptionsSchema()
+	if schema["type"] != "object" || schema["additionalProperties"] != false {
+		t.Errorf("MD003 schema = %v, want a closed object", schema)
+	}
+	props, _ := schema["properties"].(map[string]interface{})
+	style, _ := props["style"].(map[string]interface{})
+	if style["type"] != "string" {
+		t.Errorf("MD003 style schema = %v, want a string", style)
+	}
+	if enum, _ := style["enum"].([]interface{}); len(enum) != 6 || enum[0] != "consistent" {
+		t.Errorf("MD003 style enum = %v, want the six heading styles", style["enum"])
+	}
+
+	e, _ = rules.Lookup("MD013")
+	props, _ = e.OptionsSchema()["properties"].(map[string]interface{})
+	for name, typ := range map[string]string{"line_length": "integer", "code_blocks": "boolean"} {
+		if s, _ := props[name].(map[string]interface{}); s["type"] != typ {
+			t.Errorf("MD013 %s schema = %v, want type %s", name, props[name], typ)
+		}
+	}
+
+	// GL003 embeds a recursive schema type; its fields are flattened.
+	e, _ = rules.Lookup("GL003")
+	props, _ = e.OptionsSchema()["properties"].(map[string]interface{})
+	items, _ := props["items"].(map[string]interface{})
+	if _, ok := props["required"]; !ok || items["type"] != "object" {
+		t.Errorf("GL003 properties = %v, want required and an object items", props)
+	}
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OptionsSchema returns a JSON Schema for the options object of the rule,
+// derived from the JSON tags of its fields. A field whose values are limited
+// to a fixed set lists them in an enum tag, as in
+//
+//	Style string `json:"style" enum:"consistent,fenced,indented"`
+//
+// The schema does not allow keys that are not fields of the rule.
+func (e Entry) OptionsSchema() map[string]interface{} {
+	return typeSchema(e.typ, make(map[reflect.Type]bool))
+}
+
+// typeSchema returns the JSON Schema of the values encoding/json decodes into
+// a t. visiting holds the struct types being described, so that recursive
+// types end in a plain object schema.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	if t == reflect.TypeOf(IntOrArray(nil)) {
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+			},
+		}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), visiting)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := make(map[string]interface{})
+		addFieldSchemas(properties, t, visiting)
+		return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	}
+	// Interfaces accept any value.
+	return map[string]interface{}{}
+}
+
+// addFieldSchemas adds the schemas of the JSON fields of the struct type t to
+// properties, including those of embedded structs.
+func addFieldSchemas(properties map[string]interface{}, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addFieldSchemas(properties, f.Type, visiting)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema := typeSchema(f.Type, visiting)
+		if enum := f.Tag.Get("enum"); enum != "" {
+			var values []interface{}
+			for _, v := range strings.Split(enum, ",") {
+				values = append(values, v)
+			}
+			schema["enum"] = values
+		}
+		properties[name] = schema
+	}
+}
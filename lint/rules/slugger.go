@@ -0,0 +1,242 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Slugger generates the anchors of a document's headings, as a Markdown
+// renderer does. A Slugger is used for a single document: Slug is called for
+// every heading in document order, so that duplicate anchors can be numbered.
+type Slugger interface {
+	Slug(text string) string
+}
+
+// DefaultSlugger is the name of the slugger used when none is configured.
+const DefaultSlugger = "github"
+
+var (
+	sluggersMu sync.RWMutex
+	sluggers   = make(map[string]func() Slugger)
+)
+
+// RegisterSlugger makes a heading anchor algorithm available under name, the
+// value of the slugger option of MD051 and GL001. newSlugger is called once
+// per document. RegisterSlugger panics if name is already registered.
+func RegisterSlugger(name string, newSlugger func() Slugger) {
+	sluggersMu.Lock()
+	defer sluggersMu.Unlock()
+	if _, dup := sluggers[name]; dup {
+		panic("rules: RegisterSlugger called twice for " + name)
+	}
+	sluggers[name] = newSlugger
+}
+
+// NewSlugger returns a new Slugger for the algorithm registered as name. The
+// built-in algorithms are "github" (the default), "gitlab", "pandoc",
+// "kramdown", "mkdocs", and "hugo".
+func NewSlugger(name string) (Slugger, error) {
+	sluggersMu.RLock()
+	newSlugger, ok := sluggers[name]
+	sluggersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown slugger %q", name)
+	}
+	return newSlugger(), nil
+}
+
+// SluggerNames returns the names of all registered sluggers, sorted.
+func SluggerNames() []string {
+	sluggersMu.RLock()
+	defer sluggersMu.RUnlock()
+	names := make([]string, 0, len(sluggers))
+	for name := range sluggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newSluggerOrDefault returns the slugger registered as name, falling back to
+// DefaultSlugger for an empty or unknown name.
+func newSluggerOrDefault(name string) Slugger {
+	if s, err := NewSlugger(name); err == nil {
+		return s
+	}
+	s, _ := NewSlugger(DefaultSlugger)
+	return s
+}
+
+func init() {
+	for name, fn := range map[string]func(string) string{
+		"github":   githubSlug,
+		"gitlab":   gitlabSlug,
+		"pandoc":   pandocSlug,
+		"kramdown": kramdownSlug,
+		"hugo":     hugoSlug,
+	} {
+		RegisterSlugger(name, func() Slugger { return &numberingSlugger{slug: fn, sep: "-"} })
+	}
+	// Python-Markdown, which MkDocs uses, numbers duplicates with underscores.
+	RegisterSlugger("mkdocs", func() Slugger { return &numberingSlugger{slug: mkdocsSlug, sep: "_"} })
+}
+
+// numberingSlugger turns a slug function into a Slugger that appends sep and
+// the lowest free number, starting at 1, to anchors that were already used.
+type numberingSlugger struct {
+	slug func(string) string
+	sep  string
+	seen map[string]bool
+}
+
+func (s *numberingSlugger) Slug(text string) string {
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+	base := s.slug(text)
+	slug := base
+	for n := 1; s.seen[slug]; n++ {
+		slug = base + s.sep + strconv.Itoa(n)
+	}
+	s.seen[slug] = true
+	return slug
+}
+
+// githubSlug follows github-slugger: lowercase, drop everything but letters,
+// marks, numbers, underscores, hyphens, and spaces, then turn each space into
+// a hyphen.
+func githubSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ' || r == '-':
+			b.WriteRune('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// gitlabSlug is like githubSlug, but collapses runs of hyphens.
+func gitlabSlug(text string) string {
+	return collapseHyphens(githubSlug(strings.TrimSpace(text)))
+}
+
+// hugoSlug follows Hugo's default anchorize function: lowercase, keep
+// letters, numbers, underscores, and hyphens, and turn whitespace into
+// hyphens.
+func hugoSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsSpace(r) || r == '-':
+			b.WriteRune('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pandocSlug follows pandoc's auto_identifiers extension: keep letters,
+// numbers, underscores, hyphens, and periods, turn whitespace into hyphens,
+// lowercase, and drop everything before the first letter. An empty result
+// becomes "section".
+func pandocSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		case r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	slug := strings.TrimLeftFunc(b.String(), func(r rune) bool { return !unicode.IsLetter(r) })
+	if slug == "" {
+		return "section"
+	}
+	return slug
+}
+
+// kramdownLeadRE matches the characters kramdown strips before the first
+// ASCII letter of a heading.
+var kramdownLeadRE = regexp.MustCompile(`^[^a-zA-Z]+`)
+
+// kramdownSlug follows kramdown's auto_ids: drop everything before the first
+// ASCII letter and everything but ASCII letters, digits, spaces, and hyphens,
+// turn spaces into hyphens, and lowercase. An empty result becomes "section".
+func kramdownSlug(text string) string {
+	var b strings.Builder
+	for _, r := range kramdownLeadRE.ReplaceAllString(text, "") {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// latinFolds maps Latin letters with diacritics to their base letter, as
+// Unicode NFKD decomposition followed by dropping non-ASCII characters does.
+var latinFolds = func() map[rune]rune {
+	const pairs = "ÀAÁAÂAÃAÄAÅAÇCÈEÉEÊEËEÌIÍIÎIÏIÑNÒOÓOÔOÕOÖOÙUÚUÛUÜUÝYàaáaâaãaäaåaçcèeéeêeëeìiíiîiïiñnòoóoôoõoöoùuúuûuüuýyÿy" +
+		"ĀAāaĂAăaĄAąaĆCćcĈCĉcĊCċcČCčcĎDďdĒEēeĔEĕeĖEėeĘEęeĚEěeĜGĝgĞGğgĠGġgĢGģgĤHĥhĨIĩiĪIīiĬIĭiĮIįiİIĴJĵjĶKķkĹLĺlĻLļlĽLľlĿLŀlŃNńnŅNņnŇNňnŉn" +
+		"ŌOōoŎOŏoŐOőoŔRŕrŖRŗrŘRřrŚSśsŜSŝsŞSşsŠSšsŢTţtŤTťtŨUũuŪUūuŬUŭuŮUůuŰUűuŲUųuŴWŵwŶYŷyŸYŹZźzŻZżzŽZžzſs"
+	runes := []rune(pairs)
+	folds := make(map[rune]rune, len(runes)/2)
+	for i := 0; i+1 < len(runes); i += 2 {
+		folds[runes[i]] = runes[i+1]
+	}
+	return folds
+}()
+
+// mkdocsSlug follows Python-Markdown's default toc slugify: fold accented
+// Latin letters to ASCII and drop other non-ASCII characters, keep letters,
+// digits, underscores, hyphens, and whitespace, trim, lowercase, and turn
+// runs of hyphens and whitespace into a single hyphen.
+func mkdocsSlug(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if folded, ok := latinFolds[r]; ok {
+			r = folded
+		}
+		if r < unicode.MaxASCII && (r == '_' || r == '-' || unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return collapseHyphens(strings.Join(strings.Fields(b.String()), "-"))
+}
+
+// collapseHyphens replaces runs of hyphens in s with a single hyphen.
+func collapseHyphens(s string) string {
+	for strings.Contains(s, "--") {
+		s = strings.ReplaceAll(s, "--", "-")
+	}
+	return s
+}
+
+// headingIDRE matches an explicit heading ID attribute such as {#custom-id}
+// or kramdown's {: #custom-id} at the end of a heading's text.
+var headingIDRE = regexp.MustCompile(`\s*\{:?\s*#([^\s}]+)[^}]*\}\s*$`)
+
+// explicitHeadingID returns the ID given by an attribute block at the end of
+// a heading's text.
+func explicitHeadingID(text string) (string, bool) {
+	m := headingIDRE.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
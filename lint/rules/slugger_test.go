@@ -0,0 +1,103 @@
+package rules_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrueg/goldmark-lint/lint/rules"
+)
+
+func TestSluggers(t *testing.T) {
+	tests := []struct {
+		slugger string
+		text    string
+		want    string
+	}{
+		{"github", "Hello World", "hello-world"},
+		{"github", "What's new?", "whats-new"},
+		{"github", "A -- B", "a----b"},
+		{"github", "日本語の見出し", "日本語の見出し"},
+		{"github", "Über Größe", "über-größe"},
+		{"github", "1. Intro", "1-intro"},
+		{"gitlab", "A -- B", "a-b"},
+		{"gitlab", "Über Größe", "über-größe"},
+		{"pandoc", "1. Intro", "intro"},
+		{"pandoc", "Version 1.2", "version-1.2"},
+		{"pandoc", "123", "section"},
+		{"pandoc", "日本語", "日本語"},
+		{"kramdown", "1. Intro", "intro"},
+		{"kramdown", "Über Größe", "ber-gre"},
+		{"kramdown", "日本語", "section"},
+		{"mkdocs", "Über Größe", "uber-groe"},
+		{"mkdocs", "A -- B", "a-b"},
+		{"mkdocs", "  Spaces\taround  ", "spaces-around"},
+		{"hugo", "Hello\tWorld", "hello-world"},
+		{"hugo", "日本語の見出し", "日本語の見出し"},
+	}
+	for _, tt := range tests {
+		s, err := rules.NewSlugger(tt.slugger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Slug(tt.text); got != tt.want {
+			t.Errorf("%s: Slug(%q) = %q, want %q", tt.slugger, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSluggers_Duplicates(t *testing.T) {
+	for name, want := range map[string][]string{
+		"github": {"intro", "intro-1", "intro-2"},
+		"pandoc": {"intro", "intro-1", "intro-2"},
+		"mkdocs": {"intro", "intro_1", "intro_2"},
+	} {
+		s, _ := rules.NewSlugger(name)
+		var got []string
+		for range want {
+			got = append(got, s.Slug("Intro"))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+
+	// A heading whose anchor is already taken by a numbered duplicate gets
+	// the next free number.
+	s, _ := rules.NewSlugger("github")
+	var got []string
+	for _, text := range []string{"Intro", "Intro 1", "Intro"} {
+		got = append(got, s.Slug(text))
+	}
+	if want := []string{"intro", "intro-1", "intro-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type upperSlugger struct{}
+
+func (upperSlugger) Slug(text string) string { return strings.ToUpper(text) }
+
+func TestRegisterSlugger(t *testing.T) {
+	rules.RegisterSlugger("test-upper", func() rules.Slugger { return upperSlugger{} })
+	s, err := rules.NewSlugger("test-upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Slug("abc"); got != "ABC" {
+		t.Errorf("Slug() = %q, want ABC", got)
+	}
+	if _, err := rules.NewSlugger("no-such-slugger"); err == nil {
+		t.Error("expected an error for an unknown slugger")
+	}
+	names := rules.SluggerNames()
+	for _, name := range []string{"github", "gitlab", "hugo", "kramdown", "mkdocs", "pandoc", "test-upper"} {
+		found := false
+		for _, n := range names {
+			found = found || n == name
+		}
+		if !found {
+			t.Errorf("expected %s in SluggerNames() = %v", name, names)
+		}
+	}
+}
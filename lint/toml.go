@@ -0,0 +1,590 @@
+package lint
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlParser reads a TOML document into nested maps, as used by Hugo-style
+// "+++" front matter. Tables become map[string]interface{}, arrays
+// []interface{}, integers int64, and floats float64. Dates and times are kept
+// as strings, as they are for YAML front matter.
+type tomlParser struct {
+	src  string
+	pos  int
+	root map[string]interface{}
+	// defined records the tables created by a [table] header or by a key, so
+	// that defining one twice is an error. Keys are the table paths joined
+	// with "\x00".
+	defined map[string]bool
+	// keyLines records the line of each key; see Document.FrontMatterKeyLines.
+	keyLines map[string]int
+	// linePath is the key path of the current table in keyLines, in which
+	// elements of arrays of tables are numbered.
+	linePath string
+}
+
+// parseTOML parses src, recording the lines of its keys in keyLines. Errors
+// and keyLines report 1-based lines of src.
+func parseTOML(src string, keyLines map[string]int) (map[string]interface{}, *FrontMatterError) {
+	p := &tomlParser{src: src, root: make(map[string]interface{}), defined: make(map[string]bool), keyLines: keyLines}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+// line returns the 1-based line of the current position.
+func (p *tomlParser) line() int {
+	return 1 + strings.Count(p.src[:p.pos], "\n")
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) *FrontMatterError {
+	return &FrontMatterError{Line: p.line(), Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *tomlParser) parse() *FrontMatterError {
+	current, path := p.root, []string(nil)
+	for {
+		p.skipBlank(true)
+		if p.pos >= len(p.src) {
+			return nil
+		}
+		var err *FrontMatterError
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "[["):
+			p.pos += 2
+			if path, err = p.key(); err != nil {
+				return err
+			}
+			if !p.consume("]]") {
+				return p.errorf("expected ]] after array of tables %s", strings.Join(path, "."))
+			}
+			current, err = p.appendTable(path)
+		case p.src[p.pos] == '[':
+			p.pos++
+			if path, err = p.key(); err != nil {
+				return err
+			}
+			if !p.consume("]") {
+				return p.errorf("expected ] after table %s", strings.Join(path, "."))
+			}
+			current, err = p.table(path)
+		default:
+			err = p.keyValue(current, path, false)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// skipBlank skips spaces, tabs, and comments, and also line breaks if
+// newlines is set.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case newlines && (c == '\n' || c == '\r'):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes the rest of a line, which must be blank or a comment.
+func (p *tomlParser) endOfLine() *FrontMatterError {
+	p.skipBlank(false)
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+		return p.errorf("unexpected %q at end of line", p.rest())
+	}
+	return nil
+}
+
+// rest returns the remainder of the current line, for error messages.
+func (p *tomlParser) rest() string {
+	s := p.src[p.pos:]
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// consume skips s if the input continues with it, after optional blanks.
+func (p *tomlParser) consume(s string) bool {
+	p.skipBlank(false)
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// key parses a possibly dotted key such as a."b.c".d.
+func (p *tomlParser) key() ([]string, *FrontMatterError) {
+	var parts []string
+	for {
+		p.skipBlank(false)
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected a key")
+		}
+		var part string
+		switch c := p.src[p.pos]; {
+		case c == '"':
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case c == '\'':
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && isTOMLBareKeyChar(p.src[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("invalid key %q", p.rest())
+			}
+			part = p.src[start:p.pos]
+		}
+		parts = append(parts, part)
+		if !p.consume(".") {
+			return parts, nil
+		}
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// keyValue parses a key = value pair into table, whose path is tablePath.
+// Tables created inside an inline table are not recorded as defined, as no
+// header can refer to them.
+func (p *tomlParser) keyValue(table map[string]interface{}, tablePath []string, inline bool) *FrontMatterError {
+	p.skipBlank(false)
+	line := p.line()
+	key, err := p.key()
+	if err != nil {
+		return err
+	}
+	if !p.consume("=") {
+		return p.errorf("expected = after key %s", strings.Join(key, "."))
+	}
+	p.skipBlank(false)
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	path := append(append([]string(nil), tablePath...), key...)
+	for i, part := range key[:len(key)-1] {
+		sub, ok := table[part]
+		if !ok {
+			next := make(map[string]interface{})
+			table[part] = next
+			if !inline {
+				p.defined[strings.Join(path[:len(tablePath)+i+1], "\x00")] = true
+			}
+			table = next
+			continue
+		}
+		next, ok := sub.(map[string]interface{})
+		if !ok {
+			return p.errorf("key %s is already defined", strings.Join(path[:len(tablePath)+i+1], "."))
+		}
+		table = next
+	}
+	last := key[len(key)-1]
+	if _, dup := table[last]; dup {
+		return p.errorf("key %s is already defined", strings.Join(path, "."))
+	}
+	table[last] = value
+	if inline {
+		return nil
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		p.defined[strings.Join(path, "\x00")] = true
+	}
+	linePath := p.linePath
+	for _, part := range key {
+		linePath = joinKeyPath(linePath, part)
+		if _, ok := p.keyLines[linePath]; !ok {
+			p.keyLines[linePath] = line
+		}
+	}
+	return nil
+}
+
+// descend returns the table at path, creating missing tables, and makes it
+// the current table. For an array of tables, the last element is used.
+func (p *tomlParser) descend(path []string) (map[string]interface{}, *FrontMatterError) {
+	table := p.root
+	p.linePath = ""
+	for i, part := range path {
+		p.linePath = joinKeyPath(p.linePath, part)
+		if _, ok := p.keyLines[p.linePath]; !ok {
+			p.keyLines[p.linePath] = p.line()
+		}
+		switch sub := table[part].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[part] = next
+			table = next
+		case map[string]interface{}:
+			table = sub
+		case []interface{}:
+			var next map[string]interface{}
+			if len(sub) > 0 {
+				next, _ = sub[len(sub)-1].(map[string]interface{})
+			}
+			if next == nil {
+				return nil, p.errorf("key %s is not a table", strings.Join(path[:i+1], "."))
+			}
+			p.linePath = joinKeyPath(p.linePath, strconv.Itoa(len(sub)-1))
+			table = next
+		default:
+			return nil, p.errorf("key %s is not a table", strings.Join(path[:i+1], "."))
+		}
+	}
+	return table, nil
+}
+
+// table handles a [table] header.
+func (p *tomlParser) table(path []string) (map[string]interface{}, *FrontMatterError) {
+	name := strings.Join(path, "\x00")
+	if p.defined[name] {
+		return nil, p.errorf("table %s is already defined", strings.Join(path, "."))
+	}
+	table, err := p.descend(path)
+	if err != nil {
+		return nil, err
+	}
+	p.defined[name] = true
+	return table, nil
+}
+
+// appendTable handles an [[array of tables]] header.
+func (p *tomlParser) appendTable(path []string) (map[string]interface{}, *FrontMatterError) {
+	parent, err := p.descend(path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	var tables []interface{}
+	switch existing := parent[last].(type) {
+	case nil:
+	case []interface{}:
+		tables = existing
+	default:
+		return nil, p.errorf("key %s is not an array of tables", strings.Join(path, "."))
+	}
+	table := make(map[string]interface{})
+	parent[last] = append(tables, table)
+	arrayPath := joinKeyPath(p.linePath, last)
+	if _, ok := p.keyLines[arrayPath]; !ok {
+		p.keyLines[arrayPath] = p.line()
+	}
+	p.linePath = joinKeyPath(arrayPath, strconv.Itoa(len(tables)))
+	p.keyLines[p.linePath] = p.line()
+	// Subtables of the previous element may be defined again.
+	prefix := strings.Join(path, "\x00") + "\x00"
+	for name := range p.defined {
+		if strings.HasPrefix(name, prefix) {
+			delete(p.defined, name)
+		}
+	}
+	return table, nil
+}
+
+// tomlDateTimeRE matches TOML offset and local dates and times.
+var tomlDateTimeRE = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}:\d{2}(\.\d+)?)$`)
+
+// value parses a value starting at the current position.
+func (p *tomlParser) value() (interface{}, *FrontMatterError) {
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected a value")
+	}
+	switch p.src[p.pos] {
+	case '"':
+		return p.basicString()
+	case '\'':
+		return p.literalString()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	// A date and a time may be separated by a space.
+	if p.pos-start == 10 && p.pos+3 < len(p.src) && p.src[p.pos] == ' ' &&
+		isDigit(p.src[p.pos+1]) && isDigit(p.src[p.pos+2]) && p.src[p.pos+3] == ':' {
+		p.pos++
+		for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+			p.pos++
+		}
+	}
+	token := p.src[start:p.pos]
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	if tomlDateTimeRE.MatchString(token) {
+		return token, nil
+	}
+	if v, ok := parseTOMLInteger(token); ok {
+		return v, nil
+	}
+	if !strings.Contains(token, "__") && !strings.HasPrefix(token, "_") && !strings.HasSuffix(token, "_") {
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil && !strings.ContainsAny(token, "xXpP") {
+			return v, nil
+		}
+	}
+	p.pos = start
+	if token == "" {
+		return nil, p.errorf("expected a value, found %q", p.rest())
+	}
+	return nil, p.errorf("invalid value %q", token)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// parseTOMLInteger parses a decimal, hexadecimal (0x), octal (0o), or binary
+// (0b) integer with optional underscores between digits.
+func parseTOMLInteger(token string) (int64, bool) {
+	if token == "" || strings.Contains(token, "__") || strings.HasSuffix(token, "_") {
+		return 0, false
+	}
+	base, digits := 10, token
+	if len(token) > 2 && token[0] == '0' {
+		switch token[1] {
+		case 'x':
+			base, digits = 16, token[2:]
+		case 'o':
+			base, digits = 8, token[2:]
+		case 'b':
+			base, digits = 2, token[2:]
+		}
+	}
+	if base == 10 {
+		unsigned := strings.TrimLeft(digits, "+-")
+		if len(unsigned) > 1 && unsigned[0] == '0' || strings.HasPrefix(unsigned, "_") {
+			return 0, false
+		}
+	} else if strings.HasPrefix(digits, "_") || strings.ContainsAny(digits, "+-") {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+	return v, err == nil
+}
+
+// array parses an array, which may span several lines.
+func (p *tomlParser) array() (interface{}, *FrontMatterError) {
+	p.pos++ // [
+	values := []interface{}{}
+	for {
+		p.skipBlank(true)
+		if p.pos < len(p.src) && p.src[p.pos] == ']' {
+			p.pos++
+			return values, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skipBlank(true)
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == ']' {
+			p.pos++
+			return values, nil
+		}
+		return nil, p.errorf("expected , or ] in array")
+	}
+}
+
+// inlineTable parses an inline table such as { a = 1, b.c = 2 }.
+func (p *tomlParser) inlineTable() (interface{}, *FrontMatterError) {
+	p.pos++ // {
+	table := make(map[string]interface{})
+	if p.consume("}") {
+		return table, nil
+	}
+	for {
+		if err := p.keyValue(table, nil, true); err != nil {
+			return nil, err
+		}
+		if p.consume("}") {
+			return table, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// basicString parses a "basic" or """multi-line basic""" string.
+func (p *tomlParser) basicString() (string, *FrontMatterError) {
+	multiline := strings.HasPrefix(p.src[p.pos:], `"""`)
+	if multiline {
+		p.pos += 3
+		p.skipNewline()
+	} else {
+		p.pos++
+	}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"' && !multiline:
+			p.pos++
+			return b.String(), nil
+		case c == '"' && strings.HasPrefix(p.src[p.pos:], `"""`):
+			p.pos += 3
+			// Up to two quotes may directly precede the closing delimiter.
+			for i := 0; i < 2 && p.pos < len(p.src) && p.src[p.pos] == '"'; i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			return b.String(), nil
+		case c == '\n' && !multiline:
+			return "", p.errorf("unterminated string")
+		case c == '\\':
+			if err := p.escape(&b, multiline); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// escape decodes the escape sequence at the current position into b.
+func (p *tomlParser) escape(b *strings.Builder, multiline bool) *FrontMatterError {
+	p.pos++ // backslash
+	if p.pos >= len(p.src) {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte('\x1b')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("invalid escape sequence")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid escape sequence \\%c%s", c, p.src[p.pos:p.pos+n])
+		}
+		b.WriteRune(rune(code))
+		p.pos += n
+	default:
+		// A backslash at the end of a line in a multi-line string trims the
+		// line break and the whitespace that follows it.
+		if multiline && (c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+			p.pos--
+			rest := strings.TrimLeft(p.src[p.pos:], " \t")
+			if !strings.HasPrefix(rest, "\n") && !strings.HasPrefix(rest, "\r\n") {
+				return p.errorf("invalid escape sequence")
+			}
+			p.pos = len(p.src) - len(strings.TrimLeft(rest, " \t\r\n"))
+			return nil
+		}
+		p.pos--
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+// literalString parses a literal string in single quotes, or a multi-line
+// literal string in triple single quotes.
+func (p *tomlParser) literalString() (string, *FrontMatterError) {
+	if strings.HasPrefix(p.src[p.pos:], "'''") {
+		p.pos += 3
+		p.skipNewline()
+		end := strings.Index(p.src[p.pos:], "'''")
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		end += p.pos
+		for i := 0; i < 2 && end+3 < len(p.src) && p.src[end+3] == '\''; i++ {
+			end++
+		}
+		s := p.src[p.pos:end]
+		p.pos = end + 3
+		return s, nil
+	}
+	p.pos++
+	end := strings.IndexAny(p.src[p.pos:], "'\n")
+	if end < 0 || p.src[p.pos+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// skipNewline skips a line break directly after the opening delimiter of a
+// multi-line string.
+func (p *tomlParser) skipNewline() {
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if strings.HasPrefix(p.src[p.pos:], "\n") {
+		p.pos++
+	}
+}